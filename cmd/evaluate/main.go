@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/evaluation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/resource"
+
+	"gocv.io/x/gocv"
+)
+
+func main() {
+	// コマンドライン引数の解析
+	var (
+		datasetPath string
+		cascadePath string
+		configPath  string
+		format      string
+		outputPath  string
+	)
+	flag.StringVar(&datasetPath, "dataset", "", "ラベル付きデータセット（感情ごとのサブフォルダを持つディレクトリ、またはpath,labelのCSVマニフェスト）")
+	flag.StringVar(&cascadePath, "cascade", resource.ResolvePath("models/haarcascade_frontalface_default.xml"), "顔検出用のカスケード分類器")
+	flag.StringVar(&configPath, "config", "", "アナライザー設定を読み込む設定ファイル（省略時はデフォルト値）")
+	flag.StringVar(&format, "format", "table", "出力形式（table, json, both）")
+	flag.StringVar(&outputPath, "output", "", "JSONレポートの出力先（省略時は標準出力）")
+	flag.Parse()
+
+	if datasetPath == "" {
+		fmt.Fprintln(os.Stderr, "-dataset を指定してください")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(datasetPath, cascadePath, configPath, format, outputPath); err != nil {
+		slog.Error("評価に失敗", "error", err)
+		os.Exit(1)
+	}
+}
+
+// 評価を実行してレポートを出力
+func run(datasetPath, cascadePath, configPath, format, outputPath string) error {
+	samples, err := evaluation.LoadDataset(datasetPath)
+	if err != nil {
+		return err
+	}
+
+	// カスケード分類器の準備
+	cascade := gocv.NewCascadeClassifier()
+	defer cascade.Close()
+	if !cascade.Load(cascadePath) {
+		return fmt.Errorf("カスケード分類器の読み込みに失敗: %s", cascadePath)
+	}
+	faceAnalyzer := analyzer.New(&cascade, "", "", false)
+
+	// 設定ファイルが指定された場合はアナライザーに反映
+	if configPath != "" {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+		if err := faceAnalyzer.Configure(cfg.Analyzer); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	slog.Info("評価を開始します", "dataset", datasetPath, "samples", len(samples))
+	report, predictions, err := evaluation.Evaluate(ctx, samples, func(imgData []byte) (string, int, error) {
+		result, err := faceAnalyzer.Analyze(imgData)
+		if err != nil {
+			return "", 0, err
+		}
+		return string(result.PrimaryEmotion), len(result.Faces), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range predictions {
+		if p.Err != nil {
+			slog.Warn("サンプルの分析に失敗", "path", p.Path, "error", p.Err)
+		}
+	}
+
+	return writeReport(report, format, outputPath)
+}
+
+// 指定された形式でレポートを出力
+func writeReport(report evaluation.Report, format, outputPath string) error {
+	var jsonOut io.Writer = os.Stdout
+	if outputPath != "" {
+		file, err := os.Create(outputPath)
+		if err != nil {
+			return fmt.Errorf("出力ファイルの作成に失敗: %w", err)
+		}
+		defer file.Close()
+		jsonOut = file
+	}
+
+	switch format {
+	case "table":
+		if outputPath != "" {
+			if err := evaluation.WriteJSON(jsonOut, report); err != nil {
+				return err
+			}
+		}
+		return evaluation.WriteTable(os.Stdout, report)
+	case "json":
+		return evaluation.WriteJSON(jsonOut, report)
+	case "both":
+		if err := evaluation.WriteTable(os.Stdout, report); err != nil {
+			return err
+		}
+		return evaluation.WriteJSON(jsonOut, report)
+	default:
+		return fmt.Errorf("不正な出力形式です: %s", format)
+	}
+}
@@ -1,22 +1,41 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/okamyuji/face-emotion-analyzer/config"
 	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/fetch"
+	"github.com/okamyuji/face-emotion-analyzer/internal/gallery"
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
 	"github.com/okamyuji/face-emotion-analyzer/internal/handler"
+	"github.com/okamyuji/face-emotion-analyzer/internal/job"
+	"github.com/okamyuji/face-emotion-analyzer/internal/metrics"
 	"github.com/okamyuji/face-emotion-analyzer/internal/middleware"
 	"github.com/okamyuji/face-emotion-analyzer/internal/resource"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"github.com/okamyuji/face-emotion-analyzer/internal/webhook"
+	"github.com/okamyuji/face-emotion-analyzer/internal/worker"
 
 	"gocv.io/x/gocv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 // バージョン情報
@@ -26,6 +45,9 @@ var (
 	BuildTime  = "unknown"
 )
 
+// 終了時に処理中のリクエストとジョブの完了を待つ時間
+const shutdownTimeout = 30 * time.Second
+
 func main() {
 	// コマンドライン引数の解析
 	var showVersion bool
@@ -41,6 +63,9 @@ func main() {
 	// 環境とロギングの初期化
 	logger := initEnvironment()
 
+	// 設定ファイルの読み込み
+	cfg := loadConfig(logger)
+
 	// カスケード分類器の準備
 	cascade := gocv.NewCascadeClassifier()
 	defer cascade.Close()
@@ -75,16 +100,91 @@ func main() {
 
 	// 顔検出器の初期化
 	faceAnalyzer := analyzer.New(&cascade, "", "", false)
+	if err := faceAnalyzer.Configure(cfg.Analyzer); err != nil {
+		logger.Error("顔検出器の設定に失敗", "error", err)
+		os.Exit(1)
+	}
+
+	defer faceAnalyzer.Close()
+
+	// 登録済み人物のギャラリーの読み込み
+	faceGallery, err := gallery.Open(resolveOptionalPath(cfg.Gallery.Path))
+	if err != nil {
+		logger.Error("ギャラリーの読み込みに失敗", "error", err)
+		os.Exit(1)
+	}
+
+	// メトリクスとセッション集計の初期化
+	metricsCollector := metrics.NewMetricsCollector()
+	metricsCollector.SetMatSource(func() metrics.MatCounts {
+		live, _ := analyzer.MatProfileCount()
+		return metrics.MatCounts{Live: live}
+	})
+	sessionStore := session.NewStore(cfg.Session.TTL, cfg.Session.EngagementThreshold, cfg.Session.MaxSessions)
+	faceAnalyzer.SetStageObserver(metricsCollector.RecordProcessingTime)
+	eventHub := stream.NewHub(cfg.Session.EventBuffer, cfg.Stream.SmoothingWindow)
+
+	// 非同期ジョブのワーカープールの初期化
+	jobPool := worker.NewPool(int32(cfg.Jobs.MinWorkers), int32(cfg.Jobs.MaxWorkers))
+	jobManager := job.NewManager(jobPool, cfg.Jobs.TTL, cfg.Jobs.MaxPending)
 
 	// ハンドラーの初期化
 	faceHandler := handler.NewFaceHandler(renderer, faceAnalyzer)
+	faceHandler.SetGallery(faceGallery, cfg.Gallery.MatchThreshold)
+	faceHandler.SetSessionStore(sessionStore)
+	faceHandler.SetEventHub(eventHub)
+	faceHandler.SetMetrics(metricsCollector)
+	faceHandler.SetDebug(cfg.App.Debug)
+	faceHandler.SetExplain(cfg.Analyzer.Explain.Enabled, cfg.Analyzer.Explain.Images)
+	faceHandler.SetCrowd(cfg.Analyzer.Crowd.Enabled)
+	if cfg.ImageURL.Enabled {
+		imageFetcher, err := fetch.New(cfg.ImageURL)
+		if err != nil {
+			logger.Error("画像の取得の初期化に失敗", "error", err)
+			os.Exit(1)
+		}
+		faceHandler.SetImageFetcher(imageFetcher)
+	}
+	var webhookHandler *handler.WebhookHandler
+	if cfg.Webhook.Enabled {
+		dispatcher, err := webhook.New(cfg.Webhook, metricsCollector)
+		if err != nil {
+			logger.Error("Webhookの初期化に失敗", "error", err)
+			os.Exit(1)
+		}
+		defer dispatcher.Close()
+		faceHandler.SetWebhooks(dispatcher)
+		webhookHandler = handler.NewWebhookHandler(dispatcher, cfg.Webhook.AdminToken)
+	}
+	faceHandler.SetModelInfo(handler.ModelInfo{
+		ServerVersion: Version,
+		Detectors:     faceAnalyzer.DetectorNames(),
+		Pipeline:      faceAnalyzer.StageNames(),
+	})
+	galleryHandler := handler.NewGalleryHandler(faceAnalyzer, faceGallery)
+	sessionHandler := handler.NewSessionHandler(sessionStore)
+	jobHandler := handler.NewJobHandler(faceHandler, jobManager, cfg.Jobs.MaxUploadSize)
+	eventsHandler := handler.NewSessionEventsHandler(eventHub, cfg.Session.HeartbeatInterval)
+	streamHandler := handler.NewStreamHandler(faceHandler, cfg.Stream.MaxFPS, cfg.Stream.SmoothingWindow)
 	healthHandler := handler.NewHealthHandler(logger)
 
 	// ルーティングの設定
+	// /analyze などのルートは既存のWeb UIとの互換性のために残す
 	mux := http.NewServeMux()
 	mux.Handle("/", securityMiddleware.Middleware(faceHandler.Handle))
 	mux.Handle("/analyze", securityMiddleware.Middleware(http.HandlerFunc(faceHandler.HandleAnalyze)))
 	mux.HandleFunc("/health", healthHandler.Handle)
+	mux.Handle("/metrics", metricsCollector.Handler())
+	mux.Handle("GET /sessions/{id}", securityMiddleware.Middleware(sessionHandler.HandleGet))
+	mux.Handle("POST /gallery/enroll", securityMiddleware.Middleware(galleryHandler.HandleEnroll))
+	mux.Handle("GET /gallery", securityMiddleware.Middleware(galleryHandler.HandleList))
+	mux.Handle("DELETE /gallery/{id}", securityMiddleware.Middleware(galleryHandler.HandleDelete))
+	mux.Handle("/api/v1/", handler.NewAPIv1Router(faceHandler, galleryHandler, sessionHandler, jobHandler, streamHandler, eventsHandler, webhookHandler, securityMiddleware.Middleware))
+
+	// デバッグ用エンドポイントはデバッグモードでのみ公開
+	if cfg.App.Debug {
+		mux.HandleFunc("GET /debug/mats", handler.NewDebugHandler().HandleMats)
+	}
 
 	// 静的ファイルの提供
 	fs := http.FileServer(http.Dir(resource.ResolvePath("web/static")))
@@ -109,12 +209,80 @@ func main() {
 		server.Addr = ":" + port
 	}
 
+	// gRPCサーバーの起動（HTTPとは別のポートで待ち受ける）
+	if cfg.GRPC.Port != "" {
+		grpcService := handler.NewGRPCService(faceHandler, streamHandler)
+		if err := startGRPCServer(cfg.GRPC.Port, grpcService, securityMiddleware, metricsCollector); err != nil {
+			logger.Error("gRPCサーバーの起動に失敗", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// サーバーの起動
-	logger.Info("サーバーを起動します", "port", server.Addr)
-	if err := server.ListenAndServe(); err != nil {
+	// SIGINT または SIGTERM を受け取ると、処理中のリクエストと待機中のジョブを含むジョブの完了を待って終了する
+	// 期限までに終わらなかったジョブはキャンセルとして記録する
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("サーバーを起動します", "port", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
 		logger.Error("サーバーの起動に失敗", "error", err)
 		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	logger.Info("サーバーを停止します")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("サーバーの停止に失敗", "error", err)
 	}
+	if err := jobPool.Shutdown(shutdownCtx); err != nil {
+		logger.Error("ジョブのワーカープールの停止に失敗", "error", err)
+	}
+}
+
+// gRPCサーバーを起動
+// HTTPのハンドラーと同じレート制限、リクエストID、メトリクスを適用する
+func startGRPCServer(
+	port string,
+	service *handler.GRPCService,
+	security *middleware.SecurityMiddleware,
+	observer middleware.RequestObserver,
+) error {
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return fmt.Errorf("gRPCのポートの待ち受けに失敗: %w", err)
+	}
+
+	server := grpc.NewServer(
+		grpc.MaxRecvMsgSize(handler.GRPCMaxMessageSize),
+		grpc.ChainUnaryInterceptor(
+			middleware.UnaryMetricsInterceptor(observer),
+			security.UnaryServerInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			middleware.StreamMetricsInterceptor(observer),
+			security.StreamServerInterceptor(),
+		),
+	)
+	faceemotionv1.RegisterAnalyzerServiceServer(server, service)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	slog.Info("gRPCサーバーを起動します", "port", listener.Addr().String())
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			slog.Error("gRPCサーバーが停止しました", "error", err)
+		}
+	}()
+	return nil
 }
 
 // 暗号学的に安全なランダムトークンを生成
@@ -160,3 +328,32 @@ func initEnvironment() *slog.Logger {
 
 	return logger
 }
+
+// 環境に応じた設定ファイルを読み込む
+// 設定ファイルがない場合のみデフォルト設定で起動し、不正な設定の場合は終了する
+func loadConfig(logger *slog.Logger) *config.Config {
+	path := resource.ResolvePath(fmt.Sprintf("config/config.%s.yaml", os.Getenv("APP_ENV")))
+	cfg, err := config.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		logger.Warn("設定ファイルが見つからないためデフォルト設定を使用します", "path", path)
+		return &config.Config{}
+	}
+	if err != nil {
+		logger.Error("設定ファイルの読み込みに失敗", "path", path, "error", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		logger.Error("不正な設定です", "path", path, "error", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// 相対パスをプロジェクトルートからのパスに解決する
+// 空の場合は空のまま返す
+func resolveOptionalPath(path string) string {
+	if path == "" {
+		return path
+	}
+	return resource.ResolvePath(path)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/evaluation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/resource"
+	"gopkg.in/yaml.v3"
+
+	"gocv.io/x/gocv"
+)
+
+// 探索結果の出力形式（設定ファイルのanalyzerセクションと同じ構造）
+type tunedConfig struct {
+	Analyzer struct {
+		Emotion config.EmotionConfig `yaml:"emotion"`
+	} `yaml:"analyzer"`
+}
+
+// データセットの1サンプル分の特徴量
+type featureSample struct {
+	path     string
+	label    string
+	features *analyzer.EmotionFeatures
+	err      error
+}
+
+func main() {
+	// コマンドライン引数の解析
+	var (
+		datasetPath string
+		cascadePath string
+		method      string
+		iterations  int
+		seed        int64
+		outputPath  string
+		format      string
+	)
+	space := evaluation.DefaultSearchSpace()
+
+	flag.StringVar(&datasetPath, "dataset", "", "ラベル付きデータセット（感情ごとのサブフォルダを持つディレクトリ、またはpath,labelのCSVマニフェスト）")
+	flag.StringVar(&cascadePath, "cascade", resource.ResolvePath("models/haarcascade_frontalface_default.xml"), "顔検出用のカスケード分類器")
+	flag.StringVar(&method, "method", "grid", "探索方法（grid, random）")
+	flag.IntVar(&iterations, "iterations", 5000, "ランダムサーチの試行回数")
+	flag.Int64Var(&seed, "seed", time.Now().UnixNano(), "ランダムサーチの乱数シード")
+	flag.StringVar(&outputPath, "output", "tuned_emotion.yaml", "最適なパラメータを書き出すYAMLファイル")
+	flag.StringVar(&format, "format", "table", "結果の出力形式（table, json）")
+	flag.Float64Var(&space.VariationMin, "variation-min", space.VariationMin, "変動閾値の探索下限")
+	flag.Float64Var(&space.VariationMax, "variation-max", space.VariationMax, "変動閾値の探索上限")
+	flag.Float64Var(&space.VariationStep, "variation-step", space.VariationStep, "変動閾値のグリッド幅")
+	flag.Float64Var(&space.BrightnessMin, "brightness-min", space.BrightnessMin, "輝度閾値の探索下限")
+	flag.Float64Var(&space.BrightnessMax, "brightness-max", space.BrightnessMax, "輝度閾値の探索上限")
+	flag.Float64Var(&space.BrightnessStep, "brightness-step", space.BrightnessStep, "輝度閾値のグリッド幅")
+	flag.Parse()
+
+	if datasetPath == "" {
+		fmt.Fprintln(os.Stderr, "-dataset を指定してください")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(datasetPath, cascadePath, method, iterations, seed, space, outputPath, format); err != nil {
+		slog.Error("パラメータの探索に失敗", "error", err)
+		os.Exit(1)
+	}
+}
+
+// パラメータ探索を実行して結果を出力
+func run(datasetPath, cascadePath, method string, iterations int, seed int64, space evaluation.SearchSpace, outputPath, format string) error {
+	samples, err := evaluation.LoadDataset(datasetPath)
+	if err != nil {
+		return err
+	}
+
+	// カスケード分類器の準備
+	cascade := gocv.NewCascadeClassifier()
+	defer cascade.Close()
+	if !cascade.Load(cascadePath) {
+		return fmt.Errorf("カスケード分類器の読み込みに失敗: %s", cascadePath)
+	}
+	faceAnalyzer := analyzer.New(&cascade, "", "", false)
+
+	// 特徴量は閾値に依存しないため最初に一度だけ抽出する
+	slog.Info("特徴量を抽出します", "samples", len(samples))
+	features := extractFeatures(faceAnalyzer, samples)
+
+	var candidates []config.EmotionConfig
+	switch method {
+	case "grid":
+		candidates, err = evaluation.GridCandidates(space)
+	case "random":
+		candidates, err = evaluation.RandomCandidates(space, iterations, rand.New(rand.NewSource(seed)))
+	default:
+		err = fmt.Errorf("不正な探索方法です: %s", method)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("パラメータを探索します", "method", method, "candidates", len(candidates))
+	result := evaluation.Tune(config.DefaultEmotionConfig(), candidates, func(params config.EmotionConfig) evaluation.Report {
+		return evaluation.ComputeReport(classify(features, params))
+	})
+
+	if err := writeTunedConfig(outputPath, result.Best); err != nil {
+		return err
+	}
+	slog.Info("最適なパラメータを書き出しました", "path", outputPath)
+
+	switch format {
+	case "json":
+		return evaluation.WriteJSON(os.Stdout, result)
+	case "table":
+		return evaluation.WriteTuningTable(os.Stdout, result)
+	default:
+		return fmt.Errorf("不正な出力形式です: %s", format)
+	}
+}
+
+// 各サンプルの主要な顔の特徴量を抽出
+func extractFeatures(fa *analyzer.FaceAnalyzer, samples []evaluation.Sample) []featureSample {
+	results := make([]featureSample, 0, len(samples))
+	for _, sample := range samples {
+		fs := featureSample{path: sample.Path, label: sample.Label}
+
+		data, err := os.ReadFile(sample.Path)
+		if err != nil {
+			fs.err = fmt.Errorf("画像の読み込みに失敗: %w", err)
+			results = append(results, fs)
+			continue
+		}
+
+		features, err := fa.ExtractEmotionFeatures(data)
+		if err != nil {
+			slog.Warn("特徴量の抽出に失敗", "path", sample.Path, "error", err)
+			fs.err = err
+		} else if len(features) > 0 {
+			fs.features = &features[0]
+		}
+		results = append(results, fs)
+	}
+	return results
+}
+
+// 抽出済みの特徴量を指定されたパラメータで分類
+func classify(samples []featureSample, params config.EmotionConfig) []evaluation.Prediction {
+	predictions := make([]evaluation.Prediction, len(samples))
+	for i, s := range samples {
+		predictions[i] = evaluation.Prediction{
+			Path:         s.path,
+			Label:        s.label,
+			FaceDetected: s.features != nil,
+			Err:          s.err,
+		}
+		if s.features != nil {
+			predictions[i].Predicted = string(analyzer.ClassifyEmotion(*s.features, params))
+		}
+	}
+	return predictions
+}
+
+// 最適なパラメータを設定ファイル形式で書き出す
+func writeTunedConfig(path string, params config.EmotionConfig) error {
+	var tuned tunedConfig
+	tuned.Analyzer.Emotion = params
+
+	data, err := yaml.Marshal(tuned)
+	if err != nil {
+		return fmt.Errorf("YAML形式への変換に失敗: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("ファイルの保存に失敗: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// 顔分析設定
+type AnalyzerConfig struct {
+	Emotion     EmotionConfig     `yaml:"emotion"`
+	Detection   DetectionConfig   `yaml:"detection"`
+	Preprocess  PreprocessConfig  `yaml:"preprocess"`
+	PrimaryFace PrimaryFaceConfig `yaml:"primary_face"`
+	Tracking    TrackingConfig    `yaml:"tracking"`
+	Embedding   EmbeddingConfig   `yaml:"embedding"`
+	Eyes        EyesConfig        `yaml:"eyes"`
+	Occlusion   OcclusionConfig   `yaml:"occlusion"`
+	Pipeline    PipelineConfig    `yaml:"pipeline"`
+	Explain     ExplainConfig     `yaml:"explain"`
+	ROI         ROI               `yaml:"roi"`
+	Crowd       CrowdConfig       `yaml:"crowd"`
+	Annotation  AnnotationConfig  `yaml:"annotation"`
+}
+
+// 処理済み画像に描画するラベルの設定
+type AnnotationConfig struct {
+	// TrueType/OpenTypeフォントのパス（空の場合は埋め込みの日本語フォントを使用）
+	Font     string  `yaml:"font"`
+	FontSize float64 `yaml:"font_size"`
+	// ラベルの背景に半透明の矩形を描画する
+	Background bool `yaml:"background"`
+	// リクエストでロケールが指定されない場合のラベルの言語（ja、en）
+	Locale string `yaml:"locale"`
+}
+
+// ラベルの言語
+var validAnnotationLocales = map[string]bool{
+	"ja": true,
+	"en": true,
+}
+
+// ラベルの言語かどうかを判定
+func IsValidAnnotationLocale(locale string) bool {
+	return validAnnotationLocales[locale]
+}
+
+// 群衆モードの設定
+// 大きな画像を重なりのあるタイルに分割して並列に検出し、小さな顔も検出できるようにする
+// 無効な場合は /analyze の crowd オプションを受け付けない
+type CrowdConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// タイルの一辺の長さ（ピクセル）
+	TileSize int `yaml:"tile_size"`
+	// 隣接するタイルとの重なりの割合（0〜0.5未満）
+	Overlap float64 `yaml:"overlap"`
+	// 並列に検出するタイルの数（0の場合はCPU数）
+	Workers int `yaml:"workers"`
+}
+
+// 正規化座標（0〜1）で指定する分析対象領域
+// 未設定の場合は画像全体を対象とし、polygon を指定した場合は矩形より優先する
+type ROI struct {
+	X       float64      `yaml:"x"`
+	Y       float64      `yaml:"y"`
+	Width   float64      `yaml:"width"`
+	Height  float64      `yaml:"height"`
+	Polygon [][2]float64 `yaml:"polygon"`
+}
+
+// 分析対象領域が未設定かを返す
+func (r ROI) IsZero() bool {
+	return len(r.Polygon) == 0 && r.X == 0 && r.Y == 0 && r.Width == 0 && r.Height == 0
+}
+
+// 判定根拠の出力設定
+// 無効な場合は /analyze の explain オプションを受け付けない
+type ExplainConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// 中間画像（グレースケール画像、平坦化した顔領域）の出力を許可する
+	Images       bool   `yaml:"images"`
+	SmileCascade string `yaml:"smile_cascade"`
+}
+
+// 分析パイプライン設定
+// stages を省略した場合は全ての段階を PipelineStages の順で実行する
+type PipelineConfig struct {
+	Stages []string `yaml:"stages"`
+}
+
+// 分析パイプラインの段階（実行順）
+var PipelineStages = []string{
+	"decode", "grayscale", "preprocess", "detect", "track", "classify", "select", "draw", "encode",
+}
+
+// 省略できない段階
+var requiredPipelineStages = []string{"decode", "grayscale", "detect", "classify", "select"}
+
+// 顔の下半分の遮蔽（マスク・手など）の検出設定
+type OcclusionConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// 額と口元の色差（Lab色空間のa*b*平面上の距離）がこの値以上なら口元が肌以外で覆われているとみなす
+	ColorThreshold float64 `yaml:"color_threshold"`
+	// 口元と目元のテクスチャ量の比がこの値未満なら口元の構造が見えていないとみなす
+	TextureThreshold float64 `yaml:"texture_threshold"`
+	// 遮蔽された顔の感情を unknown にする（false の場合は信頼度の減点のみ）
+	UnknownEmotion bool `yaml:"unknown_emotion"`
+	// 主要な顔が遮蔽されている場合に感情の信頼度から差し引く割合（0で無効）
+	ConfidencePenalty float64 `yaml:"confidence_penalty"`
+}
+
+// 目の開閉と視線方向の推定設定
+type EyesConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	Cascade         string  `yaml:"cascade"`
+	ClosedThreshold float64 `yaml:"closed_threshold"`
+	GazeThreshold   float64 `yaml:"gaze_threshold"`
+	// 主要な顔の目が閉じている場合に感情の信頼度から差し引く割合（0で無効）
+	ClosedConfidencePenalty float64 `yaml:"closed_confidence_penalty"`
+}
+
+// 顔の埋め込み設定
+// モデルを指定した場合のみ各顔の埋め込みを計算する
+type EmbeddingConfig struct {
+	Model     string `yaml:"model"`
+	InputSize int    `yaml:"input_size"`
+}
+
+// 主要な顔の選択設定
+// リクエストでポリシーが指定されない場合に使用する
+type PrimaryFaceConfig struct {
+	Policy string `yaml:"policy"`
+}
+
+// 顔のトラッキング設定
+// セッションごとに前回の検出結果とのIoUで顔を対応付け、同じIDを割り当てる
+type TrackingConfig struct {
+	IoUThreshold float64       `yaml:"iou_threshold"`
+	MaxMissed    int           `yaml:"max_missed"`
+	SessionTTL   time.Duration `yaml:"session_ttl"`
+	// 同時に追跡するセッション数の上限（0の場合はデフォルト値）
+	MaxSessions int `yaml:"max_sessions"`
+}
+
+// 前処理設定
+// 検出と感情分類の前にグレースケール画像へ steps の順で適用する
+type PreprocessConfig struct {
+	Steps             []string `yaml:"steps"`
+	LowLightThreshold float64  `yaml:"low_light_threshold"`
+	CLAHEClipLimit    float64  `yaml:"clahe_clip_limit"`
+	CLAHETileSize     int      `yaml:"clahe_tile_size"`
+	Gamma             float64  `yaml:"gamma"`
+	DenoiseKernel     int      `yaml:"denoise_kernel"`
+}
+
+// 顔検出設定
+// 複数の検出器を指定した場合は全ての結果をNMSで統合する
+type DetectionConfig struct {
+	Detectors      []string `yaml:"detectors"`
+	ProfileCascade string   `yaml:"profile_cascade"`
+	DNNProto       string   `yaml:"dnn_proto"`
+	DNNModel       string   `yaml:"dnn_model"`
+	DNNConfidence  float64  `yaml:"dnn_confidence"`
+	NMSThreshold   float64  `yaml:"nms_threshold"`
+}
+
+// 感情分類の閾値設定
+// 顔領域の輝度の標準偏差（variation）と平均（brightness）から感情を判定する
+type EmotionConfig struct {
+	SurpriseVariation   float64 `yaml:"surprise_variation"`
+	HappyVariation      float64 `yaml:"happy_variation"`
+	MidVariation        float64 `yaml:"mid_variation"`
+	LowVariation        float64 `yaml:"low_variation"`
+	BrightnessThreshold float64 `yaml:"brightness_threshold"`
+}
+
+// デフォルトの感情分類閾値を返す
+func DefaultEmotionConfig() EmotionConfig {
+	return EmotionConfig{
+		SurpriseVariation:   80,
+		HappyVariation:      65,
+		MidVariation:        50,
+		LowVariation:        35,
+		BrightnessThreshold: 140,
+	}
+}
+
+// 未設定の場合はデフォルト値を補完した設定を返す
+func (c EmotionConfig) WithDefaults() EmotionConfig {
+	if c == (EmotionConfig{}) {
+		return DefaultEmotionConfig()
+	}
+	return c
+}
+
+// 閾値の大小関係を検証
+func (c EmotionConfig) Validate() error {
+	if c == (EmotionConfig{}) {
+		return nil
+	}
+	if !(c.SurpriseVariation > c.HappyVariation &&
+		c.HappyVariation > c.MidVariation &&
+		c.MidVariation > c.LowVariation &&
+		c.LowVariation >= 0) {
+		return fmt.Errorf("感情分類の閾値は surprise > happy > mid > low >= 0 の順である必要があります")
+	}
+	if c.BrightnessThreshold < 0 || c.BrightnessThreshold > 255 {
+		return fmt.Errorf("不正な輝度閾値です: %v", c.BrightnessThreshold)
+	}
+	return nil
+}
+
+// 利用可能な顔検出器の名前
+var validDetectors = map[string]bool{
+	"frontal":         true,
+	"profile":         true,
+	"profile_flipped": true,
+	"dnn":             true,
+}
+
+// 顔検出設定の検証
+func (c DetectionConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Detectors))
+	for _, name := range c.Detectors {
+		if !validDetectors[name] {
+			return fmt.Errorf("不明な顔検出器です: %s", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("顔検出器が重複しています: %s", name)
+		}
+		seen[name] = true
+	}
+	if seen["dnn"] && (c.DNNProto == "" || c.DNNModel == "") {
+		return fmt.Errorf("DNN検出器にはdnn_protoとdnn_modelの指定が必要です")
+	}
+	if c.DNNConfidence < 0 || c.DNNConfidence > 1 {
+		return fmt.Errorf("不正なDNN信頼度の閾値です: %v", c.DNNConfidence)
+	}
+	if c.NMSThreshold < 0 || c.NMSThreshold > 1 {
+		return fmt.Errorf("不正なNMS閾値です: %v", c.NMSThreshold)
+	}
+	return nil
+}
+
+// 利用可能な前処理の名前
+var validPreprocessSteps = map[string]bool{
+	"clahe":   true,
+	"gamma":   true,
+	"denoise": true,
+}
+
+// 前処理設定の検証
+func (c PreprocessConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Steps))
+	for _, name := range c.Steps {
+		if !validPreprocessSteps[name] {
+			return fmt.Errorf("不明な前処理です: %s", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("前処理が重複しています: %s", name)
+		}
+		seen[name] = true
+	}
+	if c.LowLightThreshold < 0 || c.LowLightThreshold > 255 {
+		return fmt.Errorf("不正な低照度の閾値です: %v", c.LowLightThreshold)
+	}
+	if c.CLAHEClipLimit < 0 {
+		return fmt.Errorf("不正なCLAHEのクリップ上限です: %v", c.CLAHEClipLimit)
+	}
+	if c.CLAHETileSize < 0 {
+		return fmt.Errorf("不正なCLAHEのタイルサイズです: %d", c.CLAHETileSize)
+	}
+	if c.Gamma < 0 {
+		return fmt.Errorf("不正なガンマ値です: %v", c.Gamma)
+	}
+	if c.DenoiseKernel < 0 || (c.DenoiseKernel > 0 && c.DenoiseKernel%2 == 0) {
+		return fmt.Errorf("ノイズ除去のカーネルサイズは正の奇数である必要があります: %d", c.DenoiseKernel)
+	}
+	return nil
+}
+
+// 利用可能な主要な顔の選択ポリシー
+var validPrimaryFacePolicies = map[string]bool{
+	"largest":    true,
+	"center":     true,
+	"confidence": true,
+	"quality":    true,
+	"tracked":    true,
+}
+
+// 主要な顔の選択ポリシーかどうかを判定
+func IsValidPrimaryFacePolicy(policy string) bool {
+	return validPrimaryFacePolicies[policy]
+}
+
+// 主要な顔の選択設定の検証
+// トラッキングIDはリクエストごとに指定するため、tracked はデフォルトに指定できない
+func (c PrimaryFaceConfig) Validate() error {
+	if c.Policy == "" {
+		return nil
+	}
+	if !IsValidPrimaryFacePolicy(c.Policy) || c.Policy == "tracked" {
+		return fmt.Errorf("不正な主要な顔の選択ポリシーです: %s", c.Policy)
+	}
+	return nil
+}
+
+// トラッキング設定の検証
+func (c TrackingConfig) Validate() error {
+	if c.IoUThreshold < 0 || c.IoUThreshold > 1 {
+		return fmt.Errorf("不正なトラッキングのIoU閾値です: %v", c.IoUThreshold)
+	}
+	if c.MaxMissed < 0 {
+		return fmt.Errorf("不正な最大見失いフレーム数です: %d", c.MaxMissed)
+	}
+	if c.SessionTTL < 0 {
+		return fmt.Errorf("不正なセッションの有効期限です: %v", c.SessionTTL)
+	}
+	if c.MaxSessions < 0 {
+		return fmt.Errorf("不正な追跡するセッション数の上限です: %d", c.MaxSessions)
+	}
+	return nil
+}
+
+// 目の推定設定の検証
+func (c EyesConfig) Validate() error {
+	if c.ClosedThreshold < 0 || c.ClosedThreshold > 1 {
+		return fmt.Errorf("不正な閉眼の閾値です: %v", c.ClosedThreshold)
+	}
+	if c.GazeThreshold < 0 || c.GazeThreshold > 1 {
+		return fmt.Errorf("不正な視線方向の閾値です: %v", c.GazeThreshold)
+	}
+	if c.ClosedConfidencePenalty < 0 || c.ClosedConfidencePenalty > 1 {
+		return fmt.Errorf("不正な閉眼時の信頼度の減点です: %v", c.ClosedConfidencePenalty)
+	}
+	return nil
+}
+
+// 分析パイプライン設定の検証
+// 段階は PipelineStages の順で重複なく指定する
+func (c PipelineConfig) Validate() error {
+	if len(c.Stages) == 0 {
+		return nil
+	}
+
+	order := make(map[string]int, len(PipelineStages))
+	for i, name := range PipelineStages {
+		order[name] = i
+	}
+	last := -1
+	seen := make(map[string]bool, len(c.Stages))
+	for _, name := range c.Stages {
+		i, ok := order[name]
+		if !ok {
+			return fmt.Errorf("不明なパイプラインの段階です: %s", name)
+		}
+		if i <= last {
+			return fmt.Errorf("パイプラインの段階の順序が不正です: %s", name)
+		}
+		last = i
+		seen[name] = true
+	}
+	for _, name := range requiredPipelineStages {
+		if !seen[name] {
+			return fmt.Errorf("パイプラインに必須の段階がありません: %s", name)
+		}
+	}
+	return nil
+}
+
+// 判定根拠の出力設定の検証
+func (c ExplainConfig) Validate() error {
+	if c.Images && !c.Enabled {
+		return fmt.Errorf("中間画像の出力には判定根拠の出力の有効化が必要です")
+	}
+	return nil
+}
+
+// 分析対象領域の検証
+func (r ROI) Validate() error {
+	if len(r.Polygon) > 0 {
+		if len(r.Polygon) < 3 {
+			return fmt.Errorf("分析対象領域の多角形には3点以上が必要です: %d", len(r.Polygon))
+		}
+		for _, p := range r.Polygon {
+			if p[0] < 0 || p[0] > 1 || p[1] < 0 || p[1] > 1 {
+				return fmt.Errorf("分析対象領域の多角形の座標が範囲外です: %v", p)
+			}
+		}
+		return nil
+	}
+	if r.IsZero() {
+		return nil
+	}
+	if r.X < 0 || r.Y < 0 || r.Width <= 0 || r.Height <= 0 || r.X+r.Width > 1 || r.Y+r.Height > 1 {
+		return fmt.Errorf("不正な分析対象領域です: x=%v y=%v width=%v height=%v", r.X, r.Y, r.Width, r.Height)
+	}
+	return nil
+}
+
+// 群衆モード設定の検証
+func (c CrowdConfig) Validate() error {
+	if c.TileSize < 0 {
+		return fmt.Errorf("不正な群衆モードのタイルサイズです: %d", c.TileSize)
+	}
+	if c.Overlap < 0 || c.Overlap >= 0.5 {
+		return fmt.Errorf("不正な群衆モードのタイルの重なりです: %v", c.Overlap)
+	}
+	if c.Workers < 0 {
+		return fmt.Errorf("不正な群衆モードの並列数です: %d", c.Workers)
+	}
+	return nil
+}
+
+// ラベル設定の検証
+func (c AnnotationConfig) Validate() error {
+	if c.FontSize < 0 {
+		return fmt.Errorf("不正なラベルのフォントサイズです: %v", c.FontSize)
+	}
+	if c.Locale != "" && !IsValidAnnotationLocale(c.Locale) {
+		return fmt.Errorf("不正なラベルの言語です: %s", c.Locale)
+	}
+	return nil
+}
+
+// 遮蔽検出設定の検証
+func (c OcclusionConfig) Validate() error {
+	if c.ColorThreshold < 0 {
+		return fmt.Errorf("不正な遮蔽の色差の閾値です: %v", c.ColorThreshold)
+	}
+	if c.TextureThreshold < 0 || c.TextureThreshold > 1 {
+		return fmt.Errorf("不正な遮蔽のテクスチャ比の閾値です: %v", c.TextureThreshold)
+	}
+	if c.ConfidencePenalty < 0 || c.ConfidencePenalty > 1 {
+		return fmt.Errorf("不正な遮蔽時の信頼度の減点です: %v", c.ConfidencePenalty)
+	}
+	return nil
+}
+
+// 顔分析設定の検証
+func (c *AnalyzerConfig) Validate() error {
+	if err := c.Emotion.Validate(); err != nil {
+		return err
+	}
+	if err := c.Detection.Validate(); err != nil {
+		return err
+	}
+	if err := c.Preprocess.Validate(); err != nil {
+		return err
+	}
+	if err := c.PrimaryFace.Validate(); err != nil {
+		return err
+	}
+	if err := c.Tracking.Validate(); err != nil {
+		return err
+	}
+	if c.Embedding.InputSize < 0 {
+		return fmt.Errorf("不正な埋め込みモデルの入力サイズです: %d", c.Embedding.InputSize)
+	}
+	if err := c.Eyes.Validate(); err != nil {
+		return err
+	}
+	if err := c.Occlusion.Validate(); err != nil {
+		return err
+	}
+	if err := c.Pipeline.Validate(); err != nil {
+		return err
+	}
+	if err := c.Explain.Validate(); err != nil {
+		return err
+	}
+	if err := c.ROI.Validate(); err != nil {
+		return err
+	}
+	if err := c.Crowd.Validate(); err != nil {
+		return err
+	}
+	if err := c.Annotation.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
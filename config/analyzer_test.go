@@ -0,0 +1,355 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmotionConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  EmotionConfig
+		wantErr bool
+	}{
+		{
+			name:   "デフォルト値",
+			config: DefaultEmotionConfig(),
+		},
+		{
+			name:   "未設定",
+			config: EmotionConfig{},
+		},
+		{
+			name: "閾値の順序が不正",
+			config: EmotionConfig{
+				SurpriseVariation:   60,
+				HappyVariation:      65,
+				MidVariation:        50,
+				LowVariation:        35,
+				BrightnessThreshold: 140,
+			},
+			wantErr: true,
+		},
+		{
+			name: "輝度閾値が範囲外",
+			config: EmotionConfig{
+				SurpriseVariation:   80,
+				HappyVariation:      65,
+				MidVariation:        50,
+				LowVariation:        35,
+				BrightnessThreshold: 300,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEmotionConfig_WithDefaults(t *testing.T) {
+	assert.Equal(t, DefaultEmotionConfig(), EmotionConfig{}.WithDefaults())
+
+	custom := EmotionConfig{SurpriseVariation: 90, HappyVariation: 70, MidVariation: 40, LowVariation: 20, BrightnessThreshold: 120}
+	assert.Equal(t, custom, custom.WithDefaults())
+}
+
+func TestDetectionConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  DetectionConfig
+		wantErr bool
+	}{
+		{
+			name:   "未設定",
+			config: DetectionConfig{},
+		},
+		{
+			name: "複数の検出器",
+			config: DetectionConfig{
+				Detectors:    []string{"frontal", "profile", "profile_flipped"},
+				NMSThreshold: 0.3,
+			},
+		},
+		{
+			name:    "不明な検出器",
+			config:  DetectionConfig{Detectors: []string{"frontal", "mtcnn"}},
+			wantErr: true,
+		},
+		{
+			name:    "検出器の重複",
+			config:  DetectionConfig{Detectors: []string{"frontal", "frontal"}},
+			wantErr: true,
+		},
+		{
+			name:    "DNNモデルの指定なし",
+			config:  DetectionConfig{Detectors: []string{"dnn"}},
+			wantErr: true,
+		},
+		{
+			name:    "NMS閾値が範囲外",
+			config:  DetectionConfig{NMSThreshold: 1.5},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPreprocessConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PreprocessConfig
+		wantErr bool
+	}{
+		{
+			name:   "未設定",
+			config: PreprocessConfig{},
+		},
+		{
+			name: "全ての前処理",
+			config: PreprocessConfig{
+				Steps:             []string{"clahe", "gamma", "denoise"},
+				LowLightThreshold: 90,
+				CLAHEClipLimit:    2.0,
+				CLAHETileSize:     8,
+				Gamma:             1.5,
+				DenoiseKernel:     3,
+			},
+		},
+		{
+			name:    "不明な前処理",
+			config:  PreprocessConfig{Steps: []string{"sharpen"}},
+			wantErr: true,
+		},
+		{
+			name:    "前処理の重複",
+			config:  PreprocessConfig{Steps: []string{"gamma", "gamma"}},
+			wantErr: true,
+		},
+		{
+			name:    "カーネルサイズが偶数",
+			config:  PreprocessConfig{Steps: []string{"denoise"}, DenoiseKernel: 4},
+			wantErr: true,
+		},
+		{
+			name:    "低照度の閾値が範囲外",
+			config:  PreprocessConfig{LowLightThreshold: 300},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPipelineConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PipelineConfig
+		wantErr bool
+	}{
+		{
+			name:   "未設定",
+			config: PipelineConfig{},
+		},
+		{
+			name:   "全ての段階",
+			config: PipelineConfig{Stages: PipelineStages},
+		},
+		{
+			name:   "描画とエンコードを省略",
+			config: PipelineConfig{Stages: []string{"decode", "grayscale", "detect", "classify", "select"}},
+		},
+		{
+			name:    "不明な段階",
+			config:  PipelineConfig{Stages: []string{"decode", "grayscale", "detect", "classify", "select", "blur"}},
+			wantErr: true,
+		},
+		{
+			name:    "順序が不正",
+			config:  PipelineConfig{Stages: []string{"grayscale", "decode", "detect", "classify", "select"}},
+			wantErr: true,
+		},
+		{
+			name:    "段階の重複",
+			config:  PipelineConfig{Stages: []string{"decode", "grayscale", "detect", "detect", "classify", "select"}},
+			wantErr: true,
+		},
+		{
+			name:    "必須の段階がない",
+			config:  PipelineConfig{Stages: []string{"decode", "grayscale", "classify", "select"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestROI_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		roi     ROI
+		wantErr bool
+	}{
+		{
+			name: "未設定",
+			roi:  ROI{},
+		},
+		{
+			name: "中央の矩形",
+			roi:  ROI{X: 0.25, Y: 0.2, Width: 0.5, Height: 0.6},
+		},
+		{
+			name:    "画像の外にはみ出す矩形",
+			roi:     ROI{X: 0.6, Y: 0.2, Width: 0.5, Height: 0.6},
+			wantErr: true,
+		},
+		{
+			name:    "幅が0の矩形",
+			roi:     ROI{X: 0.2, Y: 0.2, Height: 0.5},
+			wantErr: true,
+		},
+		{
+			name: "多角形",
+			roi:  ROI{Polygon: [][2]float64{{0.2, 0.2}, {0.8, 0.2}, {0.5, 0.9}}},
+		},
+		{
+			name:    "2点の多角形",
+			roi:     ROI{Polygon: [][2]float64{{0.2, 0.2}, {0.8, 0.2}}},
+			wantErr: true,
+		},
+		{
+			name:    "範囲外の頂点",
+			roi:     ROI{Polygon: [][2]float64{{0.2, 0.2}, {1.2, 0.2}, {0.5, 0.9}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.roi.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCrowdConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CrowdConfig
+		wantErr bool
+	}{
+		{
+			name: "未設定",
+			cfg:  CrowdConfig{},
+		},
+		{
+			name: "有効な設定",
+			cfg:  CrowdConfig{Enabled: true, TileSize: 512, Overlap: 0.25, Workers: 4},
+		},
+		{
+			name:    "負のタイルサイズ",
+			cfg:     CrowdConfig{TileSize: -1},
+			wantErr: true,
+		},
+		{
+			name:    "重なりが0.5以上",
+			cfg:     CrowdConfig{Overlap: 0.5},
+			wantErr: true,
+		},
+		{
+			name:    "負の並列数",
+			cfg:     CrowdConfig{Workers: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAnnotationConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     AnnotationConfig
+		wantErr bool
+	}{
+		{
+			name: "未設定",
+			cfg:  AnnotationConfig{},
+		},
+		{
+			name: "英語のラベル",
+			cfg:  AnnotationConfig{FontSize: 20, Background: true, Locale: "en"},
+		},
+		{
+			name:    "負のフォントサイズ",
+			cfg:     AnnotationConfig{FontSize: -1},
+			wantErr: true,
+		},
+		{
+			name:    "不明な言語",
+			cfg:     AnnotationConfig{Locale: "fr"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
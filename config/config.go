@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -19,7 +22,15 @@ type Config struct {
 	Server   ServerConfig   `yaml:"server"`
 	Security SecurityConfig `yaml:"security"`
 	Image    ImageConfig    `yaml:"image"`
+	ImageURL ImageURLConfig `yaml:"image_url"`
 	OpenCV   OpenCVConfig   `yaml:"opencv"`
+	Analyzer AnalyzerConfig `yaml:"analyzer"`
+	Gallery  GalleryConfig  `yaml:"gallery"`
+	Session  SessionConfig  `yaml:"session"`
+	Jobs     JobsConfig     `yaml:"jobs"`
+	Stream   StreamConfig   `yaml:"stream"`
+	GRPC     GRPCConfig     `yaml:"grpc"`
+	Webhook  WebhookConfig  `yaml:"webhook"`
 	Logging  LoggingConfig  `yaml:"logging"`
 }
 
@@ -63,6 +74,70 @@ type ImageConfig struct {
 	Quality      int      `yaml:"quality"`
 }
 
+// URLで指定された画像の取得の設定
+type ImageURLConfig struct {
+	// imageUrl による画像の指定を許可するか
+	Enabled bool `yaml:"enabled"`
+	// 許可するスキーム（http、https）
+	AllowedSchemes []string `yaml:"allowed_schemes"`
+	// 許可するホスト（有効な場合は必須。"*.example.com" はサブドメインに、"*" はすべてのホストに一致する）
+	AllowedHosts []string `yaml:"allowed_hosts"`
+	// プライベートアドレスなどへの接続を例外として許可するネットワーク（CIDR）
+	AllowedNetworks []string `yaml:"allowed_networks"`
+	// 許可する Content-Type
+	AllowedTypes []string `yaml:"allowed_types"`
+	// 取得する画像の最大サイズ（バイト）
+	MaxSize int64 `yaml:"max_size"`
+	// 接続から読み取り完了までのタイムアウト
+	Timeout time.Duration `yaml:"timeout"`
+	// 追従するリダイレクトの最大回数
+	MaxRedirects int `yaml:"max_redirects"`
+}
+
+// 未設定かを返す
+func (c ImageURLConfig) IsZero() bool {
+	return !c.Enabled && len(c.AllowedSchemes) == 0 && len(c.AllowedHosts) == 0 &&
+		len(c.AllowedNetworks) == 0 && len(c.AllowedTypes) == 0 &&
+		c.MaxSize == 0 && c.Timeout == 0 && c.MaxRedirects == 0
+}
+
+// URLで指定された画像の取得の設定を検証
+func (c ImageURLConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if len(c.AllowedSchemes) == 0 {
+		return fmt.Errorf("許可するURLのスキームが設定されていません")
+	}
+	for _, scheme := range c.AllowedSchemes {
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("不正なURLのスキームです: %s", scheme)
+		}
+	}
+	// 許可リストが空の場合は任意のホストから取得できてしまうため、明示的な指定を必須にする
+	if len(c.AllowedHosts) == 0 {
+		return fmt.Errorf("許可するURLのホストが設定されていません")
+	}
+	for _, network := range c.AllowedNetworks {
+		if _, err := netip.ParsePrefix(network); err != nil {
+			return fmt.Errorf("不正なネットワークの指定です: %s: %w", network, err)
+		}
+	}
+	if len(c.AllowedTypes) == 0 {
+		return fmt.Errorf("許可する画像の形式が設定されていません")
+	}
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("不正な取得する画像の最大サイズです: %d", c.MaxSize)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("不正な画像の取得のタイムアウトです: %v", c.Timeout)
+	}
+	if c.MaxRedirects < 0 {
+		return fmt.Errorf("不正なリダイレクトの最大回数です: %d", c.MaxRedirects)
+	}
+	return nil
+}
+
 // OpenCV設定
 type OpenCVConfig struct {
 	CascadeFile  string  `yaml:"cascade_file"`
@@ -72,6 +147,197 @@ type OpenCVConfig struct {
 	Flags        int     `yaml:"flags"`
 }
 
+// ギャラリーの照合閾値のデフォルト値
+const DefaultMatchThreshold = 0.5
+
+// 登録済み人物のギャラリー設定
+type GalleryConfig struct {
+	Path string `yaml:"path"`
+	// 照合する類似度の閾値（0より大きく1以下、未設定の場合はデフォルト値）
+	MatchThreshold float64 `yaml:"match_threshold"`
+}
+
+// セッション集計設定
+type SessionConfig struct {
+	TTL                 time.Duration `yaml:"ttl"`
+	EngagementThreshold float64       `yaml:"engagement_threshold"`
+	// SSEの購読者ごとに保持するイベント数
+	EventBuffer int `yaml:"event_buffer"`
+	// SSEの接続を維持するためのハートビートの間隔
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	// 保持するセッション数の上限（0の場合はデフォルト値）
+	MaxSessions int `yaml:"max_sessions"`
+}
+
+// 非同期の分析ジョブの設定
+type JobsConfig struct {
+	// 終了したジョブの結果を保持する期間
+	TTL time.Duration `yaml:"ttl"`
+	// ジョブを実行するワーカー数の範囲
+	MinWorkers int `yaml:"min_workers"`
+	MaxWorkers int `yaml:"max_workers"`
+	// 待機中と実行中のジョブ数の上限（0の場合はデフォルト値）
+	MaxPending int `yaml:"max_pending"`
+	// リクエストボディと画像データのサイズ上限（バイト、0の場合はデフォルト値）
+	MaxUploadSize int64 `yaml:"max_upload_size"`
+}
+
+// 非同期の分析ジョブの設定を検証
+func (c JobsConfig) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("不正なジョブの保持期間です: %v", c.TTL)
+	}
+	if c.MinWorkers < 0 || c.MaxWorkers < 0 {
+		return fmt.Errorf("不正なジョブのワーカー数です: %d, %d", c.MinWorkers, c.MaxWorkers)
+	}
+	if c.MaxWorkers > 0 && c.MaxWorkers < c.MinWorkers {
+		return fmt.Errorf("ジョブの最大ワーカー数が最小ワーカー数より小さいです: %d < %d", c.MaxWorkers, c.MinWorkers)
+	}
+	if c.MaxPending < 0 {
+		return fmt.Errorf("不正なジョブ数の上限です: %d", c.MaxPending)
+	}
+	if c.MaxUploadSize < 0 {
+		return fmt.Errorf("不正なジョブのアップロードサイズの上限です: %d", c.MaxUploadSize)
+	}
+	return nil
+}
+
+// WebSocketによる連続分析の設定
+type StreamConfig struct {
+	// 1接続あたりの分析するフレーム数の上限（0の場合は制限しない）
+	MaxFPS float64 `yaml:"max_fps"`
+	// 感情とエンゲージメントスコアの平滑化に使用するフレーム数（1以下の場合は平滑化しない）
+	SmoothingWindow int `yaml:"smoothing_window"`
+}
+
+// 連続分析の設定を検証
+func (c StreamConfig) Validate() error {
+	if c.MaxFPS < 0 {
+		return fmt.Errorf("不正なフレームレートの上限です: %v", c.MaxFPS)
+	}
+	if c.SmoothingWindow < 0 {
+		return fmt.Errorf("不正な平滑化のフレーム数です: %d", c.SmoothingWindow)
+	}
+	return nil
+}
+
+// gRPCサーバーの設定
+type GRPCConfig struct {
+	// 待ち受けるポート（空の場合はgRPCサーバーを起動しない）
+	Port string `yaml:"port"`
+}
+
+// gRPCサーバーの設定を検証
+func (c GRPCConfig) Validate() error {
+	if c.Port == "" {
+		return nil
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port <= 0 || port > 65535 {
+		return fmt.Errorf("不正なgRPCのポートです: %s", c.Port)
+	}
+	return nil
+}
+
+// 分析とジョブの完了を通知するWebhookの設定
+type WebhookConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// 署名に使用する秘密鍵（環境変数 WEBHOOK_SECRET で上書きできる）
+	Secret string `yaml:"secret"`
+	// Webhookを登録できるAPIキー（環境変数 WEBHOOK_API_KEYS にカンマ区切りで指定できる）
+	APIKeys []string `yaml:"api_keys"`
+	// 配信に失敗したイベントを参照する管理用のトークン（空の場合は管理用のエンドポイントを公開しない）
+	AdminToken string `yaml:"admin_token"`
+	// 配信先のURLの制限（image_url と同じ形式）
+	AllowedSchemes  []string `yaml:"allowed_schemes"`
+	AllowedHosts    []string `yaml:"allowed_hosts"`
+	AllowedNetworks []string `yaml:"allowed_networks"`
+	// 1回の配信のタイムアウト
+	Timeout time.Duration `yaml:"timeout"`
+	// 再試行を含めた最大の配信回数
+	MaxAttempts int `yaml:"max_attempts"`
+	// 再試行の間隔（失敗するごとに2倍にし、max_backoff を上限とする）
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+	// 配信するワーカー数と待機できる配信の数
+	Workers   int `yaml:"workers"`
+	QueueSize int `yaml:"queue_size"`
+	// 保持する配信に失敗したイベントの数
+	DeadLetterSize int `yaml:"dead_letter_size"`
+	// APIキーごとに登録できるWebhookの数
+	MaxRegistrations int `yaml:"max_registrations"`
+	// 登録を保存するファイルのパス（空の場合はメモリ上でのみ保持し、再起動すると消える）
+	StorePath string `yaml:"store_path"`
+}
+
+// 未設定かを返す
+func (c WebhookConfig) IsZero() bool {
+	return !c.Enabled && c.Secret == "" && len(c.APIKeys) == 0 && c.AdminToken == "" &&
+		len(c.AllowedSchemes) == 0 && len(c.AllowedHosts) == 0 && len(c.AllowedNetworks) == 0 &&
+		c.Timeout == 0 && c.MaxAttempts == 0 && c.InitialBackoff == 0 && c.MaxBackoff == 0 &&
+		c.Workers == 0 && c.QueueSize == 0 && c.DeadLetterSize == 0 && c.MaxRegistrations == 0 &&
+		c.StorePath == ""
+}
+
+// 秘密鍵などを設定ファイルに書かずに済むよう、環境変数で上書きする
+func (c *WebhookConfig) overrideWithEnv() {
+	if secret := os.Getenv("WEBHOOK_SECRET"); secret != "" {
+		c.Secret = secret
+	}
+	if keys := os.Getenv("WEBHOOK_API_KEYS"); keys != "" {
+		c.APIKeys = nil
+		for _, key := range strings.Split(keys, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				c.APIKeys = append(c.APIKeys, key)
+			}
+		}
+	}
+	if token := os.Getenv("WEBHOOK_ADMIN_TOKEN"); token != "" {
+		c.AdminToken = token
+	}
+}
+
+// Webhookの設定を検証
+func (c WebhookConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Secret == "" {
+		return fmt.Errorf("Webhookの署名の秘密鍵が設定されていません")
+	}
+	if len(c.AllowedSchemes) == 0 {
+		return fmt.Errorf("許可するWebhookのスキームが設定されていません")
+	}
+	for _, scheme := range c.AllowedSchemes {
+		if scheme != "http" && scheme != "https" {
+			return fmt.Errorf("不正なWebhookのスキームです: %s", scheme)
+		}
+	}
+	for _, network := range c.AllowedNetworks {
+		if _, err := netip.ParsePrefix(network); err != nil {
+			return fmt.Errorf("不正なネットワークの指定です: %s: %w", network, err)
+		}
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("不正なWebhookのタイムアウトです: %v", c.Timeout)
+	}
+	if c.MaxAttempts <= 0 {
+		return fmt.Errorf("不正なWebhookの最大配信回数です: %d", c.MaxAttempts)
+	}
+	if c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("不正なWebhookの再試行の間隔です: %v, %v", c.InitialBackoff, c.MaxBackoff)
+	}
+	if c.Workers <= 0 || c.QueueSize <= 0 {
+		return fmt.Errorf("不正なWebhookのワーカー数またはキューのサイズです: %d, %d", c.Workers, c.QueueSize)
+	}
+	if c.DeadLetterSize <= 0 {
+		return fmt.Errorf("不正な配信に失敗したイベントの保持数です: %d", c.DeadLetterSize)
+	}
+	if c.MaxRegistrations <= 0 {
+		return fmt.Errorf("不正なWebhookの登録数の上限です: %d", c.MaxRegistrations)
+	}
+	return nil
+}
+
 // ログ設定
 type LoggingConfig struct {
 	Level  string            `yaml:"level"`
@@ -102,10 +368,19 @@ func Load(path string) (*Config, error) {
 	if port := os.Getenv("PORT"); port != "" {
 		cfg.Server.Port = port
 	}
+	cfg.Webhook.overrideWithEnv()
+	cfg.applyDefaults()
 
 	return &cfg, nil
 }
 
+// 未設定の項目にデフォルト値を設定
+func (c *Config) applyDefaults() {
+	if c.Gallery.MatchThreshold == 0 {
+		c.Gallery.MatchThreshold = DefaultMatchThreshold
+	}
+}
+
 // 設定値の検証
 func (c *Config) Validate() error {
 	if c.App.Name == "" {
@@ -120,6 +395,45 @@ func (c *Config) Validate() error {
 	if c.OpenCV.ScaleFactor <= 1.0 {
 		return fmt.Errorf("不正なスケールファクターです")
 	}
+	if err := c.ImageURL.Validate(); err != nil {
+		return err
+	}
+	if err := c.Analyzer.Validate(); err != nil {
+		return err
+	}
+	if c.Gallery.MatchThreshold <= 0 || c.Gallery.MatchThreshold > 1 {
+		return fmt.Errorf("不正なギャラリーの照合閾値です: %v", c.Gallery.MatchThreshold)
+	}
+	if c.Session.TTL < 0 {
+		return fmt.Errorf("不正なセッションの保持期間です: %v", c.Session.TTL)
+	}
+	if c.Session.EngagementThreshold < 0 || c.Session.EngagementThreshold > 1 {
+		return fmt.Errorf("不正なエンゲージメントの閾値です: %v", c.Session.EngagementThreshold)
+	}
+	if c.Session.EventBuffer < 0 {
+		return fmt.Errorf("不正なイベントのバッファサイズです: %d", c.Session.EventBuffer)
+	}
+	if c.Session.HeartbeatInterval < 0 {
+		return fmt.Errorf("不正なハートビートの間隔です: %v", c.Session.HeartbeatInterval)
+	}
+	if c.Session.MaxSessions < 0 {
+		return fmt.Errorf("不正なセッション数の上限です: %d", c.Session.MaxSessions)
+	}
+	if err := c.Jobs.Validate(); err != nil {
+		return err
+	}
+	if err := c.Stream.Validate(); err != nil {
+		return err
+	}
+	if err := c.GRPC.Validate(); err != nil {
+		return err
+	}
+	if c.GRPC.Port != "" && c.GRPC.Port == c.Server.Port {
+		return fmt.Errorf("gRPCのポートがサーバーポートと同じです: %s", c.GRPC.Port)
+	}
+	if err := c.Webhook.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
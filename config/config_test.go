@@ -0,0 +1,390 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  JobsConfig
+		wantErr bool
+	}{
+		{
+			name:   "未設定",
+			config: JobsConfig{},
+		},
+		{
+			name:   "有効な設定",
+			config: JobsConfig{TTL: time.Hour, MinWorkers: 1, MaxWorkers: 4, MaxPending: 100},
+		},
+		{
+			name:    "負の保持期間",
+			config:  JobsConfig{TTL: -time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "負のワーカー数",
+			config:  JobsConfig{MinWorkers: -1},
+			wantErr: true,
+		},
+		{
+			name:    "最大ワーカー数が最小ワーカー数より小さい",
+			config:  JobsConfig{MinWorkers: 4, MaxWorkers: 2},
+			wantErr: true,
+		},
+		{
+			name:    "負のジョブ数の上限",
+			config:  JobsConfig{MaxPending: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStreamConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  StreamConfig
+		wantErr bool
+	}{
+		{
+			name:   "未設定",
+			config: StreamConfig{},
+		},
+		{
+			name:   "有効な設定",
+			config: StreamConfig{MaxFPS: 10, SmoothingWindow: 5},
+		},
+		{
+			name:    "負のフレームレート",
+			config:  StreamConfig{MaxFPS: -1},
+			wantErr: true,
+		},
+		{
+			name:    "負の平滑化のフレーム数",
+			config:  StreamConfig{SmoothingWindow: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGRPCConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  GRPCConfig
+		wantErr bool
+	}{
+		{
+			name:   "無効",
+			config: GRPCConfig{},
+		},
+		{
+			name:   "有効なポート",
+			config: GRPCConfig{Port: "9090"},
+		},
+		{
+			name:    "数値でないポート",
+			config:  GRPCConfig{Port: "grpc"},
+			wantErr: true,
+		},
+		{
+			name:    "範囲外のポート",
+			config:  GRPCConfig{Port: "70000"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestImageURLConfig_Validate(t *testing.T) {
+	valid := ImageURLConfig{
+		Enabled:         true,
+		AllowedSchemes:  []string{"https"},
+		AllowedHosts:    []string{"images.example.com"},
+		AllowedNetworks: []string{"10.0.0.0/8"},
+		AllowedTypes:    []string{"image/jpeg"},
+		MaxSize:         1024,
+		Timeout:         time.Second,
+		MaxRedirects:    2,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *ImageURLConfig)
+		wantErr bool
+	}{
+		{
+			name:   "有効な設定",
+			modify: func(c *ImageURLConfig) {},
+		},
+		{
+			name:   "無効な場合は検証しない",
+			modify: func(c *ImageURLConfig) { *c = ImageURLConfig{} },
+		},
+		{
+			name:    "スキームが未設定",
+			modify:  func(c *ImageURLConfig) { c.AllowedSchemes = nil },
+			wantErr: true,
+		},
+		{
+			name:    "不正なスキーム",
+			modify:  func(c *ImageURLConfig) { c.AllowedSchemes = []string{"file"} },
+			wantErr: true,
+		},
+		{
+			name:    "ホストが未設定",
+			modify:  func(c *ImageURLConfig) { c.AllowedHosts = nil },
+			wantErr: true,
+		},
+		{
+			name:    "不正なネットワーク",
+			modify:  func(c *ImageURLConfig) { c.AllowedNetworks = []string{"10.0.0.0"} },
+			wantErr: true,
+		},
+		{
+			name:    "形式が未設定",
+			modify:  func(c *ImageURLConfig) { c.AllowedTypes = nil },
+			wantErr: true,
+		},
+		{
+			name:    "最大サイズが0",
+			modify:  func(c *ImageURLConfig) { c.MaxSize = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "タイムアウトが0",
+			modify:  func(c *ImageURLConfig) { c.Timeout = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "負のリダイレクト回数",
+			modify:  func(c *ImageURLConfig) { c.MaxRedirects = -1 },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookConfig_Validate(t *testing.T) {
+	valid := WebhookConfig{
+		Enabled:          true,
+		Secret:           "secret",
+		AllowedSchemes:   []string{"https"},
+		AllowedNetworks:  []string{"10.0.0.0/8"},
+		Timeout:          time.Second,
+		MaxAttempts:      3,
+		InitialBackoff:   time.Second,
+		MaxBackoff:       time.Minute,
+		Workers:          1,
+		QueueSize:        10,
+		DeadLetterSize:   10,
+		MaxRegistrations: 5,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *WebhookConfig)
+		wantErr bool
+	}{
+		{
+			name:   "有効な設定",
+			modify: func(c *WebhookConfig) {},
+		},
+		{
+			name:   "無効な場合は検証しない",
+			modify: func(c *WebhookConfig) { *c = WebhookConfig{} },
+		},
+		{
+			name:    "秘密鍵が未設定",
+			modify:  func(c *WebhookConfig) { c.Secret = "" },
+			wantErr: true,
+		},
+		{
+			name:    "不正なスキーム",
+			modify:  func(c *WebhookConfig) { c.AllowedSchemes = []string{"ftp"} },
+			wantErr: true,
+		},
+		{
+			name:    "不正なネットワーク",
+			modify:  func(c *WebhookConfig) { c.AllowedNetworks = []string{"10.0.0.0"} },
+			wantErr: true,
+		},
+		{
+			name:    "配信回数が0",
+			modify:  func(c *WebhookConfig) { c.MaxAttempts = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "再試行の間隔の上限が初期値より短い",
+			modify:  func(c *WebhookConfig) { c.MaxBackoff = time.Millisecond },
+			wantErr: true,
+		},
+		{
+			name:    "ワーカー数が0",
+			modify:  func(c *WebhookConfig) { c.Workers = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "保持数が0",
+			modify:  func(c *WebhookConfig) { c.DeadLetterSize = 0 },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWebhookConfig_OverrideWithEnv(t *testing.T) {
+	t.Setenv("WEBHOOK_SECRET", "env-secret")
+	t.Setenv("WEBHOOK_API_KEYS", "key1, key2,")
+	t.Setenv("WEBHOOK_ADMIN_TOKEN", "admin")
+
+	c := WebhookConfig{Secret: "file-secret", APIKeys: []string{"file-key"}}
+	c.overrideWithEnv()
+	assert.Equal(t, "env-secret", c.Secret)
+	assert.Equal(t, []string{"key1", "key2"}, c.APIKeys)
+	assert.Equal(t, "admin", c.AdminToken)
+}
+
+func TestLoad_MatchThreshold(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want float64
+	}{
+		{
+			name: "未設定の場合はデフォルト値",
+			yaml: "gallery:\n  path: \"\"\n",
+			want: DefaultMatchThreshold,
+		},
+		{
+			name: "ギャラリーの設定なし",
+			yaml: "app:\n  name: test\n",
+			want: DefaultMatchThreshold,
+		},
+		{
+			name: "指定した値",
+			yaml: "gallery:\n  match_threshold: 0.8\n",
+			want: 0.8,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.yaml), 0644))
+
+			cfg, err := Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.Gallery.MatchThreshold)
+		})
+	}
+}
+
+func TestConfig_Validate_MatchThreshold(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold float64
+		wantErr   bool
+	}{
+		{name: "有効な閾値", threshold: 0.5},
+		{name: "閾値が0", threshold: 0, wantErr: true},
+		{name: "負の閾値", threshold: -0.1, wantErr: true},
+		{name: "1より大きい閾値", threshold: 1.1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{}
+			c.App.Name = "test"
+			c.Server.Port = "8080"
+			c.Image.MaxSize = 1024
+			c.OpenCV.ScaleFactor = 1.1
+			c.Gallery.MatchThreshold = tt.threshold
+
+			err := c.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// サーバーは起動時に検証するため、同梱する設定ファイルはすべて検証を通る必要がある
+func TestLoad_BundledConfigs(t *testing.T) {
+	for _, env := range []string{"development", "production", "test"} {
+		t.Run(env, func(t *testing.T) {
+			cfg, err := Load("config." + env + ".yaml")
+			require.NoError(t, err)
+			assert.NoError(t, cfg.Validate())
+		})
+	}
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
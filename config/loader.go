@@ -53,6 +53,8 @@ func (l *ConfigLoader) LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	baseConfig.applyDefaults()
+
 	// 設定の検証
 	if err := baseConfig.Validate(); err != nil {
 		return nil, fmt.Errorf("設定の検証に失敗: %w", err)
@@ -128,6 +130,82 @@ func (l *ConfigLoader) mergeConfigs(base, override *Config) error {
 		base.Server.Port = override.Server.Port
 	}
 
+	// URLで指定された画像の取得の設定の上書き
+	if !override.ImageURL.IsZero() {
+		base.ImageURL = override.ImageURL
+	}
+
+	// 顔分析設定の上書き
+	if override.Analyzer.Emotion != (EmotionConfig{}) {
+		base.Analyzer.Emotion = override.Analyzer.Emotion
+	}
+	if len(override.Analyzer.Detection.Detectors) > 0 {
+		base.Analyzer.Detection = override.Analyzer.Detection
+	}
+	if len(override.Analyzer.Preprocess.Steps) > 0 {
+		base.Analyzer.Preprocess = override.Analyzer.Preprocess
+	}
+	if override.Analyzer.PrimaryFace.Policy != "" {
+		base.Analyzer.PrimaryFace = override.Analyzer.PrimaryFace
+	}
+	if override.Analyzer.Tracking != (TrackingConfig{}) {
+		base.Analyzer.Tracking = override.Analyzer.Tracking
+	}
+	if override.Analyzer.Embedding != (EmbeddingConfig{}) {
+		base.Analyzer.Embedding = override.Analyzer.Embedding
+	}
+	if override.Analyzer.Eyes != (EyesConfig{}) {
+		base.Analyzer.Eyes = override.Analyzer.Eyes
+	}
+	if override.Analyzer.Occlusion != (OcclusionConfig{}) {
+		base.Analyzer.Occlusion = override.Analyzer.Occlusion
+	}
+	if len(override.Analyzer.Pipeline.Stages) > 0 {
+		base.Analyzer.Pipeline = override.Analyzer.Pipeline
+	}
+	if override.Analyzer.Explain != (ExplainConfig{}) {
+		base.Analyzer.Explain = override.Analyzer.Explain
+	}
+	if !override.Analyzer.ROI.IsZero() {
+		base.Analyzer.ROI = override.Analyzer.ROI
+	}
+	if override.Analyzer.Crowd != (CrowdConfig{}) {
+		base.Analyzer.Crowd = override.Analyzer.Crowd
+	}
+	if override.Analyzer.Annotation != (AnnotationConfig{}) {
+		base.Analyzer.Annotation = override.Analyzer.Annotation
+	}
+
+	// ギャラリー設定の上書き
+	if override.Gallery != (GalleryConfig{}) {
+		base.Gallery = override.Gallery
+	}
+
+	// セッション集計設定の上書き
+	if override.Session != (SessionConfig{}) {
+		base.Session = override.Session
+	}
+
+	// 非同期ジョブの設定の上書き
+	if override.Jobs != (JobsConfig{}) {
+		base.Jobs = override.Jobs
+	}
+
+	// 連続分析の設定の上書き
+	if override.Stream != (StreamConfig{}) {
+		base.Stream = override.Stream
+	}
+
+	// gRPCサーバーの設定の上書き
+	if override.GRPC != (GRPCConfig{}) {
+		base.GRPC = override.GRPC
+	}
+
+	// Webhookの設定の上書き
+	if !override.Webhook.IsZero() {
+		base.Webhook = override.Webhook
+	}
+
 	return nil
 }
 
@@ -148,6 +226,9 @@ func (l *ConfigLoader) overrideWithEnv(config *Config) error {
 	if host := os.Getenv("HOST"); host != "" {
 		config.Server.Host = host
 	}
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		config.GRPC.Port = grpcPort
+	}
 
 	// セキュリティ設定
 	if origins := os.Getenv("ALLOWED_ORIGINS"); origins != "" {
@@ -161,6 +242,9 @@ func (l *ConfigLoader) overrideWithEnv(config *Config) error {
 		config.Security.RateLimit.RequestsPerMinute = limit
 	}
 
+	// Webhook設定
+	config.Webhook.overrideWithEnv()
+
 	// ロギング設定
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		config.Logging.Level = logLevel
@@ -3,17 +3,52 @@ package analyzer
 import (
 	"fmt"
 	"image"
-	"image/color"
+	"log"
+	"time"
 
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/resource"
 	"gocv.io/x/gocv"
 )
 
+// 顔検出のデフォルト設定
+const (
+	defaultNMSThreshold   = 0.3
+	defaultDNNConfidence  = 0.5
+	defaultProfileCascade = "models/haarcascade_profileface.xml"
+)
+
 // 感情を表すための文字列型
 type Emotion string
 
 // 顔分析機能のインターフェース
 type FaceAnalyzerInterface interface {
 	Analyze(imgData []byte) (*AnalysisResult, error)
+	AnalyzeWithOptions(imgData []byte, opts AnalyzeOptions) (*AnalysisResult, error)
+}
+
+// リクエストごとの分析オプション
+type AnalyzeOptions struct {
+	// 主要な顔の選択ポリシー（空の場合は設定ファイルの値を使用）
+	PrimaryPolicy string
+	// PrimaryPolicy が tracked の場合に主要な顔とするトラッキングID
+	TrackID int
+	// トラッキングのセッションID（空の場合はトラッキングしない）
+	SessionID string
+	// 各顔の判定根拠を含める
+	Explain bool
+	// 判定根拠に中間画像（グレースケール画像、平坦化した顔領域）を含める
+	ExplainImages bool
+	// 分析対象領域（nil の場合は設定ファイルの値を使用）
+	ROI *config.ROI
+	// 群衆モード（タイルに分割して検出し、全体の集計を含める）
+	Crowd bool
+	// 処理済み画像のラベルの言語（空の場合は設定ファイルの値を使用）
+	Locale string
+	// 段階が終わるごとに進捗（0〜1）を通知する
+	Progress func(float64)
 }
 
 const (
@@ -27,10 +62,31 @@ const (
 
 // 検出された顔の領域を保持する構造体
 type Face struct {
-	X      float64
-	Y      float64
-	Width  float64
-	Height float64
+	X              float64
+	Y              float64
+	Width          float64
+	Height         float64
+	Detector       string
+	DetectionScore float64
+	TrackID        int
+	Quality        float64
+	Emotion        Emotion
+	// 埋め込みモデルが設定されている場合のみ計算する
+	Embedding []float32
+	// 目の推定が有効な場合のみ設定する
+	Eyes *EyeAnalysis
+	// 遮蔽検出が有効な場合のみ設定する
+	Occlusion *OcclusionAnalysis
+	// 0〜1のエンゲージメントスコア
+	Engagement float64
+	// 判定根拠の出力を指定した場合のみ設定する
+	Explanation *FaceExplanation
+}
+
+// 感情分類に使用する顔領域の特徴量
+type EmotionFeatures struct {
+	Brightness float64
+	Variation  float64
 }
 
 // 分析結果を格納する構造体
@@ -39,124 +95,432 @@ type AnalysisResult struct {
 	PrimaryEmotion     Emotion
 	Confidence         float32
 	ProcessedImageData []byte
+	Preprocessing      []string
+	// 主要な顔のインデックス（顔がない場合は -1）
+	PrimaryIndex   int
+	PrimaryTrackID int
+	PrimaryPolicy  string
+	// 入力画像のサイズ
+	ImageWidth  int
+	ImageHeight int
+	// パイプラインの段階ごとの処理時間（実行順）
+	StageTimings []StageTiming
+	// 前処理後のグレースケール画像のPNG画像（中間画像の出力を指定した場合のみ）
+	GrayscaleImage []byte
+	// 群衆モードを指定した場合の全体の集計
+	Crowd *CrowdSummary
 }
 
 // 顔検出・感情分析を行うための構造体
 type FaceAnalyzer struct {
-	cascade gocv.CascadeClassifier
-	net     gocv.Net
-	useDNN  bool
+	cascade       gocv.CascadeClassifier
+	net           gocv.Net
+	hasNet        bool
+	useDNN        bool
+	emotionParams config.EmotionConfig
+	detectors     []Detector
+	nmsThreshold  float64
+	preprocess    *preprocessor
+	primaryPolicy string
+	tracker       *Tracker
+	embedder      *embedder
+	eyes          *eyeAnalyzer
+	eyePenalty    float64
+	occlusion     *occlusionDetector
+	// 遮蔽された顔の感情を unknown にするか
+	occlusionUnknown bool
+	occlusionPenalty float64
+	pipeline         []Stage
+	smile            *gocv.CascadeClassifier
+	roi              config.ROI
+	crowd            *crowdDetector
+	labels           *annotation.Renderer
+	// 段階ごとの処理時間の通知先（メトリクスの記録など）
+	stageObserver func(stage string, d time.Duration)
 }
 
 // FaceAnalyzerのインスタンスを生成するためのコンストラクタ
 // protoPath, modelPathは DNN を使う場合に指定。useDNN が false の場合は無視される。
 func New(cascade *gocv.CascadeClassifier, protoPath, modelPath string, useDNN bool) *FaceAnalyzer {
 	net := gocv.Net{}
-	if useDNN && protoPath != "" && modelPath != "" {
+	hasNet := useDNN && protoPath != "" && modelPath != ""
+	if hasNet {
 		net = gocv.ReadNetFromCaffe(protoPath, modelPath)
 	}
-	return &FaceAnalyzer{
-		cascade: *cascade,
-		net:     net,
-		useDNN:  useDNN,
+	fa := &FaceAnalyzer{
+		cascade:       *cascade,
+		net:           net,
+		hasNet:        hasNet,
+		useDNN:        useDNN,
+		emotionParams: config.DefaultEmotionConfig(),
+		nmsThreshold:  defaultNMSThreshold,
+		primaryPolicy: PrimaryLargest,
+		tracker:       NewTracker(0, 0, 0, 0),
 	}
+	fa.detectors = fa.defaultDetectors()
+	// 埋め込みフォントは常に読み込めるため失敗しない
+	fa.labels, _ = annotation.New(config.AnnotationConfig{})
+	// 既定の段階名は全て定義済みのため失敗しない
+	fa.pipeline, _ = fa.buildPipeline(nil)
+	return fa
+}
+
+// コンストラクタで渡された分類器とモデルによる検出器の構成
+func (fa *FaceAnalyzer) defaultDetectors() []Detector {
+	detectors := []Detector{&cascadeDetector{
+		name:    DetectorFrontal,
+		cascade: &fa.cascade,
+		score:   frontalCascadeScore,
+	}}
+	if fa.hasNet && !fa.net.Empty() {
+		detectors = append(detectors, &dnnDetector{
+			net:           &fa.net,
+			minConfidence: defaultDNNConfidence,
+		})
+	}
+	return detectors
+}
+
+// 設定ファイルの内容をアナライザーに反映
+// 検出器のモデルを読み込むため、リクエストの処理を開始する前に呼び出すこと
+func (fa *FaceAnalyzer) Configure(cfg config.AnalyzerConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("顔分析設定が不正です: %w", err)
+	}
+
+	pipeline, err := fa.buildPipeline(cfg.Pipeline.Stages)
+	if err != nil {
+		return err
+	}
+
+	annotationCfg := cfg.Annotation
+	if annotationCfg.Font != "" {
+		annotationCfg.Font = resource.ResolvePath(annotationCfg.Font)
+	}
+	labels, err := annotation.New(annotationCfg)
+	if err != nil {
+		return fmt.Errorf("ラベルのフォントの初期化に失敗: %w", err)
+	}
+
+	detectors, err := fa.buildDetectors(cfg.Detection)
+	if err != nil {
+		return err
+	}
+
+	var emb *embedder
+	if cfg.Embedding.Model != "" {
+		emb, err = newEmbedder(resource.ResolvePath(cfg.Embedding.Model), cfg.Embedding.InputSize)
+		if err != nil {
+			closeAll(detectors)
+			return fmt.Errorf("埋め込みモデルの初期化に失敗: %w", err)
+		}
+	}
+
+	var eyes *eyeAnalyzer
+	if cfg.Eyes.Enabled {
+		cascadePath := cfg.Eyes.Cascade
+		if cascadePath == "" {
+			cascadePath = defaultEyeCascade
+		}
+		eyes, err = newEyeAnalyzer(resource.ResolvePath(cascadePath), cfg.Eyes.ClosedThreshold, cfg.Eyes.GazeThreshold)
+		if err != nil {
+			closeAll(detectors)
+			emb.Close()
+			return fmt.Errorf("目の推定の初期化に失敗: %w", err)
+		}
+	}
+
+	var smile *gocv.CascadeClassifier
+	if cfg.Explain.Enabled {
+		cascadePath := cfg.Explain.SmileCascade
+		if cascadePath == "" {
+			cascadePath = defaultSmileCascade
+		}
+		smile, err = newSmileCascade(resource.ResolvePath(cascadePath))
+		if err != nil {
+			closeAll(detectors)
+			emb.Close()
+			eyes.Close()
+			return fmt.Errorf("判定根拠の出力の初期化に失敗: %w", err)
+		}
+	}
+
+	fa.closeDetectors()
+	fa.detectors = detectors
+	if err := fa.preprocess.Close(); err != nil {
+		log.Printf("前処理のクローズに失敗: %v", err)
+	}
+	fa.preprocess = newPreprocessor(cfg.Preprocess)
+	fa.nmsThreshold = defaultNMSThreshold
+	if cfg.Detection.NMSThreshold > 0 {
+		fa.nmsThreshold = cfg.Detection.NMSThreshold
+	}
+	fa.emotionParams = cfg.Emotion.WithDefaults()
+	fa.primaryPolicy = PrimaryLargest
+	if cfg.PrimaryFace.Policy != "" {
+		fa.primaryPolicy = cfg.PrimaryFace.Policy
+	}
+	fa.tracker = NewTracker(cfg.Tracking.IoUThreshold, cfg.Tracking.MaxMissed, cfg.Tracking.SessionTTL, cfg.Tracking.MaxSessions)
+	if err := fa.embedder.Close(); err != nil {
+		log.Printf("埋め込みモデルのクローズに失敗: %v", err)
+	}
+	fa.embedder = emb
+	if err := fa.eyes.Close(); err != nil {
+		log.Printf("目のカスケード分類器のクローズに失敗: %v", err)
+	}
+	fa.eyes = eyes
+	fa.eyePenalty = cfg.Eyes.ClosedConfidencePenalty
+	fa.occlusion = newOcclusionDetector(cfg.Occlusion)
+	fa.occlusionUnknown = cfg.Occlusion.UnknownEmotion
+	fa.occlusionPenalty = cfg.Occlusion.ConfidencePenalty
+	fa.pipeline = pipeline
+	fa.closeSmileCascade()
+	fa.smile = smile
+	fa.roi = cfg.ROI
+	fa.crowd = newCrowdDetector(cfg.Crowd)
+	fa.labels = labels
+	return nil
+}
+
+func (fa *FaceAnalyzer) closeSmileCascade() {
+	if fa.smile == nil {
+		return
+	}
+	if err := fa.smile.Close(); err != nil {
+		log.Printf("笑顔のカスケード分類器のクローズに失敗: %v", err)
+	}
+	fa.smile = nil
+}
+
+// パイプラインの段階ごとの処理時間の通知先を設定
+// リクエストの処理を開始する前に呼び出すこと
+func (fa *FaceAnalyzer) SetStageObserver(observer func(stage string, d time.Duration)) {
+	fa.stageObserver = observer
+}
+
+// 構成済みのパイプラインの段階名を返す
+func (fa *FaceAnalyzer) StageNames() []string {
+	names := make([]string, len(fa.pipeline))
+	for i, s := range fa.pipeline {
+		names[i] = s.Name()
+	}
+	return names
+}
+
+// 設定に従って顔検出器を構成
+func (fa *FaceAnalyzer) buildDetectors(cfg config.DetectionConfig) ([]Detector, error) {
+	if len(cfg.Detectors) == 0 {
+		return fa.defaultDetectors(), nil
+	}
+
+	profilePath := cfg.ProfileCascade
+	if profilePath == "" {
+		profilePath = resource.ResolvePath(defaultProfileCascade)
+	}
+	minConfidence := cfg.DNNConfidence
+	if minConfidence == 0 {
+		minConfidence = defaultDNNConfidence
+	}
+
+	detectors := make([]Detector, 0, len(cfg.Detectors))
+	for _, name := range cfg.Detectors {
+		var (
+			d   Detector
+			err error
+		)
+		switch name {
+		case DetectorFrontal:
+			d = &cascadeDetector{name: DetectorFrontal, cascade: &fa.cascade, score: frontalCascadeScore}
+		case DetectorProfile:
+			d, err = newCascadeDetector(DetectorProfile, profilePath, profileCascadeScore, false)
+		case DetectorProfileFlipped:
+			d, err = newCascadeDetector(DetectorProfileFlipped, profilePath, profileCascadeScore, true)
+		case DetectorDNN:
+			d, err = newDNNDetector(cfg.DNNProto, cfg.DNNModel, minConfidence)
+		}
+		if err != nil {
+			closeAll(detectors)
+			return nil, fmt.Errorf("顔検出器 %s の初期化に失敗: %w", name, err)
+		}
+		detectors = append(detectors, d)
+	}
+	return detectors, nil
+}
+
+// 構成済みの検出器名を返す
+func (fa *FaceAnalyzer) DetectorNames() []string {
+	names := make([]string, len(fa.detectors))
+	for i, d := range fa.detectors {
+		names[i] = d.Name()
+	}
+	return names
+}
+
+// アナライザーが保持するリソースを解放
+// コンストラクタで渡されたカスケード分類器は呼び出し元が解放する
+func (fa *FaceAnalyzer) Close() error {
+	fa.closeDetectors()
+	if err := fa.preprocess.Close(); err != nil {
+		log.Printf("前処理のクローズに失敗: %v", err)
+	}
+	fa.preprocess = nil
+	if err := fa.embedder.Close(); err != nil {
+		log.Printf("埋め込みモデルのクローズに失敗: %v", err)
+	}
+	fa.embedder = nil
+	if err := fa.eyes.Close(); err != nil {
+		log.Printf("目のカスケード分類器のクローズに失敗: %v", err)
+	}
+	fa.eyes = nil
+	fa.closeSmileCascade()
+	if fa.hasNet {
+		fa.hasNet = false
+		return fa.net.Close()
+	}
+	return nil
+}
+
+func (fa *FaceAnalyzer) closeDetectors() {
+	closeAll(fa.detectors)
+	fa.detectors = nil
+}
+
+func closeAll(detectors []Detector) {
+	for _, d := range detectors {
+		if err := d.Close(); err != nil {
+			log.Printf("顔検出器 %s のクローズに失敗: %v", d.Name(), err)
+		}
+	}
+}
+
+// 現在の感情分類パラメータを返す
+func (fa *FaceAnalyzer) EmotionParams() config.EmotionConfig {
+	return fa.emotionParams
 }
 
 // Analyze は画像から顔を検出し、感情を分析します
 func (fa *FaceAnalyzer) Analyze(imgData []byte) (*AnalysisResult, error) {
+	return fa.AnalyzeWithOptions(imgData, AnalyzeOptions{})
+}
+
+// AnalyzeWithOptions はリクエストごとのオプションを指定して画像を分析します
+func (fa *FaceAnalyzer) AnalyzeWithOptions(imgData []byte, opts AnalyzeOptions) (*AnalysisResult, error) {
 	// 入力データのチェック
 	if len(imgData) == 0 {
-		return nil, fmt.Errorf("画像データが空です")
+		return nil, errors.CodeError(errors.ErrCodeInvalidImage, "画像データが空です", nil)
 	}
-
-	// 画像データをMatに変換
-	img, err := gocv.IMDecode(imgData, gocv.IMReadColor)
-	if err != nil {
-		return nil, fmt.Errorf("画像のデコードに失敗: %v", err)
+	policy := opts.PrimaryPolicy
+	if policy == "" {
+		policy = fa.primaryPolicy
+	}
+	if !config.IsValidPrimaryFacePolicy(policy) {
+		return nil, errors.CodeError(errors.ErrCodeInvalidInput, fmt.Sprintf("不正な主要な顔の選択ポリシーです: %s", policy), nil)
+	}
+	roi := fa.roi
+	if opts.ROI != nil {
+		if err := opts.ROI.Validate(); err != nil {
+			return nil, errors.CodeError(errors.ErrCodeInvalidInput, "分析対象領域が不正です", err)
+		}
+		roi = *opts.ROI
+	}
+	if opts.Crowd && fa.crowd == nil {
+		return nil, errors.CodeError(errors.ErrCodeForbidden, "群衆モードが無効です", nil)
+	}
+	if opts.Locale != "" && !config.IsValidAnnotationLocale(opts.Locale) {
+		return nil, errors.CodeError(errors.ErrCodeInvalidInput, fmt.Sprintf("不正なラベルの言語です: %s", opts.Locale), nil)
 	}
-	defer img.Close()
 
-	// 画像が正しく読み込まれたかチェック
-	if img.Empty() {
-		return nil, fmt.Errorf("無効な画像データです")
-	}
-
-	// グレースケールに変換（顔検出用）
-	gray := gocv.NewMat()
-	defer gray.Close()
-	gocv.CvtColor(img, &gray, gocv.ColorBGRToGray)
-
-	// 顔の検出
-	minSize := image.Point{X: gray.Cols() / 8, Y: gray.Rows() / 8}
-	maxSize := image.Point{X: gray.Cols() * 3 / 4, Y: gray.Rows() * 3 / 4}
-
-	detected := fa.cascade.DetectMultiScaleWithParams(
-		gray,
-		1.1,
-		3,
-		0,
-		minSize,
-		maxSize,
-	)
-
-	// 結果の準備
-	result := AnalysisResult{
-		Faces:          make([]Face, len(detected)),
-		PrimaryEmotion: EmotionUnknown,
-		Confidence:     0.0,
-	}
-
-	// 処理結果を保存するための新しい画像を作成
-	outputImg := img.Clone()
-	defer outputImg.Close()
-
-	// 各顔に対して処理
-	for i, rect := range detected {
-		// 顔領域の感情分析
-		emotion := fa.analyzeEmotion(gray, Face{
-			X:      float64(rect.Min.X),
-			Y:      float64(rect.Min.Y),
-			Width:  float64(rect.Dx()),
-			Height: float64(rect.Dy()),
-		})
+	c := newAnalysisContext(imgData, opts, policy)
+	c.roi = roi
+	defer c.Close()
+	if err := fa.runPipeline(fa.pipeline, c); err != nil {
+		return nil, err
+	}
+	return c.result, nil
+}
 
-		result.Faces[i] = Face{
-			X:      float64(rect.Min.X),
-			Y:      float64(rect.Min.Y),
-			Width:  float64(rect.Dx()),
-			Height: float64(rect.Dy()),
-		}
+// ExtractEmotionFeatures は画像から検出した各顔の感情分類用特徴量を返します
+// 閾値のチューニングなど、分類を繰り返し行う用途で使用します
+func (fa *FaceAnalyzer) ExtractEmotionFeatures(imgData []byte) ([]EmotionFeatures, error) {
+	if len(imgData) == 0 {
+		return nil, errors.CodeError(errors.ErrCodeInvalidImage, "画像データが空です", nil)
+	}
 
-		// 最初の顔を主要な感情として設定
-		if i == 0 {
-			result.PrimaryEmotion = emotion
-			result.Confidence = 0.9 // TODO: 実際のスコアを計算
+	// 検出までの段階のみ実行する
+	var stages []Stage
+	for _, s := range fa.pipeline {
+		stages = append(stages, s)
+		if s.Name() == StageDetect {
+			break
 		}
+	}
 
-		// 顔の周りに緑の矩形を描画
-		gocv.Rectangle(&outputImg, rect, color.RGBA{0, 255, 0, 255}, 3)
+	c := newAnalysisContext(imgData, AnalyzeOptions{}, fa.primaryPolicy)
+	c.roi = fa.roi
+	defer c.Close()
+	if err := fa.runPipeline(stages, c); err != nil {
+		return nil, err
+	}
 
-		// テキストの描画位置を計算
-		textPoint := image.Point{
-			X: rect.Min.X,
-			Y: rect.Min.Y - 10,
+	features := make([]EmotionFeatures, 0, len(c.detected))
+	for _, detection := range c.detected {
+		f, ok := extractEmotionFeatures(c.gray, faceFromDetection(detection), !fa.preprocess.equalizesLocally())
+		if !ok {
+			continue
 		}
-		// 感情を画像に描画
-		gocv.PutText(&outputImg, string(emotion), textPoint, gocv.FontHersheyPlain, 1.2, color.RGBA{0, 255, 0, 255}, 2)
+		features = append(features, f)
 	}
+	return features, nil
+}
 
-	// 処理済みの画像をエンコード
-	buf, err := gocv.IMEncode(".jpg", outputImg)
+// 画像データをデコード
+func decodeImage(imgData []byte) (gocv.Mat, error) {
+	img, err := gocv.IMDecode(imgData, gocv.IMReadColor)
 	if err != nil {
-		return nil, fmt.Errorf("画像のエンコードに失敗: %v", err)
+		return img, errors.CodeError(errors.ErrCodeInvalidImage, "画像のデコードに失敗", err)
+	}
+
+	// 画像が正しく読み込まれたかチェック
+	if img.Empty() {
+		img.Close()
+		return img, errors.CodeError(errors.ErrCodeInvalidImage, "無効な画像データです", nil)
+	}
+	return img, nil
+}
+
+// 構成された全ての検出器で顔を検出し、重複をNMSで統合
+func (fa *FaceAnalyzer) detectFaces(img, gray gocv.Mat) []Detection {
+	var detections []Detection
+	for _, d := range fa.detectors {
+		detections = append(detections, d.Detect(img, gray)...)
 	}
-	result.ProcessedImageData = buf.GetBytes()
+	return nonMaxSuppression(detections, fa.nmsThreshold)
+}
 
-	return &result, nil
+// 検出結果から顔の領域を作成
+func faceFromDetection(d Detection) Face {
+	return Face{
+		X:              float64(d.Rect.Min.X),
+		Y:              float64(d.Rect.Min.Y),
+		Width:          float64(d.Rect.Dx()),
+		Height:         float64(d.Rect.Dy()),
+		Detector:       d.Detector,
+		DetectionScore: d.Score,
+	}
 }
 
 // 顔画像から感情を分析
 func (fa *FaceAnalyzer) analyzeEmotion(img gocv.Mat, face Face) Emotion {
+	features, ok := extractEmotionFeatures(img, face, !fa.preprocess.equalizesLocally())
+	if !ok {
+		return EmotionUnknown
+	}
+	return ClassifyEmotion(features, fa.emotionParams)
+}
+
+// 顔領域から感情分類用の特徴量を抽出
+// 前処理でCLAHEを適用済みの場合はequalizeをfalseにして顔領域全体の平坦化を省略する
+func extractEmotionFeatures(img gocv.Mat, face Face, equalize bool) (EmotionFeatures, bool) {
 	// 画像サイズを取得
 	width := img.Cols()
 	height := img.Rows()
@@ -185,7 +549,7 @@ func (fa *FaceAnalyzer) analyzeEmotion(img gocv.Mat, face Face) Emotion {
 
 	// 有効な領域サイズをチェック
 	if w <= 0 || h <= 0 {
-		return EmotionUnknown
+		return EmotionFeatures{}, false
 	}
 
 	// 顔領域を切り出し
@@ -195,7 +559,11 @@ func (fa *FaceAnalyzer) analyzeEmotion(img gocv.Mat, face Face) Emotion {
 	// ヒストグラム平坦化
 	equalized := gocv.NewMat()
 	defer equalized.Close()
-	gocv.EqualizeHist(roi, &equalized)
+	if equalize {
+		gocv.EqualizeHist(roi, &equalized)
+	} else {
+		roi.CopyTo(&equalized)
+	}
 
 	// 平均輝度を計算
 	mean := gocv.NewMat()
@@ -204,22 +572,56 @@ func (fa *FaceAnalyzer) analyzeEmotion(img gocv.Mat, face Face) Emotion {
 	defer stddev.Close()
 	gocv.MeanStdDev(equalized, &mean, &stddev)
 
-	brightness := mean.GetDoubleAt(0, 0)
-	variation := stddev.GetDoubleAt(0, 0)
+	return EmotionFeatures{
+		Brightness: mean.GetDoubleAt(0, 0),
+		Variation:  stddev.GetDoubleAt(0, 0),
+	}, true
+}
+
+// 顔領域の鮮明度とサイズから品質スコアを計算
+func faceQuality(img gocv.Mat, rect image.Rectangle) float64 {
+	rect = rect.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+	if rect.Empty() {
+		return 0
+	}
+	return qualityScore(laplacianVariance(img, rect), float64(rect.Dx()), float64(rect.Dy()))
+}
+
+// 領域のラプラシアンの分散（鮮明度の指標）を計算
+func laplacianVariance(img gocv.Mat, rect image.Rectangle) float64 {
+	roi := img.Region(rect)
+	defer roi.Close()
+
+	laplacian := gocv.NewMat()
+	defer laplacian.Close()
+	gocv.Laplacian(roi, &laplacian, gocv.MatTypeCV64F, 1, 1, 0, gocv.BorderDefault)
+
+	mean := gocv.NewMat()
+	stddev := gocv.NewMat()
+	defer mean.Close()
+	defer stddev.Close()
+	gocv.MeanStdDev(laplacian, &mean, &stddev)
+
+	sd := stddev.GetDoubleAt(0, 0)
+	return sd * sd
+}
+
+// 輝度と変動に基づいて感情を判定
+func ClassifyEmotion(features EmotionFeatures, params config.EmotionConfig) Emotion {
+	bright := features.Brightness > params.BrightnessThreshold
 
-	// 輝度と変動に基づいて感情を判定
 	switch {
-	case variation > 80:
+	case features.Variation > params.SurpriseVariation:
 		return EmotionSurprise
-	case variation > 65:
+	case features.Variation > params.HappyVariation:
 		return EmotionHappy
-	case variation > 50:
-		if brightness > 140 {
+	case features.Variation > params.MidVariation:
+		if bright {
 			return EmotionHappy
 		}
 		return EmotionSad
-	case variation > 35:
-		if brightness > 140 {
+	case features.Variation > params.LowVariation:
+		if bright {
 			return EmotionNeutral
 		}
 		return EmotionAngry
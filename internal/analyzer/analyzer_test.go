@@ -5,6 +5,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/okamyuji/face-emotion-analyzer/config"
 	"github.com/okamyuji/face-emotion-analyzer/internal/resource"
 	"gocv.io/x/gocv"
 )
@@ -169,3 +170,61 @@ func BenchmarkAnalyzer_Analyze(b *testing.B) {
 		}
 	}
 }
+
+func TestClassifyEmotion(t *testing.T) {
+	params := config.DefaultEmotionConfig()
+
+	tests := []struct {
+		name     string
+		features EmotionFeatures
+		want     Emotion
+	}{
+		{"変動が非常に大きい", EmotionFeatures{Brightness: 100, Variation: 85}, EmotionSurprise},
+		{"変動が大きい", EmotionFeatures{Brightness: 100, Variation: 70}, EmotionHappy},
+		{"中程度の変動で明るい", EmotionFeatures{Brightness: 150, Variation: 55}, EmotionHappy},
+		{"中程度の変動で暗い", EmotionFeatures{Brightness: 120, Variation: 55}, EmotionSad},
+		{"小さい変動で明るい", EmotionFeatures{Brightness: 150, Variation: 40}, EmotionNeutral},
+		{"小さい変動で暗い", EmotionFeatures{Brightness: 120, Variation: 40}, EmotionAngry},
+		{"変動がほとんどない", EmotionFeatures{Brightness: 120, Variation: 10}, EmotionNeutral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyEmotion(tt.features, params); got != tt.want {
+				t.Errorf("ClassifyEmotion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFaceAnalyzer_Configure(t *testing.T) {
+	fa := &FaceAnalyzer{emotionParams: config.DefaultEmotionConfig()}
+
+	custom := config.EmotionConfig{
+		SurpriseVariation:   90,
+		HappyVariation:      70,
+		MidVariation:        45,
+		LowVariation:        30,
+		BrightnessThreshold: 130,
+	}
+	if err := fa.Configure(config.AnalyzerConfig{Emotion: custom}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if fa.EmotionParams() != custom {
+		t.Errorf("EmotionParams() = %v, want %v", fa.EmotionParams(), custom)
+	}
+
+	// 未設定の場合はデフォルト値を使用
+	if err := fa.Configure(config.AnalyzerConfig{}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if fa.EmotionParams() != config.DefaultEmotionConfig() {
+		t.Errorf("EmotionParams() = %v, want defaults", fa.EmotionParams())
+	}
+
+	// 大小関係が不正な閾値はエラー
+	custom.HappyVariation = 95
+	if err := fa.Configure(config.AnalyzerConfig{Emotion: custom}); err == nil {
+		t.Error("Configure() expected error for unordered thresholds")
+	}
+}
@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"image"
+	"runtime"
+	"sync"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"gocv.io/x/gocv"
+)
+
+// 群衆モードのデフォルト設定
+const (
+	defaultCrowdTileSize = 512
+	defaultCrowdOverlap  = 0.25
+	// タイルの境界からこの距離以内の検出結果は顔の一部しか写っていない可能性がある
+	tileEdgeMargin = 2
+	// 境界に接する検出結果の面積のうち、この割合以上が他の検出結果と重なれば同じ顔とみなす
+	partialOverlapThreshold = 0.5
+)
+
+// 群衆全体の集計
+type CrowdSummary struct {
+	FaceCount int
+	// 感情ごとの顔の数と割合
+	Emotions     map[Emotion]int
+	Distribution map[Emotion]float64
+	// 検出に使用したタイルの数
+	Tiles int
+}
+
+// 画像を重なりのあるタイルに分割して並列に顔を検出する
+// 各検出器の最小サイズは入力画像の大きさに比例するため、タイルに分割することで小さな顔も検出できる
+type crowdDetector struct {
+	tileSize int
+	overlap  float64
+	workers  int
+}
+
+// 群衆モードの検出器を作成
+// 無効な場合は nil を返す
+func newCrowdDetector(cfg config.CrowdConfig) *crowdDetector {
+	if !cfg.Enabled {
+		return nil
+	}
+	d := &crowdDetector{
+		tileSize: cfg.TileSize,
+		overlap:  cfg.Overlap,
+		workers:  cfg.Workers,
+	}
+	if d.tileSize == 0 {
+		d.tileSize = defaultCrowdTileSize
+	}
+	if d.overlap == 0 {
+		d.overlap = defaultCrowdOverlap
+	}
+	if d.workers == 0 {
+		d.workers = runtime.NumCPU()
+	}
+	return d
+}
+
+// タイルごとに detect で顔を検出し、タイルの境界をまたぐ検出結果を統合する
+// 大きな顔を検出するため画像全体でも検出する。戻り値の2つ目は使用したタイルの数
+func (d *crowdDetector) Detect(img, gray gocv.Mat, detect func(img, gray gocv.Mat) []Detection, nmsThreshold float64) ([]Detection, int) {
+	bounds := image.Rect(0, 0, img.Cols(), img.Rows())
+	tiles := crowdTiles(bounds.Dx(), bounds.Dy(), d.tileSize, d.overlap)
+	if len(tiles) == 1 {
+		return detect(img, gray), 1
+	}
+	regions := append(tiles, bounds)
+
+	perRegion := make([][]Detection, len(regions))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(d.workers, len(regions)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				tileImg := img.Region(regions[i])
+				tileGray := gray.Region(regions[i])
+				perRegion[i] = detect(tileImg, tileGray)
+				tileImg.Close()
+				tileGray.Close()
+			}
+		}()
+	}
+	for i := range regions {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return mergeTileDetections(regions, perRegion, bounds, nmsThreshold), len(tiles)
+}
+
+// 画像を一辺 tileSize の重なりのあるタイルに分割
+// 最後のタイルは画像の端に揃え、画像がタイルより小さい場合は画像全体を1つのタイルとする
+func crowdTiles(width, height, tileSize int, overlap float64) []image.Rectangle {
+	step := max(1, int(float64(tileSize)*(1-overlap)))
+	xs := tileStarts(width, tileSize, step)
+	ys := tileStarts(height, tileSize, step)
+
+	tiles := make([]image.Rectangle, 0, len(xs)*len(ys))
+	for _, y := range ys {
+		for _, x := range xs {
+			tiles = append(tiles, image.Rect(x, y, min(x+tileSize, width), min(y+tileSize, height)))
+		}
+	}
+	return tiles
+}
+
+func tileStarts(length, size, step int) []int {
+	if length <= size {
+		return []int{0}
+	}
+	var starts []int
+	for s := 0; s+size < length; s += step {
+		starts = append(starts, s)
+	}
+	return append(starts, length-size)
+}
+
+// タイルごとの検出結果を画像全体の座標に戻して統合
+// タイルの内側の境界に接する検出結果は顔の一部の可能性があるため、他の検出結果と重なる場合は除外する
+func mergeTileDetections(tiles []image.Rectangle, perTile [][]Detection, bounds image.Rectangle, nmsThreshold float64) []Detection {
+	var whole, partial []Detection
+	for i, tile := range tiles {
+		for _, d := range perTile[i] {
+			d.Rect = d.Rect.Add(tile.Min)
+			if touchesInnerEdge(d.Rect, tile, bounds) {
+				partial = append(partial, d)
+			} else {
+				whole = append(whole, d)
+			}
+		}
+	}
+
+	merged := nonMaxSuppression(whole, nmsThreshold)
+	for _, p := range nonMaxSuppression(partial, nmsThreshold) {
+		if !overlapsAny(p.Rect, merged) {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+// 矩形がタイルの境界のうち画像の端ではない境界に接しているかを返す
+func touchesInnerEdge(rect, tile, bounds image.Rectangle) bool {
+	return (tile.Min.X > bounds.Min.X && rect.Min.X-tile.Min.X <= tileEdgeMargin) ||
+		(tile.Min.Y > bounds.Min.Y && rect.Min.Y-tile.Min.Y <= tileEdgeMargin) ||
+		(tile.Max.X < bounds.Max.X && tile.Max.X-rect.Max.X <= tileEdgeMargin) ||
+		(tile.Max.Y < bounds.Max.Y && tile.Max.Y-rect.Max.Y <= tileEdgeMargin)
+}
+
+// 矩形の面積の一定以上が検出結果のいずれかと重なるかを返す
+func overlapsAny(rect image.Rectangle, detections []Detection) bool {
+	area := rect.Dx() * rect.Dy()
+	if area <= 0 {
+		return false
+	}
+	for _, d := range detections {
+		inter := rect.Intersect(d.Rect)
+		if float64(inter.Dx()*inter.Dy())/float64(area) >= partialOverlapThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// 検出された全ての顔の数と感情の分布を集計
+func crowdSummary(faces []Face, tiles int) *CrowdSummary {
+	summary := &CrowdSummary{
+		FaceCount:    len(faces),
+		Emotions:     make(map[Emotion]int),
+		Distribution: make(map[Emotion]float64),
+		Tiles:        tiles,
+	}
+	for _, f := range faces {
+		summary.Emotions[f.Emotion]++
+	}
+	for emotion, count := range summary.Emotions {
+		summary.Distribution[emotion] = float64(count) / float64(len(faces))
+	}
+	return summary
+}
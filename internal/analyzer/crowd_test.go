@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrowdTiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		wantCount     int
+		wantFirst     image.Rectangle
+		wantLast      image.Rectangle
+	}{
+		{
+			name:      "タイルより小さい画像",
+			width:     400,
+			height:    300,
+			wantCount: 1,
+			wantFirst: image.Rect(0, 0, 400, 300),
+			wantLast:  image.Rect(0, 0, 400, 300),
+		},
+		{
+			name:      "横長の画像",
+			width:     1000,
+			height:    600,
+			wantCount: 6,
+			wantFirst: image.Rect(0, 0, 512, 512),
+			wantLast:  image.Rect(488, 88, 1000, 600),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiles := crowdTiles(tt.width, tt.height, 512, 0.25)
+			require.Len(t, tiles, tt.wantCount)
+			assert.Equal(t, tt.wantFirst, tiles[0])
+			assert.Equal(t, tt.wantLast, tiles[len(tiles)-1])
+
+			// 全てのタイルが画像内に収まり、隣接するタイルと重なる
+			bounds := image.Rect(0, 0, tt.width, tt.height)
+			for i, tile := range tiles {
+				assert.True(t, tile.In(bounds))
+				if i > 0 && tiles[i-1].Min.Y == tile.Min.Y {
+					assert.False(t, tiles[i-1].Intersect(tile).Empty())
+				}
+			}
+		})
+	}
+}
+
+func TestMergeTileDetections(t *testing.T) {
+	bounds := image.Rect(0, 0, 1000, 600)
+	tiles := []image.Rectangle{image.Rect(0, 0, 512, 512), image.Rect(384, 0, 896, 512)}
+	perTile := [][]Detection{
+		{
+			// 両方のタイルに写っている顔
+			{Rect: image.Rect(400, 100, 460, 160), Score: 0.8},
+			// 右の境界で切れている顔
+			{Rect: image.Rect(480, 300, 512, 360), Score: 0.8},
+		},
+		{
+			{Rect: image.Rect(16, 100, 76, 160), Score: 0.8},
+			// 左のタイルで切れていた顔の全体
+			{Rect: image.Rect(96, 300, 156, 360), Score: 0.8},
+			// 右の境界で切れているが、他のタイルに写っていない顔
+			{Rect: image.Rect(486, 400, 512, 440), Score: 0.8},
+		},
+	}
+
+	merged := mergeTileDetections(tiles, perTile, bounds, 0.3)
+
+	rects := make([]image.Rectangle, len(merged))
+	for i, d := range merged {
+		rects[i] = d.Rect
+	}
+	assert.ElementsMatch(t, []image.Rectangle{
+		image.Rect(400, 100, 460, 160),
+		image.Rect(480, 300, 540, 360),
+		image.Rect(870, 400, 896, 440),
+	}, rects)
+}
+
+func TestTouchesInnerEdge(t *testing.T) {
+	bounds := image.Rect(0, 0, 1000, 600)
+	tile := image.Rect(0, 0, 512, 512)
+
+	assert.False(t, touchesInnerEdge(image.Rect(0, 0, 60, 60), tile, bounds), "画像の端に接する顔")
+	assert.True(t, touchesInnerEdge(image.Rect(460, 100, 511, 160), tile, bounds), "タイルの右の境界に接する顔")
+	assert.True(t, touchesInnerEdge(image.Rect(100, 460, 160, 512), tile, bounds), "タイルの下の境界に接する顔")
+	assert.False(t, touchesInnerEdge(image.Rect(100, 100, 160, 160), tile, bounds), "タイルの内側の顔")
+}
+
+func TestCrowdSummary(t *testing.T) {
+	faces := []Face{
+		{Emotion: EmotionHappy},
+		{Emotion: EmotionHappy},
+		{Emotion: EmotionHappy},
+		{Emotion: EmotionNeutral},
+	}
+
+	summary := crowdSummary(faces, 6)
+	assert.Equal(t, 4, summary.FaceCount)
+	assert.Equal(t, 6, summary.Tiles)
+	assert.Equal(t, map[Emotion]int{EmotionHappy: 3, EmotionNeutral: 1}, summary.Emotions)
+	assert.InDelta(t, 0.75, summary.Distribution[EmotionHappy], 1e-9)
+	assert.InDelta(t, 0.25, summary.Distribution[EmotionNeutral], 1e-9)
+
+	empty := crowdSummary(nil, 1)
+	assert.Equal(t, 0, empty.FaceCount)
+	assert.Empty(t, empty.Distribution)
+}
@@ -0,0 +1,228 @@
+package analyzer
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// 顔検出器の名前
+const (
+	DetectorFrontal        = "frontal"
+	DetectorProfile        = "profile"
+	DetectorProfileFlipped = "profile_flipped"
+	DetectorDNN            = "dnn"
+)
+
+// カスケード分類器はスコアを返さないため、検出器ごとの固定スコアをNMSの優先度として使用する
+const (
+	frontalCascadeScore = 0.8
+	profileCascadeScore = 0.6
+)
+
+// 1つの検出器による顔の検出結果
+type Detection struct {
+	Rect     image.Rectangle
+	Score    float64
+	Detector string
+}
+
+// 顔検出器のインターフェース
+type Detector interface {
+	Name() string
+	Detect(img, gray gocv.Mat) []Detection
+	Close() error
+}
+
+// カスケード分類器による顔検出器
+// flippedがtrueの場合は左右反転した画像で検出し、座標を元の画像に戻す
+type cascadeDetector struct {
+	name    string
+	cascade *gocv.CascadeClassifier
+	score   float64
+	flipped bool
+	owned   bool
+}
+
+// カスケードファイルを読み込んで検出器を作成
+func newCascadeDetector(name, path string, score float64, flipped bool) (*cascadeDetector, error) {
+	cascade := gocv.NewCascadeClassifier()
+	if !cascade.Load(path) {
+		cascade.Close()
+		return nil, fmt.Errorf("カスケード分類器の読み込みに失敗: %s", path)
+	}
+	return &cascadeDetector{
+		name:    name,
+		cascade: &cascade,
+		score:   score,
+		flipped: flipped,
+		owned:   true,
+	}, nil
+}
+
+func (d *cascadeDetector) Name() string {
+	return d.name
+}
+
+func (d *cascadeDetector) Detect(img, gray gocv.Mat) []Detection {
+	src := gray
+	if d.flipped {
+		flippedGray := gocv.NewMat()
+		defer flippedGray.Close()
+		gocv.Flip(gray, &flippedGray, 1)
+		src = flippedGray
+	}
+
+	minSize := image.Point{X: src.Cols() / 8, Y: src.Rows() / 8}
+	maxSize := image.Point{X: src.Cols() * 3 / 4, Y: src.Rows() * 3 / 4}
+	rects := d.cascade.DetectMultiScaleWithParams(src, 1.1, 3, 0, minSize, maxSize)
+
+	detections := make([]Detection, 0, len(rects))
+	for _, rect := range rects {
+		if d.flipped {
+			rect = mirrorRect(rect, src.Cols())
+		}
+		detections = append(detections, Detection{
+			Rect:     rect,
+			Score:    d.score,
+			Detector: d.name,
+		})
+	}
+	return detections
+}
+
+func (d *cascadeDetector) Close() error {
+	if !d.owned || d.cascade == nil {
+		return nil
+	}
+	err := d.cascade.Close()
+	d.cascade = nil
+	return err
+}
+
+// Caffe SSDモデルによる顔検出器
+// gocv.Netは並行実行に対応していないため推論はロックして行う
+type dnnDetector struct {
+	mu            sync.Mutex
+	net           *gocv.Net
+	minConfidence float64
+	owned         bool
+}
+
+// モデルを読み込んでDNN検出器を作成
+func newDNNDetector(protoPath, modelPath string, minConfidence float64) (*dnnDetector, error) {
+	net := gocv.ReadNetFromCaffe(protoPath, modelPath)
+	if net.Empty() {
+		net.Close()
+		return nil, fmt.Errorf("DNNモデルの読み込みに失敗: %s", modelPath)
+	}
+	return &dnnDetector{
+		net:           &net,
+		minConfidence: minConfidence,
+		owned:         true,
+	}, nil
+}
+
+func (d *dnnDetector) Name() string {
+	return DetectorDNN
+}
+
+func (d *dnnDetector) Detect(img, gray gocv.Mat) []Detection {
+	blob := gocv.BlobFromImage(img, 1.0, image.Pt(300, 300), gocv.NewScalar(104, 177, 123, 0), false, false)
+	defer blob.Close()
+
+	d.mu.Lock()
+	d.net.SetInput(blob, "")
+	prob := d.net.Forward("")
+	d.mu.Unlock()
+	defer prob.Close()
+
+	// 出力は [1, 1, N, 7] の形式（image_id, label, confidence, left, top, right, bottom）
+	results := gocv.GetBlobChannel(prob, 0, 0)
+	defer results.Close()
+
+	bounds := image.Rect(0, 0, img.Cols(), img.Rows())
+	var detections []Detection
+	for r := 0; r < results.Rows(); r++ {
+		confidence := float64(results.GetFloatAt(r, 2))
+		if confidence < d.minConfidence {
+			continue
+		}
+
+		rect := image.Rect(
+			int(results.GetFloatAt(r, 3)*float32(img.Cols())),
+			int(results.GetFloatAt(r, 4)*float32(img.Rows())),
+			int(results.GetFloatAt(r, 5)*float32(img.Cols())),
+			int(results.GetFloatAt(r, 6)*float32(img.Rows())),
+		).Intersect(bounds)
+		if rect.Empty() {
+			continue
+		}
+
+		detections = append(detections, Detection{
+			Rect:     rect,
+			Score:    confidence,
+			Detector: DetectorDNN,
+		})
+	}
+	return detections
+}
+
+func (d *dnnDetector) Close() error {
+	if !d.owned || d.net == nil {
+		return nil
+	}
+	err := d.net.Close()
+	d.net = nil
+	return err
+}
+
+// 左右反転した画像上の矩形を元の画像の座標に戻す
+func mirrorRect(rect image.Rectangle, width int) image.Rectangle {
+	return image.Rect(width-rect.Max.X, rect.Min.Y, width-rect.Min.X, rect.Max.Y)
+}
+
+// 2つの矩形のIoU（Intersection over Union）を計算
+func iou(a, b image.Rectangle) float64 {
+	inter := a.Intersect(b)
+	if inter.Empty() {
+		return 0
+	}
+	interArea := float64(inter.Dx() * inter.Dy())
+	union := float64(a.Dx()*a.Dy()+b.Dx()*b.Dy()) - interArea
+	if union <= 0 {
+		return 0
+	}
+	return interArea / union
+}
+
+// IoUに基づくNon-Maximum Suppressionで重複した検出結果を統合
+// スコアの高い検出を優先し、同点の場合は面積の大きい検出を残す
+func nonMaxSuppression(detections []Detection, threshold float64) []Detection {
+	sorted := make([]Detection, len(detections))
+	copy(sorted, detections)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Score != sorted[j].Score {
+			return sorted[i].Score > sorted[j].Score
+		}
+		return sorted[i].Rect.Dx()*sorted[i].Rect.Dy() > sorted[j].Rect.Dx()*sorted[j].Rect.Dy()
+	})
+
+	kept := make([]Detection, 0, len(sorted))
+	for _, candidate := range sorted {
+		suppressed := false
+		for _, k := range kept {
+			if iou(candidate.Rect, k.Rect) > threshold {
+				suppressed = true
+				break
+			}
+		}
+		if !suppressed {
+			kept = append(kept, candidate)
+		}
+	}
+	return kept
+}
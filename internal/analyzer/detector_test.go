@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIoU(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b image.Rectangle
+		want float64
+	}{
+		{"同一の矩形", image.Rect(0, 0, 10, 10), image.Rect(0, 0, 10, 10), 1},
+		{"重なりなし", image.Rect(0, 0, 10, 10), image.Rect(20, 20, 30, 30), 0},
+		{"半分の重なり", image.Rect(0, 0, 10, 10), image.Rect(5, 0, 15, 10), 50.0 / 150.0},
+		{"内包", image.Rect(0, 0, 10, 10), image.Rect(0, 0, 5, 5), 25.0 / 100.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, iou(tt.a, tt.b), 1e-9)
+		})
+	}
+}
+
+func TestNonMaxSuppression(t *testing.T) {
+	detections := []Detection{
+		{Rect: image.Rect(0, 0, 100, 100), Score: 0.6, Detector: DetectorProfile},
+		{Rect: image.Rect(5, 5, 105, 105), Score: 0.8, Detector: DetectorFrontal},
+		{Rect: image.Rect(200, 200, 260, 260), Score: 0.6, Detector: DetectorProfileFlipped},
+		{Rect: image.Rect(2, 2, 98, 98), Score: 0.8, Detector: DetectorFrontal},
+	}
+
+	kept := nonMaxSuppression(detections, 0.3)
+	require.Len(t, kept, 2)
+
+	// スコアが同じ場合は面積の大きい検出が残る
+	assert.Equal(t, DetectorFrontal, kept[0].Detector)
+	assert.Equal(t, image.Rect(5, 5, 105, 105), kept[0].Rect)
+	assert.Equal(t, DetectorProfileFlipped, kept[1].Detector)
+
+	// 入力のスライスは変更しない
+	assert.Equal(t, DetectorProfile, detections[0].Detector)
+}
+
+func TestNonMaxSuppression_Empty(t *testing.T) {
+	assert.Empty(t, nonMaxSuppression(nil, 0.3))
+}
+
+func TestMirrorRect(t *testing.T) {
+	rect := image.Rect(10, 20, 50, 80)
+	mirrored := mirrorRect(rect, 200)
+
+	assert.Equal(t, image.Rect(150, 20, 190, 80), mirrored)
+	assert.Equal(t, rect, mirrorRect(mirrored, 200))
+}
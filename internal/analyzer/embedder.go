@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// 埋め込みモデルのデフォルトの入力サイズ（ArcFace系のモデルを想定）
+const defaultEmbeddingInputSize = 112
+
+// ONNXモデルで顔領域の埋め込みベクトルを計算する
+// gocv.Netは並行実行に対応していないため推論はロックして行う
+type embedder struct {
+	mu        sync.Mutex
+	net       gocv.Net
+	inputSize int
+}
+
+// ONNXモデルを読み込んで埋め込みの計算器を作成
+func newEmbedder(modelPath string, inputSize int) (*embedder, error) {
+	if inputSize == 0 {
+		inputSize = defaultEmbeddingInputSize
+	}
+	net := gocv.ReadNetFromONNX(modelPath)
+	if net.Empty() {
+		net.Close()
+		return nil, fmt.Errorf("埋め込みモデルの読み込みに失敗: %s", modelPath)
+	}
+	return &embedder{net: net, inputSize: inputSize}, nil
+}
+
+// 顔領域のL2正規化済みの埋め込みを計算
+// 領域が画像外の場合や推論に失敗した場合はnilを返す
+func (e *embedder) Embed(img gocv.Mat, rect image.Rectangle) []float32 {
+	rect = rect.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+	if rect.Empty() {
+		return nil
+	}
+
+	roi := img.Region(rect)
+	defer roi.Close()
+
+	// 画素値を [-1, 1] に正規化し、BGRからRGBに変換して入力する
+	blob := gocv.BlobFromImage(roi, 1.0/127.5, image.Pt(e.inputSize, e.inputSize),
+		gocv.NewScalar(127.5, 127.5, 127.5, 0), true, false)
+	defer blob.Close()
+
+	e.mu.Lock()
+	e.net.SetInput(blob, "")
+	output := e.net.Forward("")
+	e.mu.Unlock()
+	defer output.Close()
+
+	data, err := output.DataPtrFloat32()
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	embedding := append([]float32(nil), data...)
+	normalize(embedding)
+	return embedding
+}
+
+func (e *embedder) Close() error {
+	if e == nil {
+		return nil
+	}
+	return e.net.Close()
+}
+
+// ベクトルをL2ノルムが1になるように正規化
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range v {
+		v[i] /= norm
+	}
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"image"
+	"math"
+)
+
+// エンゲージメントスコアの各要素の重み
+const (
+	engagementHeadPoseWeight   = 0.35
+	engagementEyesWeight       = 0.25
+	engagementVisibilityWeight = 0.2
+	engagementArousalWeight    = 0.2
+	// 顔の下半分が遮蔽されている場合の見え方のスコアの倍率
+	occludedVisibilityFactor = 0.7
+)
+
+// 感情ごとの覚醒度（0〜1）
+var emotionArousal = map[Emotion]float64{
+	EmotionSurprise: 1.0,
+	EmotionAngry:    0.8,
+	EmotionHappy:    0.7,
+	EmotionNeutral:  0.4,
+	EmotionSad:      0.3,
+}
+
+// エンゲージメントスコアの算出に使用する要素（それぞれ0〜1）
+type EngagementComponents struct {
+	HeadPose    float64
+	EyeOpenness float64
+	Visibility  float64
+	Arousal     float64
+	// 目の推定が無効な場合は開き具合を除いて重みを再配分する
+	HasEyes bool
+}
+
+// 顔の向き、目の開き具合、顔の見え方、感情の覚醒度からエンゲージメントスコア（0〜1）を計算
+func engagementScore(c EngagementComponents) float64 {
+	sum := engagementHeadPoseWeight*c.HeadPose +
+		engagementVisibilityWeight*c.Visibility +
+		engagementArousalWeight*c.Arousal
+	weight := engagementHeadPoseWeight + engagementVisibilityWeight + engagementArousalWeight
+	if c.HasEyes {
+		sum += engagementEyesWeight * c.EyeOpenness
+		weight += engagementEyesWeight
+	}
+	return clamp01(sum / weight)
+}
+
+// 顔の各要素からエンゲージメントの要素を算出
+func engagementComponents(face Face, rect image.Rectangle, width, height int) EngagementComponents {
+	c := EngagementComponents{
+		HeadPose:   headPoseScore(face.Detector, face.Eyes),
+		Visibility: visibilityScore(rect, width, height, face.Quality),
+		Arousal:    emotionArousal[face.Emotion],
+	}
+	if face.Occlusion != nil && face.Occlusion.Occluded {
+		c.Visibility *= occludedVisibilityFactor
+	}
+	if face.Eyes != nil {
+		c.EyeOpenness = face.Eyes.Openness
+		c.HasEyes = true
+	}
+	return c
+}
+
+// 顔の向きのスコア
+// 横顔の検出器で見つかった顔や、片目しか見えない・視線が逸れている顔は画面を向いていないとみなす
+func headPoseScore(detector string, eyes *EyeAnalysis) float64 {
+	score := 1.0
+	if detector == DetectorProfile || detector == DetectorProfileFlipped {
+		score = 0.4
+	}
+	if eyes == nil {
+		return score
+	}
+	if eyes.Detected == 1 {
+		score *= 0.7
+	}
+	if eyes.Gaze != GazeCenter {
+		score *= 0.7
+	}
+	return score
+}
+
+// 顔の見え方のスコア
+// 画像内に収まっている割合と品質スコアから算出する
+func visibilityScore(rect image.Rectangle, width, height int, quality float64) float64 {
+	area := rect.Dx() * rect.Dy()
+	if area <= 0 {
+		return 0
+	}
+	inside := rect.Intersect(image.Rect(0, 0, width, height))
+	fraction := float64(inside.Dx()*inside.Dy()) / float64(area)
+	return clamp01(fraction * (0.5 + 0.5*quality))
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngagementScore(t *testing.T) {
+	tests := []struct {
+		name       string
+		components EngagementComponents
+		want       float64
+	}{
+		{
+			name:       "全ての要素が最大",
+			components: EngagementComponents{HeadPose: 1, EyeOpenness: 1, Visibility: 1, Arousal: 1, HasEyes: true},
+			want:       1,
+		},
+		{
+			name:       "目を閉じている",
+			components: EngagementComponents{HeadPose: 1, EyeOpenness: 0, Visibility: 1, Arousal: 1, HasEyes: true},
+			want:       0.75,
+		},
+		{
+			name:       "目の推定が無効な場合は重みを再配分",
+			components: EngagementComponents{HeadPose: 1, Visibility: 1, Arousal: 0.4},
+			want:       (0.35 + 0.2 + 0.2*0.4) / 0.75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, engagementScore(tt.components), 1e-9)
+		})
+	}
+}
+
+func TestHeadPoseScore(t *testing.T) {
+	assert.Equal(t, 1.0, headPoseScore(DetectorFrontal, nil))
+	assert.Equal(t, 0.4, headPoseScore(DetectorProfile, nil))
+	assert.Equal(t, 1.0, headPoseScore(DetectorFrontal, &EyeAnalysis{Detected: 2, Gaze: GazeCenter}))
+	assert.InDelta(t, 0.49, headPoseScore(DetectorFrontal, &EyeAnalysis{Detected: 1, Gaze: GazeLeft}), 1e-9)
+}
+
+func TestVisibilityScore(t *testing.T) {
+	assert.InDelta(t, 1.0, visibilityScore(image.Rect(10, 10, 110, 110), 640, 480, 1), 1e-9)
+	assert.InDelta(t, 0.5, visibilityScore(image.Rect(10, 10, 110, 110), 640, 480, 0), 1e-9)
+	assert.InDelta(t, 0.5, visibilityScore(image.Rect(-50, 10, 50, 110), 640, 480, 1), 1e-9)
+	assert.Equal(t, 0.0, visibilityScore(image.Rectangle{}, 640, 480, 1))
+}
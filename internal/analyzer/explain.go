@@ -0,0 +1,156 @@
+package analyzer
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"gocv.io/x/gocv"
+)
+
+// 判定根拠の出力のデフォルト設定
+const (
+	defaultSmileCascade = "models/haarcascade_smile.xml"
+	// 笑顔スコアが0.5になる笑顔のカスケード分類器の反応数
+	smileHalfHits = 10.0
+)
+
+// 各顔の判定根拠
+type FaceExplanation struct {
+	// 感情分類に使用した特徴量
+	Features EmotionFeatures
+	// 口元の笑顔のカスケード分類器の反応の強さ（0〜1）
+	SmileScore float64
+	// 品質スコアの要素
+	LaplacianVariance float64
+	Sharpness         float64
+	SizeScore         float64
+	// 適用された分類ルール
+	Rule string
+	// 分類に使用した閾値と、特徴量の閾値からの差
+	Thresholds map[string]float64
+	Margins    map[string]float64
+	// 平坦化した顔領域のPNG画像（中間画像の出力を指定した場合のみ）
+	ROIImage []byte
+}
+
+// 笑顔のカスケード分類器を読み込む
+func newSmileCascade(path string) (*gocv.CascadeClassifier, error) {
+	cascade := gocv.NewCascadeClassifier()
+	if !cascade.Load(path) {
+		cascade.Close()
+		return nil, fmt.Errorf("笑顔のカスケード分類器の読み込みに失敗: %s", path)
+	}
+	return &cascade, nil
+}
+
+// 顔の判定根拠を作成
+func (fa *FaceAnalyzer) explainFace(c *analysisContext, face Face, rect image.Rectangle) *FaceExplanation {
+	equalize := !fa.preprocess.equalizesLocally()
+	features, _ := extractEmotionFeatures(c.gray, face, equalize)
+	rule, thresholds, margins := explainEmotion(features, fa.emotionParams)
+
+	explanation := &FaceExplanation{
+		Features:   features,
+		Rule:       rule,
+		Thresholds: thresholds,
+		Margins:    margins,
+	}
+
+	rect = rect.Intersect(image.Rect(0, 0, c.gray.Cols(), c.gray.Rows()))
+	if rect.Empty() {
+		return explanation
+	}
+	explanation.LaplacianVariance = laplacianVariance(c.gray, rect)
+	explanation.Sharpness, explanation.SizeScore = qualityComponents(
+		explanation.LaplacianVariance, float64(rect.Dx()), float64(rect.Dy()))
+	explanation.SmileScore = fa.smileScore(c.gray, rect)
+	if c.opts.ExplainImages {
+		explanation.ROIImage = equalizedROIImage(c.gray, rect, equalize)
+	}
+	return explanation
+}
+
+// 顔の下半分で笑顔のカスケード分類器が反応した数から笑顔スコアを計算
+// 反応を統合せずに数えるため、笑顔らしい領域ほど多く反応する
+func (fa *FaceAnalyzer) smileScore(gray gocv.Mat, face image.Rectangle) float64 {
+	if fa.smile == nil {
+		return 0
+	}
+	lower := image.Rect(face.Min.X, face.Min.Y+face.Dy()/2, face.Max.X, face.Max.Y)
+	roi := gray.Region(lower)
+	defer roi.Close()
+
+	w := face.Dx()
+	hits := fa.smile.DetectMultiScaleWithParams(roi, 1.1, 0, 0, image.Pt(w/4, w/8), image.Pt(w, w/2))
+	return smileScoreFromHits(len(hits))
+}
+
+func smileScoreFromHits(hits int) float64 {
+	return float64(hits) / (float64(hits) + smileHalfHits)
+}
+
+// 平坦化した顔領域をPNG画像としてエンコード
+func equalizedROIImage(gray gocv.Mat, rect image.Rectangle, equalize bool) []byte {
+	roi := gray.Region(rect)
+	defer roi.Close()
+
+	equalized := gocv.NewMat()
+	defer equalized.Close()
+	if equalize {
+		gocv.EqualizeHist(roi, &equalized)
+	} else {
+		roi.CopyTo(&equalized)
+	}
+	return encodePNG(equalized)
+}
+
+// MatをPNG画像としてエンコード
+// エンコードに失敗した場合は nil を返す
+func encodePNG(img gocv.Mat) []byte {
+	buf, err := gocv.IMEncode(gocv.PNGFileExt, img)
+	if err != nil {
+		return nil
+	}
+	defer buf.Close()
+	// ネイティブのバッファを解放するためコピーする
+	return append([]byte(nil), buf.GetBytes()...)
+}
+
+// ClassifyEmotion と同じ規則で、適用されたルールと各閾値からの差を返す
+func explainEmotion(f EmotionFeatures, p config.EmotionConfig) (rule string, thresholds, margins map[string]float64) {
+	thresholds = map[string]float64{
+		"surprise_variation":   p.SurpriseVariation,
+		"happy_variation":      p.HappyVariation,
+		"mid_variation":        p.MidVariation,
+		"low_variation":        p.LowVariation,
+		"brightness_threshold": p.BrightnessThreshold,
+	}
+	margins = make(map[string]float64, len(thresholds))
+	for name, threshold := range thresholds {
+		value := f.Variation
+		if name == "brightness_threshold" {
+			value = f.Brightness
+		}
+		margins[name] = value - threshold
+	}
+
+	bright := f.Brightness > p.BrightnessThreshold
+	brightness := "brightness <= brightness_threshold"
+	if bright {
+		brightness = "brightness > brightness_threshold"
+	}
+	switch {
+	case f.Variation > p.SurpriseVariation:
+		rule = "variation > surprise_variation"
+	case f.Variation > p.HappyVariation:
+		rule = "variation > happy_variation"
+	case f.Variation > p.MidVariation:
+		rule = "variation > mid_variation && " + brightness
+	case f.Variation > p.LowVariation:
+		rule = "variation > low_variation && " + brightness
+	default:
+		rule = "variation <= low_variation"
+	}
+	return rule, thresholds, margins
+}
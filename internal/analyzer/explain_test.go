@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainEmotion(t *testing.T) {
+	params := config.DefaultEmotionConfig()
+
+	tests := []struct {
+		name     string
+		features EmotionFeatures
+		wantRule string
+	}{
+		{"驚き", EmotionFeatures{Brightness: 100, Variation: 90}, "variation > surprise_variation"},
+		{"喜び", EmotionFeatures{Brightness: 100, Variation: 70}, "variation > happy_variation"},
+		{"明るい中程度の変化", EmotionFeatures{Brightness: 150, Variation: 55}, "variation > mid_variation && brightness > brightness_threshold"},
+		{"暗い中程度の変化", EmotionFeatures{Brightness: 120, Variation: 55}, "variation > mid_variation && brightness <= brightness_threshold"},
+		{"暗い小さな変化", EmotionFeatures{Brightness: 120, Variation: 40}, "variation > low_variation && brightness <= brightness_threshold"},
+		{"変化なし", EmotionFeatures{Brightness: 120, Variation: 10}, "variation <= low_variation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, thresholds, margins := explainEmotion(tt.features, params)
+			assert.Equal(t, tt.wantRule, rule)
+			assert.Equal(t, params.HappyVariation, thresholds["happy_variation"])
+			assert.InDelta(t, tt.features.Variation-params.SurpriseVariation, margins["surprise_variation"], 1e-9)
+			assert.InDelta(t, tt.features.Brightness-params.BrightnessThreshold, margins["brightness_threshold"], 1e-9)
+			assert.Len(t, margins, len(thresholds))
+		})
+	}
+}
+
+func TestSmileScoreFromHits(t *testing.T) {
+	assert.Equal(t, 0.0, smileScoreFromHits(0))
+	assert.InDelta(t, 0.5, smileScoreFromHits(10), 1e-9)
+	assert.Less(t, smileScoreFromHits(30), 1.0)
+}
+
+func TestQualityComponents(t *testing.T) {
+	sharpness, size := qualityComponents(100, 56, 200)
+	assert.InDelta(t, 0.5, sharpness, 1e-9)
+	assert.InDelta(t, 0.5, size, 1e-9)
+	assert.InDelta(t, 0.25, qualityScore(100, 56, 200), 1e-9)
+
+	sharpness, size = qualityComponents(0, 56, 200)
+	assert.Zero(t, sharpness)
+	assert.Zero(t, size)
+}
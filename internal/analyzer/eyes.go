@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"gocv.io/x/gocv"
+)
+
+// 視線方向（画像上の向き）
+const (
+	GazeCenter = "center"
+	GazeLeft   = "left"
+	GazeRight  = "right"
+	GazeUp     = "up"
+	GazeDown   = "down"
+)
+
+// 目の状態
+const (
+	EyeStateOpen   = "open"
+	EyeStateClosed = "closed"
+)
+
+// 目の推定のデフォルト設定
+const (
+	defaultEyeCascade      = "models/haarcascade_eye.xml"
+	defaultClosedThreshold = 0.2
+	defaultGazeThreshold   = 0.25
+	// 顔領域のうち目を探索する上側の割合
+	eyeSearchRatio = 0.55
+	// 瞳の暗部を含むとみなす行の暗画素の割合
+	darkRowRatio = 0.2
+)
+
+// 目の開き具合と視線方向の推定結果
+type EyeAnalysis struct {
+	// 0（閉じている）〜1（開いている）
+	Openness float64
+	State    string
+	Gaze     string
+	// 検出できた目の数
+	Detected int
+}
+
+// 目のカスケード分類器で目を検出し、開き具合と視線方向を推定する
+type eyeAnalyzer struct {
+	cascade         gocv.CascadeClassifier
+	closedThreshold float64
+	gazeThreshold   float64
+}
+
+// 目のカスケード分類器を読み込んで推定器を作成
+func newEyeAnalyzer(path string, closedThreshold, gazeThreshold float64) (*eyeAnalyzer, error) {
+	cascade := gocv.NewCascadeClassifier()
+	if !cascade.Load(path) {
+		cascade.Close()
+		return nil, fmt.Errorf("目のカスケード分類器の読み込みに失敗: %s", path)
+	}
+	if closedThreshold == 0 {
+		closedThreshold = defaultClosedThreshold
+	}
+	if gazeThreshold == 0 {
+		gazeThreshold = defaultGazeThreshold
+	}
+	return &eyeAnalyzer{
+		cascade:         cascade,
+		closedThreshold: closedThreshold,
+		gazeThreshold:   gazeThreshold,
+	}, nil
+}
+
+// グレースケール画像の顔領域から目の状態を推定
+// 目が検出できない場合は閉じているとみなす
+func (e *eyeAnalyzer) Analyze(gray gocv.Mat, face image.Rectangle) EyeAnalysis {
+	face = face.Intersect(image.Rect(0, 0, gray.Cols(), gray.Rows()))
+	search := image.Rect(face.Min.X, face.Min.Y, face.Max.X, face.Min.Y+int(float64(face.Dy())*eyeSearchRatio))
+	if search.Empty() {
+		return EyeAnalysis{State: EyeStateClosed, Gaze: GazeCenter}
+	}
+
+	roi := gray.Region(search)
+	defer roi.Close()
+
+	w := face.Dx()
+	eyes := e.cascade.DetectMultiScaleWithParams(roi, 1.1, 5, 0, image.Pt(w/10, w/10), image.Pt(w/2, w/2))
+	eyes = pickEyes(eyes)
+
+	var openness, dx, dy float64
+	for _, eye := range eyes {
+		eye = eye.Add(search.Min)
+		o, px, py := measureEye(gray, eye)
+		openness += o
+		dx += px
+		dy += py
+	}
+
+	result := EyeAnalysis{Detected: len(eyes), Gaze: GazeCenter}
+	if len(eyes) > 0 {
+		n := float64(len(eyes))
+		result.Openness = openness / n
+		result.Gaze = classifyGaze(dx/n, dy/n, e.gazeThreshold)
+	}
+	result.State = eyeState(result.Openness, e.closedThreshold)
+	if result.State == EyeStateClosed {
+		// 閉じた目の瞳の位置は信頼できない
+		result.Gaze = GazeCenter
+	}
+	return result
+}
+
+func (e *eyeAnalyzer) Close() error {
+	if e == nil {
+		return nil
+	}
+	return e.cascade.Close()
+}
+
+// 目の領域の開き具合と、中心からの瞳の位置（-1〜1）を計算
+func measureEye(gray gocv.Mat, eye image.Rectangle) (openness, dx, dy float64) {
+	roi := gray.Region(eye)
+	defer roi.Close()
+
+	// 瞳と虹彩を暗部として抽出し、暗部を含む行の割合から開き具合を求める
+	binary := gocv.NewMat()
+	defer binary.Close()
+	gocv.Threshold(roi, &binary, 0, 255, gocv.ThresholdBinaryInv|gocv.ThresholdOtsu)
+
+	rowSums := gocv.NewMat()
+	defer rowSums.Close()
+	gocv.Reduce(binary, &rowSums, 1, gocv.ReduceSum, gocv.MatTypeCV32F)
+
+	fractions := make([]float64, rowSums.Rows())
+	for i := range fractions {
+		fractions[i] = float64(rowSums.GetFloatAt(i, 0)) / 255 / float64(binary.Cols())
+	}
+	openness = opennessFromRows(fractions)
+
+	// 平滑化した画像の最も暗い点を瞳の位置とする
+	blurred := gocv.NewMat()
+	defer blurred.Close()
+	gocv.GaussianBlur(roi, &blurred, image.Pt(7, 7), 0, 0, gocv.BorderDefault)
+	_, _, minLoc, _ := gocv.MinMaxLoc(blurred)
+
+	dx = (float64(minLoc.X) - float64(eye.Dx())/2) / (float64(eye.Dx()) / 2)
+	dy = (float64(minLoc.Y) - float64(eye.Dy())/2) / (float64(eye.Dy()) / 2)
+	return openness, dx, dy
+}
+
+// 検出された目の候補から面積の大きい2つを左から順に返す
+func pickEyes(eyes []image.Rectangle) []image.Rectangle {
+	sorted := append([]image.Rectangle(nil), eyes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Dx()*sorted[i].Dy() > sorted[j].Dx()*sorted[j].Dy()
+	})
+	if len(sorted) > 2 {
+		sorted = sorted[:2]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Min.X < sorted[j].Min.X
+	})
+	return sorted
+}
+
+// 各行の暗画素の割合から目の開き具合（0〜1）を計算
+// 開いた目は目の領域の半分程度の高さに瞳と虹彩の暗部が続く
+func opennessFromRows(fractions []float64) float64 {
+	if len(fractions) == 0 {
+		return 0
+	}
+	rows := 0
+	for _, f := range fractions {
+		if f >= darkRowRatio {
+			rows++
+		}
+	}
+	return math.Min(1, float64(rows)/(float64(len(fractions))/2))
+}
+
+// 開き具合から目の状態を判定
+func eyeState(openness, closedThreshold float64) string {
+	if openness < closedThreshold {
+		return EyeStateClosed
+	}
+	return EyeStateOpen
+}
+
+// 瞳の位置（中心からのずれ、-1〜1）から視線方向を判定
+// ずれの大きい軸の方向を採用し、閾値未満の場合は中央とする
+func classifyGaze(dx, dy, threshold float64) string {
+	if math.Abs(dx) < threshold && math.Abs(dy) < threshold {
+		return GazeCenter
+	}
+	if math.Abs(dx) >= math.Abs(dy) {
+		if dx < 0 {
+			return GazeLeft
+		}
+		return GazeRight
+	}
+	if dy < 0 {
+		return GazeUp
+	}
+	return GazeDown
+}
+
+// 目が閉じている場合に信頼度を減点
+func applyClosedEyePenalty(confidence float32, eyes *EyeAnalysis, penalty float64) float32 {
+	if eyes == nil || eyes.State != EyeStateClosed || penalty <= 0 {
+		return confidence
+	}
+	return confidence * float32(1-penalty)
+}
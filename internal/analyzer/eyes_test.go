@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyGaze(t *testing.T) {
+	tests := []struct {
+		name   string
+		dx, dy float64
+		want   string
+	}{
+		{"中央", 0.1, -0.1, GazeCenter},
+		{"左", -0.5, 0.1, GazeLeft},
+		{"右", 0.4, -0.3, GazeRight},
+		{"上", 0.1, -0.6, GazeUp},
+		{"下", -0.2, 0.5, GazeDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyGaze(tt.dx, tt.dy, 0.25))
+		})
+	}
+}
+
+func TestOpennessFromRows(t *testing.T) {
+	tests := []struct {
+		name      string
+		fractions []float64
+		want      float64
+	}{
+		{"行なし", nil, 0},
+		{"暗部なし", []float64{0, 0.05, 0.1, 0, 0.1, 0}, 0},
+		{"半分の行に暗部", []float64{0, 0.1, 0.5, 0.6, 0.4, 0}, 1},
+		{"細い暗部", []float64{0, 0, 0.3, 0, 0, 0, 0, 0}, 0.25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, opennessFromRows(tt.fractions), 1e-9)
+		})
+	}
+}
+
+func TestPickEyes(t *testing.T) {
+	eyes := pickEyes([]image.Rectangle{
+		image.Rect(60, 10, 80, 30),
+		image.Rect(0, 0, 5, 5),
+		image.Rect(10, 12, 32, 34),
+	})
+	assert.Equal(t, []image.Rectangle{image.Rect(10, 12, 32, 34), image.Rect(60, 10, 80, 30)}, eyes)
+}
+
+func TestApplyClosedEyePenalty(t *testing.T) {
+	closed := &EyeAnalysis{State: EyeStateClosed}
+	open := &EyeAnalysis{State: EyeStateOpen, Openness: 0.8}
+
+	assert.InDelta(t, 0.63, applyClosedEyePenalty(0.9, closed, 0.3), 1e-6)
+	assert.InDelta(t, 0.9, applyClosedEyePenalty(0.9, open, 0.3), 1e-6)
+	assert.InDelta(t, 0.9, applyClosedEyePenalty(0.9, closed, 0), 1e-6)
+	assert.InDelta(t, 0.9, applyClosedEyePenalty(0.9, nil, 0.3), 1e-6)
+	assert.Equal(t, EyeStateClosed, eyeState(0.1, 0.2))
+	assert.Equal(t, EyeStateOpen, eyeState(0.2, 0.2))
+}
@@ -0,0 +1,20 @@
+//go:build matprofile
+
+package analyzer
+
+import (
+	"io"
+
+	"gocv.io/x/gocv"
+)
+
+// matprofile タグ付きのビルドでは gocv の MatProfile で生存中のMatを計測する
+const matProfileEnabled = true
+
+func matProfileCount() int {
+	return gocv.MatProfile.Count()
+}
+
+func writeMatProfile(w io.Writer) error {
+	return gocv.MatProfile.WriteTo(w, 1)
+}
@@ -0,0 +1,15 @@
+//go:build !matprofile
+
+package analyzer
+
+import "io"
+
+const matProfileEnabled = false
+
+func matProfileCount() int {
+	return 0
+}
+
+func writeMatProfile(io.Writer) error {
+	return nil
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"image"
+	"math"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"gocv.io/x/gocv"
+)
+
+// 遮蔽検出のデフォルト設定
+const (
+	defaultOcclusionColorThreshold   = 18
+	defaultOcclusionTextureThreshold = 0.35
+	// これより小さい顔は領域が小さすぎて判定できない
+	minOcclusionFaceSize = 24
+)
+
+// 顔領域に対する各部位の相対位置（左上と右下、0〜1）
+var (
+	foreheadRegion = [4]float64{0.3, 0.08, 0.7, 0.25}
+	eyeRegion      = [4]float64{0.15, 0.25, 0.85, 0.5}
+	mouthRegion    = [4]float64{0.25, 0.65, 0.75, 0.95}
+)
+
+// 顔の下半分の遮蔽の判定結果
+type OcclusionAnalysis struct {
+	// 口元が肌以外の物で覆われている、または口元の構造が見えていない
+	Occluded bool
+	// 肌以外の一様な物（マスクなど）で口元が覆われている
+	Masked bool
+	// 額と口元の色差
+	ColorDistance float64
+	// 口元と目元のテクスチャ量の比
+	TextureRatio float64
+}
+
+// 額と口元の色、目元と口元のテクスチャを比較して顔の下半分の遮蔽を検出する
+type occlusionDetector struct {
+	colorThreshold   float64
+	textureThreshold float64
+}
+
+// 遮蔽検出器を作成
+// 無効な場合は nil を返す
+func newOcclusionDetector(cfg config.OcclusionConfig) *occlusionDetector {
+	if !cfg.Enabled {
+		return nil
+	}
+	d := &occlusionDetector{
+		colorThreshold:   cfg.ColorThreshold,
+		textureThreshold: cfg.TextureThreshold,
+	}
+	if d.colorThreshold == 0 {
+		d.colorThreshold = defaultOcclusionColorThreshold
+	}
+	if d.textureThreshold == 0 {
+		d.textureThreshold = defaultOcclusionTextureThreshold
+	}
+	return d
+}
+
+// カラー画像とグレースケール画像の顔領域から遮蔽を判定
+func (d *occlusionDetector) Analyze(img, gray gocv.Mat, face image.Rectangle) OcclusionAnalysis {
+	face = face.Intersect(image.Rect(0, 0, img.Cols(), img.Rows()))
+	if face.Dx() < minOcclusionFaceSize || face.Dy() < minOcclusionFaceSize {
+		return OcclusionAnalysis{TextureRatio: 1}
+	}
+
+	colorDistance := chromaDistance(img, face)
+	textureRatio := textureLevel(gray, faceSubRegion(face, mouthRegion)) /
+		math.Max(textureLevel(gray, faceSubRegion(face, eyeRegion)), 1e-6)
+	return classifyOcclusion(colorDistance, textureRatio, d.colorThreshold, d.textureThreshold)
+}
+
+// 額と口元の平均色のLab色空間のa*b*平面上の距離を計算
+// 明るさの違いによる影響を避けるため L* は使用しない
+func chromaDistance(img gocv.Mat, face image.Rectangle) float64 {
+	roi := img.Region(face)
+	defer roi.Close()
+
+	lab := gocv.NewMat()
+	defer lab.Close()
+	gocv.CvtColor(roi, &lab, gocv.ColorBGRToLab)
+
+	local := image.Rect(0, 0, face.Dx(), face.Dy())
+	forehead := lab.Region(faceSubRegion(local, foreheadRegion))
+	defer forehead.Close()
+	mouth := lab.Region(faceSubRegion(local, mouthRegion))
+	defer mouth.Close()
+
+	f := forehead.Mean()
+	m := mouth.Mean()
+	return math.Hypot(f.Val2-m.Val2, f.Val3-m.Val3)
+}
+
+// 領域のラプラシアンの標準偏差をテクスチャ量とする
+func textureLevel(gray gocv.Mat, rect image.Rectangle) float64 {
+	roi := gray.Region(rect)
+	defer roi.Close()
+
+	laplacian := gocv.NewMat()
+	defer laplacian.Close()
+	gocv.Laplacian(roi, &laplacian, gocv.MatTypeCV64F, 1, 1, 0, gocv.BorderDefault)
+
+	mean := gocv.NewMat()
+	defer mean.Close()
+	stddev := gocv.NewMat()
+	defer stddev.Close()
+	gocv.MeanStdDev(laplacian, &mean, &stddev)
+	return stddev.GetDoubleAt(0, 0)
+}
+
+// 顔領域の相対位置から部位の領域を計算
+func faceSubRegion(face image.Rectangle, rel [4]float64) image.Rectangle {
+	w := float64(face.Dx())
+	h := float64(face.Dy())
+	return image.Rect(
+		face.Min.X+int(w*rel[0]), face.Min.Y+int(h*rel[1]),
+		face.Min.X+int(w*rel[2]), face.Min.Y+int(h*rel[3]),
+	)
+}
+
+// 色差とテクスチャ比から遮蔽を判定
+// 口元の色が肌と異なるか口元の構造が見えていなければ遮蔽、両方に当てはまればマスクとみなす
+func classifyOcclusion(colorDistance, textureRatio, colorThreshold, textureThreshold float64) OcclusionAnalysis {
+	nonSkin := colorDistance >= colorThreshold
+	lowTexture := textureRatio < textureThreshold
+	return OcclusionAnalysis{
+		Occluded:      nonSkin || lowTexture,
+		Masked:        nonSkin && lowTexture,
+		ColorDistance: colorDistance,
+		TextureRatio:  textureRatio,
+	}
+}
+
+// 遮蔽された顔の感情を判定
+// unknownEmotion が有効な場合は unknown とする
+func occludedEmotion(emotion Emotion, occlusion *OcclusionAnalysis, unknownEmotion bool) Emotion {
+	if occlusion == nil || !occlusion.Occluded || !unknownEmotion {
+		return emotion
+	}
+	return EmotionUnknown
+}
+
+// 遮蔽されている場合に信頼度を減点
+func applyOcclusionPenalty(confidence float32, occlusion *OcclusionAnalysis, penalty float64) float32 {
+	if occlusion == nil || !occlusion.Occluded || penalty <= 0 {
+		return confidence
+	}
+	return confidence * float32(1-penalty)
+}
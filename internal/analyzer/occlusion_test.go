@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyOcclusion(t *testing.T) {
+	tests := []struct {
+		name          string
+		colorDistance float64
+		textureRatio  float64
+		wantOccluded  bool
+		wantMasked    bool
+	}{
+		{"遮蔽なし", 5, 0.6, false, false},
+		{"マスク", 30, 0.1, true, true},
+		{"手で口元を覆う", 6, 0.2, true, false},
+		{"模様のある布", 25, 0.8, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyOcclusion(tt.colorDistance, tt.textureRatio, 18, 0.35)
+			assert.Equal(t, tt.wantOccluded, got.Occluded)
+			assert.Equal(t, tt.wantMasked, got.Masked)
+		})
+	}
+}
+
+func TestFaceSubRegion(t *testing.T) {
+	face := image.Rect(100, 50, 200, 150)
+	assert.Equal(t, image.Rect(125, 115, 175, 145), faceSubRegion(face, mouthRegion))
+	assert.Equal(t, image.Rect(130, 58, 170, 75), faceSubRegion(face, foreheadRegion))
+}
+
+func TestOccludedEmotionAndPenalty(t *testing.T) {
+	occluded := &OcclusionAnalysis{Occluded: true, Masked: true}
+	visible := &OcclusionAnalysis{}
+
+	assert.Equal(t, EmotionUnknown, occludedEmotion(EmotionHappy, occluded, true))
+	assert.Equal(t, EmotionHappy, occludedEmotion(EmotionHappy, occluded, false))
+	assert.Equal(t, EmotionHappy, occludedEmotion(EmotionHappy, visible, true))
+	assert.Equal(t, EmotionHappy, occludedEmotion(EmotionHappy, nil, true))
+
+	assert.InDelta(t, 0.45, applyOcclusionPenalty(0.9, occluded, 0.5), 1e-6)
+	assert.InDelta(t, 0.9, applyOcclusionPenalty(0.9, visible, 0.5), 1e-6)
+	assert.InDelta(t, 0.9, applyOcclusionPenalty(0.9, occluded, 0), 1e-6)
+}
@@ -0,0 +1,355 @@
+package analyzer
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"gocv.io/x/gocv"
+)
+
+// 分析パイプラインの段階名
+const (
+	StageDecode     = "decode"
+	StageGrayscale  = "grayscale"
+	StagePreprocess = "preprocess"
+	StageDetect     = "detect"
+	StageTrack      = "track"
+	StageClassify   = "classify"
+	StageSelect     = "select"
+	StageDraw       = "draw"
+	StageEncode     = "encode"
+)
+
+// 分析パイプラインの段階
+type Stage interface {
+	Name() string
+	Run(c *analysisContext) error
+}
+
+// 段階ごとの処理時間
+type StageTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// パイプラインの各段階で共有する分析の状態
+type analysisContext struct {
+	imgData  []byte
+	opts     AnalyzeOptions
+	policy   string
+	roi      config.ROI
+	img      gocv.Mat
+	gray     gocv.Mat
+	output   gocv.Mat
+	detected []Detection
+	trackIDs []int
+	// 群衆モードで検出に使用したタイルの数
+	tiles  int
+	result *AnalysisResult
+}
+
+func newAnalysisContext(imgData []byte, opts AnalyzeOptions, policy string) *analysisContext {
+	return &analysisContext{
+		imgData: imgData,
+		opts:    opts,
+		policy:  policy,
+		result: &AnalysisResult{
+			PrimaryEmotion: EmotionUnknown,
+			PrimaryIndex:   -1,
+		},
+	}
+}
+
+// 段階の中で作成したMatを解放
+func (c *analysisContext) Close() {
+	for _, m := range []*gocv.Mat{&c.img, &c.gray, &c.output} {
+		if !m.Closed() {
+			m.Close()
+		}
+	}
+}
+
+// 関数を段階として扱うためのアダプター
+type stageFunc struct {
+	name string
+	run  func(c *analysisContext) error
+}
+
+func (s stageFunc) Name() string {
+	return s.name
+}
+
+func (s stageFunc) Run(c *analysisContext) error {
+	return s.run(c)
+}
+
+// 段階名から段階を作成
+func (fa *FaceAnalyzer) stage(name string) (Stage, error) {
+	stages := map[string]func(*analysisContext) error{
+		StageDecode:     fa.decodeStage,
+		StageGrayscale:  fa.grayscaleStage,
+		StagePreprocess: fa.preprocessStage,
+		StageDetect:     fa.detectStage,
+		StageTrack:      fa.trackStage,
+		StageClassify:   fa.classifyStage,
+		StageSelect:     fa.selectStage,
+		StageDraw:       fa.drawStage,
+		StageEncode:     fa.encodeStage,
+	}
+	run, ok := stages[name]
+	if !ok {
+		return nil, fmt.Errorf("不明なパイプラインの段階です: %s", name)
+	}
+	return stageFunc{name: name, run: run}, nil
+}
+
+// 段階名の一覧からパイプラインを構成
+// 空の場合は全ての段階を既定の順で実行する
+func (fa *FaceAnalyzer) buildPipeline(names []string) ([]Stage, error) {
+	if len(names) == 0 {
+		names = config.PipelineStages
+	}
+	stages := make([]Stage, 0, len(names))
+	for _, name := range names {
+		s, err := fa.stage(name)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, s)
+	}
+	return stages, nil
+}
+
+// 各段階を順に実行し、処理時間を記録
+func (fa *FaceAnalyzer) runPipeline(stages []Stage, c *analysisContext) error {
+	for i, s := range stages {
+		start := time.Now()
+		err := s.Run(c)
+		elapsed := time.Since(start)
+
+		c.result.StageTimings = append(c.result.StageTimings, StageTiming{Name: s.Name(), Duration: elapsed})
+		if fa.stageObserver != nil {
+			fa.stageObserver(s.Name(), elapsed)
+		}
+		if err != nil {
+			return err
+		}
+		if c.opts.Progress != nil {
+			c.opts.Progress(float64(i+1) / float64(len(stages)))
+		}
+	}
+	return nil
+}
+
+// 画像データをMatに変換
+func (fa *FaceAnalyzer) decodeStage(c *analysisContext) error {
+	img, err := decodeImage(c.imgData)
+	if err != nil {
+		return err
+	}
+	c.img = img
+	c.result.ImageWidth = img.Cols()
+	c.result.ImageHeight = img.Rows()
+	return nil
+}
+
+// グレースケールに変換（顔検出用）
+func (fa *FaceAnalyzer) grayscaleStage(c *analysisContext) error {
+	c.gray = gocv.NewMat()
+	gocv.CvtColor(c.img, &c.gray, gocv.ColorBGRToGray)
+	return nil
+}
+
+// 低照度補正などの前処理
+func (fa *FaceAnalyzer) preprocessStage(c *analysisContext) error {
+	c.result.Preprocessing = fa.preprocess.Apply(&c.gray)
+	return nil
+}
+
+// 顔の検出
+// 分析対象領域が設定されている場合は領域を囲む矩形のみを検出の対象とし、顔の中心が領域外の検出結果を除外する
+func (fa *FaceAnalyzer) detectStage(c *analysisContext) error {
+	if c.roi.IsZero() {
+		c.detected = fa.detectIn(c, c.img, c.gray)
+		c.result.Faces = make([]Face, len(c.detected))
+		return nil
+	}
+
+	bounds := roiBounds(c.roi, c.img.Cols(), c.img.Rows())
+	if bounds.Empty() {
+		c.result.Faces = []Face{}
+		return nil
+	}
+	img := c.img.Region(bounds)
+	defer img.Close()
+	gray := c.gray.Region(bounds)
+	defer gray.Close()
+
+	c.detected = detectionsInROI(fa.detectIn(c, img, gray), bounds.Min, c.roi, c.img.Cols(), c.img.Rows())
+	c.result.Faces = make([]Face, len(c.detected))
+	return nil
+}
+
+// 画像から顔を検出
+// 群衆モードの場合はタイルに分割して検出する
+func (fa *FaceAnalyzer) detectIn(c *analysisContext, img, gray gocv.Mat) []Detection {
+	if !c.opts.Crowd || fa.crowd == nil {
+		return fa.detectFaces(img, gray)
+	}
+	detections, tiles := fa.crowd.Detect(img, gray, fa.detectFaces, fa.nmsThreshold)
+	c.tiles = tiles
+	return detections
+}
+
+// セッションが指定された場合は前回の検出結果と対応付ける
+func (fa *FaceAnalyzer) trackStage(c *analysisContext) error {
+	if c.opts.SessionID == "" {
+		return nil
+	}
+	rects := make([]image.Rectangle, len(c.detected))
+	for i, detection := range c.detected {
+		rects[i] = detection.Rect
+	}
+	c.trackIDs = fa.tracker.Update(c.opts.SessionID, rects)
+	return nil
+}
+
+// 各顔の感情と品質を分析
+func (fa *FaceAnalyzer) classifyStage(c *analysisContext) error {
+	for i, detection := range c.detected {
+		face := faceFromDetection(detection)
+		face.Quality = faceQuality(c.gray, detection.Rect)
+		face.Emotion = fa.analyzeEmotion(c.gray, face)
+		if c.trackIDs != nil {
+			face.TrackID = c.trackIDs[i]
+		}
+		if fa.embedder != nil {
+			face.Embedding = fa.embedder.Embed(c.img, detection.Rect)
+		}
+		if fa.eyes != nil {
+			eyes := fa.eyes.Analyze(c.gray, detection.Rect)
+			face.Eyes = &eyes
+		}
+		if fa.occlusion != nil {
+			occlusion := fa.occlusion.Analyze(c.img, c.gray, detection.Rect)
+			face.Occlusion = &occlusion
+			face.Emotion = occludedEmotion(face.Emotion, face.Occlusion, fa.occlusionUnknown)
+		}
+		face.Engagement = engagementScore(engagementComponents(face, detection.Rect, c.img.Cols(), c.img.Rows()))
+		if c.opts.Explain || c.opts.ExplainImages {
+			face.Explanation = fa.explainFace(c, face, detection.Rect)
+		}
+		c.result.Faces[i] = face
+	}
+	if c.opts.ExplainImages {
+		c.result.GrayscaleImage = encodePNG(c.gray)
+	}
+	return nil
+}
+
+// 主要な顔の選択
+// 群衆モードの場合は全体の集計も作成する
+func (fa *FaceAnalyzer) selectStage(c *analysisContext) error {
+	result := c.result
+	result.PrimaryIndex, result.PrimaryPolicy = selectPrimaryFace(
+		result.Faces, c.policy, c.opts.TrackID, c.img.Cols(), c.img.Rows(), fa.primaryPolicy)
+	if result.PrimaryIndex >= 0 {
+		primary := result.Faces[result.PrimaryIndex]
+		result.PrimaryEmotion = primary.Emotion
+		result.PrimaryTrackID = primary.TrackID
+		result.Confidence = 0.9 // TODO: 実際のスコアを計算
+		result.Confidence = applyClosedEyePenalty(result.Confidence, primary.Eyes, fa.eyePenalty)
+		result.Confidence = applyOcclusionPenalty(result.Confidence, primary.Occlusion, fa.occlusionPenalty)
+	}
+	if c.opts.Crowd {
+		result.Crowd = crowdSummary(result.Faces, c.tiles)
+	}
+	return nil
+}
+
+// 処理結果を保存するための新しい画像に検出結果を描画
+func (fa *FaceAnalyzer) drawStage(c *analysisContext) error {
+	c.output = c.img.Clone()
+
+	// 分析対象領域を青で描画
+	if points := roiPolygon(c.roi, c.img.Cols(), c.img.Rows()); len(points) > 0 {
+		pv := gocv.NewPointsVectorFromPoints([][]image.Point{points})
+		gocv.Polylines(&c.output, pv, true, color.RGBA{0, 128, 255, 255}, 2)
+		pv.Close()
+	}
+
+	for i, detection := range c.detected {
+		rect := detection.Rect
+
+		// 主要な顔は緑、それ以外は黄色の矩形を描画
+		col := color.RGBA{255, 200, 0, 255}
+		if i == c.result.PrimaryIndex {
+			col = color.RGBA{0, 255, 0, 255}
+		}
+		gocv.Rectangle(&c.output, rect, col, 3)
+
+		// 感情のラベルを顔の上に描画
+		fa.drawLabel(&c.output, c.result.Faces[i].Emotion, c.opts.Locale, rect, col)
+	}
+	return nil
+}
+
+// 感情のラベルをフォントで描画し、顔の矩形の上に合成
+// フォントで描画できない場合はHersheyフォントで感情の値を描画する
+func (fa *FaceAnalyzer) drawLabel(dst *gocv.Mat, emotion Emotion, locale string, rect image.Rectangle, col color.RGBA) {
+	if locale == "" {
+		locale = fa.labels.Locale()
+	}
+	patch, err := fa.labels.Render(annotation.EmotionLabel(string(emotion), locale), col)
+	if err != nil {
+		gocv.PutText(dst, string(emotion), image.Pt(rect.Min.X, rect.Min.Y-10), gocv.FontHersheyPlain, 1.2, col, 2)
+		return
+	}
+
+	// 画像の上端からはみ出す場合は顔の矩形の内側に描画する
+	at := image.Pt(rect.Min.X, rect.Min.Y-patch.Bounds().Dy())
+	if at.Y < 0 {
+		at.Y = rect.Min.Y
+	}
+	area := patch.Bounds().Add(at).Intersect(image.Rect(0, 0, dst.Cols(), dst.Rows()))
+	if area.Empty() {
+		return
+	}
+
+	region := dst.Region(area)
+	defer region.Close()
+	// 領域は連続していないため、コピーしてから合成する
+	pixels := region.Clone()
+	defer pixels.Close()
+	data := pixels.ToBytes()
+	annotation.BlendBGR(data, area.Dx(), patch, area.Min.Sub(at))
+
+	blended, err := gocv.NewMatFromBytes(area.Dy(), area.Dx(), gocv.MatTypeCV8UC3, data)
+	if err != nil {
+		return
+	}
+	defer blended.Close()
+	blended.CopyTo(&region)
+}
+
+// 処理済みの画像をエンコード
+// 描画の段階を省略した場合は入力画像をそのままエンコードする
+func (fa *FaceAnalyzer) encodeStage(c *analysisContext) error {
+	output := c.output
+	if output.Closed() {
+		output = c.img
+	}
+	buf, err := gocv.IMEncode(".jpg", output)
+	if err != nil {
+		return errors.OpenCVError("画像のエンコード", err).WithCode(errors.ErrCodeOpenCVError)
+	}
+	defer buf.Close()
+	// ネイティブのバッファを解放するためコピーする
+	c.result.ProcessedImageData = append([]byte(nil), buf.GetBytes()...)
+	return nil
+}
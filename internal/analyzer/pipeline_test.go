@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaceAnalyzer_RunPipeline(t *testing.T) {
+	var observed []string
+	fa := &FaceAnalyzer{}
+	fa.SetStageObserver(func(stage string, d time.Duration) {
+		observed = append(observed, stage)
+	})
+
+	var ran []string
+	record := func(name string, err error) Stage {
+		return stageFunc{name: name, run: func(c *analysisContext) error {
+			ran = append(ran, name)
+			return err
+		}}
+	}
+
+	t.Run("全ての段階を順に実行", func(t *testing.T) {
+		ran, observed = nil, nil
+		c := newAnalysisContext([]byte{1}, AnalyzeOptions{}, PrimaryLargest)
+		defer c.Close()
+
+		require.NoError(t, fa.runPipeline([]Stage{record("a", nil), record("b", nil)}, c))
+		assert.Equal(t, []string{"a", "b"}, ran)
+		assert.Equal(t, []string{"a", "b"}, observed)
+		require.Len(t, c.result.StageTimings, 2)
+		assert.Equal(t, "a", c.result.StageTimings[0].Name)
+		assert.Equal(t, "b", c.result.StageTimings[1].Name)
+	})
+
+	t.Run("エラーの段階で中断", func(t *testing.T) {
+		ran, observed = nil, nil
+		c := newAnalysisContext([]byte{1}, AnalyzeOptions{}, PrimaryLargest)
+		defer c.Close()
+
+		errStage := errors.New("失敗")
+		err := fa.runPipeline([]Stage{record("a", errStage), record("b", nil)}, c)
+		assert.ErrorIs(t, err, errStage)
+		assert.Equal(t, []string{"a"}, ran)
+		// 失敗した段階の処理時間も記録する
+		assert.Equal(t, []string{"a"}, observed)
+		assert.Len(t, c.result.StageTimings, 1)
+	})
+
+	t.Run("進捗の通知", func(t *testing.T) {
+		var progress []float64
+		c := newAnalysisContext([]byte{1}, AnalyzeOptions{
+			Progress: func(p float64) { progress = append(progress, p) },
+		}, PrimaryLargest)
+		defer c.Close()
+
+		require.NoError(t, fa.runPipeline([]Stage{record("a", nil), record("b", nil)}, c))
+		assert.Equal(t, []float64{0.5, 1}, progress)
+	})
+}
+
+func TestFaceAnalyzer_BuildPipeline(t *testing.T) {
+	fa := &FaceAnalyzer{}
+
+	stages, err := fa.buildPipeline(nil)
+	require.NoError(t, err)
+	names := make([]string, len(stages))
+	for i, s := range stages {
+		names[i] = s.Name()
+	}
+	assert.Equal(t, config.PipelineStages, names)
+
+	stages, err = fa.buildPipeline([]string{StageDecode, StageGrayscale, StageDetect, StageClassify, StageSelect})
+	require.NoError(t, err)
+	assert.Len(t, stages, 5)
+
+	_, err = fa.buildPipeline([]string{"blur"})
+	assert.Error(t, err)
+}
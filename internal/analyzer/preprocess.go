@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sync"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"gocv.io/x/gocv"
+)
+
+// 前処理の名前
+const (
+	PreprocessCLAHE   = "clahe"
+	PreprocessGamma   = "gamma"
+	PreprocessDenoise = "denoise"
+)
+
+// 前処理のデフォルト設定
+const (
+	defaultCLAHEClipLimit = 2.0
+	defaultCLAHETileSize  = 8
+	defaultGamma          = 1.5
+	defaultDenoiseKernel  = 3
+)
+
+// 検出と感情分類の前にグレースケール画像へ適用する前処理
+type preprocessor struct {
+	steps     []string
+	lowLight  float64
+	clipLimit float64
+	tileSize  int
+	gamma     float64
+	kernel    int
+
+	// CLAHEは内部にバッファを持つため並行実行時はロックする
+	mu       sync.Mutex
+	clahe    gocv.CLAHE
+	hasCLAHE bool
+	gammaLUT gocv.Mat
+	hasGamma bool
+}
+
+// 設定から前処理を作成
+// 前処理が指定されていない場合はnilを返す
+func newPreprocessor(cfg config.PreprocessConfig) *preprocessor {
+	if len(cfg.Steps) == 0 {
+		return nil
+	}
+
+	p := &preprocessor{
+		steps:     append([]string(nil), cfg.Steps...),
+		lowLight:  cfg.LowLightThreshold,
+		clipLimit: cfg.CLAHEClipLimit,
+		tileSize:  cfg.CLAHETileSize,
+		gamma:     cfg.Gamma,
+		kernel:    cfg.DenoiseKernel,
+	}
+	if p.clipLimit == 0 {
+		p.clipLimit = defaultCLAHEClipLimit
+	}
+	if p.tileSize == 0 {
+		p.tileSize = defaultCLAHETileSize
+	}
+	if p.gamma == 0 {
+		p.gamma = defaultGamma
+	}
+	if p.kernel == 0 {
+		p.kernel = defaultDenoiseKernel
+	}
+
+	for _, step := range p.steps {
+		switch step {
+		case PreprocessCLAHE:
+			p.clahe = gocv.NewCLAHEWithParams(p.clipLimit, image.Pt(p.tileSize, p.tileSize))
+			p.hasCLAHE = true
+		case PreprocessGamma:
+			table := gammaTable(p.gamma)
+			p.gammaLUT = gocv.NewMatWithSize(1, len(table), gocv.MatTypeCV8U)
+			for i, v := range table {
+				p.gammaLUT.SetUCharAt(0, i, v)
+			}
+			p.hasGamma = true
+		}
+	}
+	return p
+}
+
+// グレースケール画像に前処理を適用し、適用した処理の説明を返す
+// 低照度の閾値が設定されている場合は、平均輝度が閾値未満の画像にのみ適用する
+func (p *preprocessor) Apply(gray *gocv.Mat) []string {
+	if p == nil {
+		return nil
+	}
+	if p.lowLight > 0 && gray.Mean().Val1 >= p.lowLight {
+		return nil
+	}
+
+	applied := make([]string, 0, len(p.steps))
+	dst := gocv.NewMat()
+	defer dst.Close()
+
+	for _, step := range p.steps {
+		switch step {
+		case PreprocessCLAHE:
+			p.mu.Lock()
+			p.clahe.Apply(*gray, &dst)
+			p.mu.Unlock()
+			applied = append(applied, fmt.Sprintf("clahe(clip=%.1f,tile=%d)", p.clipLimit, p.tileSize))
+		case PreprocessGamma:
+			gocv.LUT(*gray, p.gammaLUT, &dst)
+			applied = append(applied, fmt.Sprintf("gamma(%.2f)", p.gamma))
+		case PreprocessDenoise:
+			gocv.GaussianBlur(*gray, &dst, image.Pt(p.kernel, p.kernel), 0, 0, gocv.BorderDefault)
+			applied = append(applied, fmt.Sprintf("denoise(kernel=%d)", p.kernel))
+		default:
+			continue
+		}
+		dst.CopyTo(gray)
+	}
+	return applied
+}
+
+// CLAHEで局所的に平坦化済みかどうか
+// 平坦化済みの場合は顔領域全体のヒストグラム平坦化を行わない
+func (p *preprocessor) equalizesLocally() bool {
+	return p != nil && p.hasCLAHE
+}
+
+// 前処理が保持するリソースを解放
+func (p *preprocessor) Close() error {
+	if p == nil {
+		return nil
+	}
+	var err error
+	if p.hasCLAHE {
+		err = p.clahe.Close()
+		p.hasCLAHE = false
+	}
+	if p.hasGamma {
+		if closeErr := p.gammaLUT.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		p.hasGamma = false
+	}
+	return err
+}
+
+// ガンマ補正のルックアップテーブルを作成
+// gammaが1より大きい場合は暗部を持ち上げる
+func gammaTable(gamma float64) [256]uint8 {
+	var table [256]uint8
+	for i := range table {
+		v := math.Pow(float64(i)/255, 1/gamma) * 255
+		table[i] = uint8(math.Min(255, math.Round(v)))
+	}
+	return table
+}
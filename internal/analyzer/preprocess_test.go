@@ -0,0 +1,34 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGammaTable(t *testing.T) {
+	t.Run("ガンマ1は恒等変換", func(t *testing.T) {
+		table := gammaTable(1)
+		for i, v := range table {
+			assert.Equal(t, uint8(i), v)
+		}
+	})
+
+	t.Run("ガンマが1より大きい場合は暗部を持ち上げる", func(t *testing.T) {
+		table := gammaTable(2)
+		assert.Equal(t, uint8(0), table[0])
+		assert.Equal(t, uint8(255), table[255])
+		assert.Equal(t, uint8(128), table[64])
+		for i := 1; i < 255; i++ {
+			assert.GreaterOrEqual(t, table[i], uint8(i))
+		}
+	})
+}
+
+func TestNewPreprocessor_NoSteps(t *testing.T) {
+	p := newPreprocessor(config.PreprocessConfig{})
+	assert.Nil(t, p)
+	assert.False(t, p.equalizesLocally())
+	assert.NoError(t, p.Close())
+}
@@ -0,0 +1,80 @@
+package analyzer
+
+import "math"
+
+// 主要な顔の選択ポリシー
+const (
+	PrimaryLargest    = "largest"
+	PrimaryCenter     = "center"
+	PrimaryConfidence = "confidence"
+	PrimaryQuality    = "quality"
+	PrimaryTracked    = "tracked"
+)
+
+// 品質スコアの計算に使用する定数
+const (
+	// ラプラシアンの分散がこの値のとき鮮明度を0.5とする
+	sharpnessHalfVariance = 100.0
+	// 短辺がこのピクセル数以上の顔はサイズによる減点をしない
+	qualityFullSize = 112.0
+)
+
+// ポリシーに従って主要な顔のインデックスを選択し、実際に使用したポリシーを返す
+// tracked で指定したIDの顔が見つからない場合は fallback のポリシーで選択する
+// 顔がない場合は -1 を返す
+func selectPrimaryFace(faces []Face, policy string, trackID int, width, height int, fallback string) (int, string) {
+	if len(faces) == 0 {
+		return -1, policy
+	}
+
+	if policy == PrimaryTracked {
+		for i, face := range faces {
+			if trackID != 0 && face.TrackID == trackID {
+				return i, PrimaryTracked
+			}
+		}
+		policy = fallback
+	}
+
+	var score func(Face) float64
+	switch policy {
+	case PrimaryCenter:
+		cx, cy := float64(width)/2, float64(height)/2
+		score = func(f Face) float64 {
+			return -math.Hypot(f.X+f.Width/2-cx, f.Y+f.Height/2-cy)
+		}
+	case PrimaryConfidence:
+		score = func(f Face) float64 { return f.DetectionScore }
+	case PrimaryQuality:
+		score = func(f Face) float64 { return f.Quality }
+	default:
+		policy = PrimaryLargest
+		score = func(f Face) float64 { return f.Width * f.Height }
+	}
+
+	// 同点の場合は面積の大きい顔を優先する
+	best := 0
+	for i := 1; i < len(faces); i++ {
+		si, sb := score(faces[i]), score(faces[best])
+		if si > sb || (si == sb && faces[i].Width*faces[i].Height > faces[best].Width*faces[best].Height) {
+			best = i
+		}
+	}
+	return best, policy
+}
+
+// 鮮明度（ラプラシアンの分散）と顔のサイズから0〜1の品質スコアを計算
+func qualityScore(laplacianVariance, width, height float64) float64 {
+	sharpness, size := qualityComponents(laplacianVariance, width, height)
+	return sharpness * size
+}
+
+// 品質スコアの要素（鮮明度とサイズ、それぞれ0〜1）を計算
+func qualityComponents(laplacianVariance, width, height float64) (sharpness, size float64) {
+	if laplacianVariance <= 0 || width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	sharpness = laplacianVariance / (laplacianVariance + sharpnessHalfVariance)
+	size = math.Min(1, math.Min(width, height)/qualityFullSize)
+	return sharpness, size
+}
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectPrimaryFace(t *testing.T) {
+	faces := []Face{
+		{X: 0, Y: 0, Width: 100, Height: 100, DetectionScore: 0.6, Quality: 0.2, TrackID: 1},
+		{X: 270, Y: 190, Width: 100, Height: 100, DetectionScore: 0.6, Quality: 0.9, TrackID: 2},
+		{X: 500, Y: 300, Width: 60, Height: 60, DetectionScore: 0.95, Quality: 0.5, TrackID: 3},
+		{X: 400, Y: 0, Width: 150, Height: 150, DetectionScore: 0.6, Quality: 0.1, TrackID: 4},
+	}
+
+	tests := []struct {
+		name       string
+		policy     string
+		trackID    int
+		wantIndex  int
+		wantPolicy string
+	}{
+		{"最大の顔", PrimaryLargest, 0, 3, PrimaryLargest},
+		{"中央の顔", PrimaryCenter, 0, 1, PrimaryCenter},
+		{"検出スコアが最大の顔", PrimaryConfidence, 0, 2, PrimaryConfidence},
+		{"品質が最大の顔", PrimaryQuality, 0, 1, PrimaryQuality},
+		{"トラッキングIDの顔", PrimaryTracked, 3, 2, PrimaryTracked},
+		{"トラッキングIDが見つからない場合はフォールバック", PrimaryTracked, 9, 3, PrimaryLargest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, policy := selectPrimaryFace(faces, tt.policy, tt.trackID, 640, 480, PrimaryLargest)
+			assert.Equal(t, tt.wantIndex, index)
+			assert.Equal(t, tt.wantPolicy, policy)
+		})
+	}
+
+	t.Run("顔がない場合", func(t *testing.T) {
+		index, _ := selectPrimaryFace(nil, PrimaryLargest, 0, 640, 480, PrimaryLargest)
+		assert.Equal(t, -1, index)
+	})
+}
+
+func TestQualityScore(t *testing.T) {
+	assert.Equal(t, 0.0, qualityScore(0, 100, 100))
+	assert.InDelta(t, 0.5, qualityScore(100, 200, 200), 1e-9)
+	assert.InDelta(t, 0.25, qualityScore(100, 56, 200), 1e-9)
+	assert.Greater(t, qualityScore(400, 200, 200), qualityScore(100, 200, 200))
+}
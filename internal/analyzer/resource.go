@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"image"
+	"io"
 	"log"
 	"runtime"
 	"sync"
@@ -18,9 +19,14 @@ type ResourceManager struct {
 	cascade  *gocv.CascadeClassifier
 	gpuMat   *gocv.Mat
 	useGPU   bool
-	pool     *sync.Pool
+	pool     []*gocv.Mat
 	closed   bool
 	maxItems int
+
+	// Matの生成・解放の集計
+	created int64
+	freed   int64
+	inUse   int
 }
 
 // 新しいResourceManagerを作成
@@ -39,11 +45,6 @@ func NewResourceManager(cascadeFile string, useGPU bool, maxPoolSize int) (*Reso
 		cascade:  &cascade,
 		useGPU:   useGPU,
 		maxItems: maxPoolSize,
-		pool: &sync.Pool{
-			New: func() interface{} {
-				return gocv.NewMat()
-			},
-		},
 	}
 
 	if useGPU {
@@ -63,17 +64,22 @@ func NewResourceManager(cascadeFile string, useGPU bool, maxPoolSize int) (*Reso
 
 // Matリソースを取得
 func (rm *ResourceManager) AcquireMat() (*gocv.Mat, error) {
-	rm.mu.RLock()
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
 	if rm.closed {
-		rm.mu.RUnlock()
 		return nil, errors.ResourceError("リソースマネージャは既に終了しています", nil)
 	}
-	rm.mu.RUnlock()
 
-	mat := rm.pool.Get().(*gocv.Mat)
-	if mat.Empty() {
-		*mat = gocv.NewMat()
+	var mat *gocv.Mat
+	if n := len(rm.pool); n > 0 {
+		mat = rm.pool[n-1]
+		rm.pool = rm.pool[:n-1]
+	} else {
+		m := gocv.NewMat()
+		rm.created++
+		mat = &m
 	}
+	rm.inUse++
 	return mat, nil
 }
 
@@ -86,9 +92,26 @@ func (rm *ResourceManager) ReleaseMat(mat *gocv.Mat) error {
 		return fmt.Errorf("マトリックスがnilです")
 	}
 
-	// 新しいマトリックスを作成してプールに戻す
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	// 使用済みのマトリックスを解放し、プールに空きがあれば新しいマトリックスを戻す
+	// 解放後のマトリックスは呼び出し元で使用しないこと
+	if !mat.Closed() {
+		if err := mat.Close(); err != nil {
+			return fmt.Errorf("マトリックスのクローズに失敗: %w", err)
+		}
+		rm.freed++
+	}
+	if rm.inUse > 0 {
+		rm.inUse--
+	}
+	if rm.closed || len(rm.pool) >= rm.maxItems {
+		return nil
+	}
 	*mat = gocv.NewMat()
-	rm.pool.Put(mat)
+	rm.created++
+	rm.pool = append(rm.pool, mat)
 	return nil
 }
 
@@ -135,6 +158,13 @@ func (rm *ResourceManager) processOnGPU(ctx context.Context, gpuMat *gocv.Mat) e
 
 // リソースを解放
 func (rm *ResourceManager) Close() error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.closed {
+		return nil
+	}
+	rm.closed = true
+
 	var errs []error
 
 	if rm.cascade != nil {
@@ -149,6 +179,15 @@ func (rm *ResourceManager) Close() error {
 		}
 	}
 
+	for _, mat := range rm.pool {
+		if err := mat.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("プール中のマトリックスのクローズに失敗: %w", err))
+			continue
+		}
+		rm.freed++
+	}
+	rm.pool = nil
+
 	if len(errs) > 0 {
 		return fmt.Errorf("リソースのクリーンアップに失敗: %v", errs)
 	}
@@ -167,6 +206,20 @@ type Status struct {
 	IsGPUEnabled bool
 	PoolSize     int
 	IsClosed     bool
+	Mats         MatStats
+}
+
+// Matの使用状況
+type MatStats struct {
+	// マネージャーが生成・解放したMatの累計
+	Created int64
+	Freed   int64
+	// 貸し出し中とプール中のMat数
+	InUse  int
+	Pooled int
+	// プロセス全体で生存中のMat数（matprofile タグ付きでビルドした場合のみ計測）
+	ProfileEnabled bool
+	Live           int
 }
 
 // リソースの状態を返す
@@ -174,9 +227,36 @@ func (rm *ResourceManager) GetStatus() Status {
 	rm.mu.RLock()
 	defer rm.mu.RUnlock()
 
+	live, enabled := MatProfileCount()
 	return Status{
 		IsGPUEnabled: rm.useGPU,
 		PoolSize:     rm.maxItems,
 		IsClosed:     rm.closed,
+		Mats: MatStats{
+			Created:        rm.created,
+			Freed:          rm.freed,
+			InUse:          rm.inUse,
+			Pooled:         len(rm.pool),
+			ProfileEnabled: enabled,
+			Live:           live,
+		},
+	}
+}
+
+// プロセス全体で生存中のMat数を返す
+// matprofile タグ付きでビルドしていない場合は計測できないため false を返す
+func MatProfileCount() (int, bool) {
+	if !matProfileEnabled {
+		return 0, false
+	}
+	return matProfileCount(), true
+}
+
+// 生存中のMatを生成したスタックトレースを書き出す
+// matprofile タグ付きでビルドしていない場合は何も書き出さない
+func WriteMatProfile(w io.Writer) error {
+	if !matProfileEnabled {
+		return nil
 	}
+	return writeMatProfile(w)
 }
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/okamyuji/face-emotion-analyzer/internal/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gocv.io/x/gocv"
@@ -146,3 +147,43 @@ func TestResourceManager_Finalizer(t *testing.T) {
 		// ファイナライザーが実行されることを期待
 	})
 }
+
+func TestResourceManager_MatStats(t *testing.T) {
+	cascadeFile := setupTestCascadeFile(t)
+
+	rm, err := NewResourceManager(cascadeFile, false, 1)
+	require.NoError(t, err)
+	defer rm.Close()
+
+	testutil.VerifyNoMatLeaks(t, func() int { return rm.GetStatus().Mats.InUse })
+	testutil.VerifyNoMatLeaks(t, func() int {
+		live, _ := MatProfileCount()
+		return live
+	})
+
+	first, err := rm.AcquireMat()
+	require.NoError(t, err)
+	second, err := rm.AcquireMat()
+	require.NoError(t, err)
+
+	status := rm.GetStatus()
+	assert.Equal(t, int64(2), status.Mats.Created)
+	assert.Equal(t, 2, status.Mats.InUse)
+	assert.Equal(t, 0, status.Mats.Pooled)
+
+	// 解放したMatはクローズされ、プールの上限を超えた分は戻さない
+	require.NoError(t, rm.ReleaseMat(first))
+	require.NoError(t, rm.ReleaseMat(second))
+
+	status = rm.GetStatus()
+	assert.Equal(t, int64(2), status.Mats.Freed)
+	assert.Equal(t, 0, status.Mats.InUse)
+	assert.Equal(t, 1, status.Mats.Pooled)
+	assert.True(t, second.Closed())
+
+	// プール中のMatを再利用する
+	reused, err := rm.AcquireMat()
+	require.NoError(t, err)
+	assert.Same(t, first, reused)
+	require.NoError(t, rm.ReleaseMat(reused))
+}
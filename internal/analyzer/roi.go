@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"image"
+	"math"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+)
+
+// 分析対象領域の頂点を画像上の座標に変換
+// 矩形の場合は4つの頂点を返し、未設定の場合は nil を返す
+func roiPolygon(roi config.ROI, width, height int) []image.Point {
+	if roi.IsZero() {
+		return nil
+	}
+	w := float64(width)
+	h := float64(height)
+	if len(roi.Polygon) > 0 {
+		points := make([]image.Point, len(roi.Polygon))
+		for i, p := range roi.Polygon {
+			points[i] = image.Pt(int(math.Round(p[0]*w)), int(math.Round(p[1]*h)))
+		}
+		return points
+	}
+	minX := int(math.Round(roi.X * w))
+	minY := int(math.Round(roi.Y * h))
+	maxX := int(math.Round((roi.X + roi.Width) * w))
+	maxY := int(math.Round((roi.Y + roi.Height) * h))
+	return []image.Point{{minX, minY}, {maxX, minY}, {maxX, maxY}, {minX, maxY}}
+}
+
+// 分析対象領域を囲む矩形を返す
+// 未設定の場合は画像全体を返す
+func roiBounds(roi config.ROI, width, height int) image.Rectangle {
+	full := image.Rect(0, 0, width, height)
+	points := roiPolygon(roi, width, height)
+	if len(points) == 0 {
+		return full
+	}
+	bounds := image.Rectangle{Min: points[0], Max: points[0]}
+	for _, p := range points[1:] {
+		bounds.Min.X = min(bounds.Min.X, p.X)
+		bounds.Min.Y = min(bounds.Min.Y, p.Y)
+		bounds.Max.X = max(bounds.Max.X, p.X)
+		bounds.Max.Y = max(bounds.Max.Y, p.Y)
+	}
+	return bounds.Intersect(full)
+}
+
+// 正規化座標の点が分析対象領域に含まれるかを返す
+func roiContains(roi config.ROI, x, y float64) bool {
+	if len(roi.Polygon) > 0 {
+		return polygonContains(roi.Polygon, x, y)
+	}
+	if roi.IsZero() {
+		return true
+	}
+	return x >= roi.X && x <= roi.X+roi.Width && y >= roi.Y && y <= roi.Y+roi.Height
+}
+
+// 点が多角形に含まれるかを交差数で判定
+func polygonContains(polygon [][2]float64, x, y float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		xi, yi := polygon[i][0], polygon[i][1]
+		xj, yj := polygon[j][0], polygon[j][1]
+		if (yi > y) != (yj > y) && x < (xj-xi)*(y-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// 分析対象領域を切り出した画像での検出結果を元の画像の座標に戻し、
+// 顔の中心が領域外の検出結果を除外する
+func detectionsInROI(detections []Detection, offset image.Point, roi config.ROI, width, height int) []Detection {
+	var result []Detection
+	for _, d := range detections {
+		d.Rect = d.Rect.Add(offset)
+		cx := float64(d.Rect.Min.X+d.Rect.Max.X) / 2 / float64(width)
+		cy := float64(d.Rect.Min.Y+d.Rect.Max.Y) / 2 / float64(height)
+		if !roiContains(roi, cx, cy) {
+			continue
+		}
+		result = append(result, d)
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoiBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		roi  config.ROI
+		want image.Rectangle
+	}{
+		{"未設定", config.ROI{}, image.Rect(0, 0, 640, 480)},
+		{"矩形", config.ROI{X: 0.25, Y: 0.25, Width: 0.5, Height: 0.5}, image.Rect(160, 120, 480, 360)},
+		{
+			"多角形",
+			config.ROI{Polygon: [][2]float64{{0.5, 0.1}, {0.9, 0.5}, {0.5, 0.9}, {0.1, 0.5}}},
+			image.Rect(64, 48, 576, 432),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, roiBounds(tt.roi, 640, 480))
+		})
+	}
+}
+
+func TestRoiContains(t *testing.T) {
+	rect := config.ROI{X: 0.25, Y: 0.25, Width: 0.5, Height: 0.5}
+	diamond := config.ROI{Polygon: [][2]float64{{0.5, 0.1}, {0.9, 0.5}, {0.5, 0.9}, {0.1, 0.5}}}
+
+	assert.True(t, roiContains(config.ROI{}, 0.95, 0.05))
+	assert.True(t, roiContains(rect, 0.5, 0.5))
+	assert.False(t, roiContains(rect, 0.1, 0.5))
+	assert.True(t, roiContains(diamond, 0.5, 0.5))
+	// 外接矩形の内側でも多角形の外側の点は含まない
+	assert.False(t, roiContains(diamond, 0.15, 0.15))
+}
+
+func TestDetectionsInROI(t *testing.T) {
+	diamond := config.ROI{Polygon: [][2]float64{{0.5, 0.1}, {0.9, 0.5}, {0.5, 0.9}, {0.1, 0.5}}}
+	detections := []Detection{
+		// 切り出した画像での座標
+		{Rect: image.Rect(230, 170, 290, 230), Detector: DetectorFrontal},
+		{Rect: image.Rect(0, 0, 30, 30), Detector: DetectorFrontal},
+	}
+
+	got := detectionsInROI(detections, image.Pt(64, 48), diamond, 640, 480)
+
+	// 元の画像の座標に戻し、中心が領域外の顔を除外する
+	assert.Equal(t, []Detection{{Rect: image.Rect(294, 218, 354, 278), Detector: DetectorFrontal}}, got)
+}
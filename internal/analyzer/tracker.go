@@ -0,0 +1,161 @@
+package analyzer
+
+import (
+	"image"
+	"sort"
+	"sync"
+	"time"
+)
+
+// トラッキングのデフォルト設定
+const (
+	defaultTrackIoUThreshold = 0.3
+	defaultTrackMaxMissed    = 5
+	defaultTrackSessionTTL   = 5 * time.Minute
+	defaultTrackMaxSessions  = 10000
+)
+
+// セッションごとに顔を追跡し、フレーム間で同じ顔に同じIDを割り当てる
+type Tracker struct {
+	mu           sync.Mutex
+	sessions     map[string]*trackSession
+	iouThreshold float64
+	maxMissed    int
+	ttl          time.Duration
+	maxSessions  int
+	now          func() time.Time
+}
+
+type trackSession struct {
+	nextID   int
+	tracks   []track
+	lastSeen time.Time
+}
+
+type track struct {
+	id     int
+	rect   image.Rectangle
+	missed int
+}
+
+// トラッカーを作成
+// 0以下の値を指定した項目はデフォルト値を使用する
+// セッション数が maxSessions に達した場合は最も長く更新のないセッションを破棄する
+func NewTracker(iouThreshold float64, maxMissed int, ttl time.Duration, maxSessions int) *Tracker {
+	if iouThreshold <= 0 {
+		iouThreshold = defaultTrackIoUThreshold
+	}
+	if maxMissed <= 0 {
+		maxMissed = defaultTrackMaxMissed
+	}
+	if ttl <= 0 {
+		ttl = defaultTrackSessionTTL
+	}
+	if maxSessions <= 0 {
+		maxSessions = defaultTrackMaxSessions
+	}
+	return &Tracker{
+		sessions:     make(map[string]*trackSession),
+		iouThreshold: iouThreshold,
+		maxMissed:    maxMissed,
+		ttl:          ttl,
+		maxSessions:  maxSessions,
+		now:          time.Now,
+	}
+}
+
+// 検出した顔の矩形をセッションの既存の追跡対象と対応付け、各矩形のトラッキングIDを返す
+// IoUの大きい組み合わせから順に対応付け、対応しない矩形には新しいIDを割り当てる
+func (t *Tracker) Update(sessionID string, rects []image.Rectangle) []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.expire(now)
+
+	session, ok := t.sessions[sessionID]
+	if !ok {
+		if len(t.sessions) >= t.maxSessions {
+			t.evictOldest()
+		}
+		session = &trackSession{nextID: 1}
+		t.sessions[sessionID] = session
+	}
+	session.lastSeen = now
+
+	type pair struct {
+		track, rect int
+		iou         float64
+	}
+	var pairs []pair
+	for i, tr := range session.tracks {
+		for j, rect := range rects {
+			if v := iou(tr.rect, rect); v >= t.iouThreshold {
+				pairs = append(pairs, pair{track: i, rect: j, iou: v})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool {
+		return pairs[a].iou > pairs[b].iou
+	})
+
+	ids := make([]int, len(rects))
+	trackUsed := make([]bool, len(session.tracks))
+	for _, p := range pairs {
+		if trackUsed[p.track] || ids[p.rect] != 0 {
+			continue
+		}
+		trackUsed[p.track] = true
+		ids[p.rect] = session.tracks[p.track].id
+	}
+
+	// 見失った追跡対象は一定フレーム数まで保持する
+	tracks := make([]track, 0, len(session.tracks)+len(rects))
+	for i, tr := range session.tracks {
+		if trackUsed[i] {
+			continue
+		}
+		tr.missed++
+		if tr.missed <= t.maxMissed {
+			tracks = append(tracks, tr)
+		}
+	}
+	for j, rect := range rects {
+		if ids[j] == 0 {
+			ids[j] = session.nextID
+			session.nextID++
+		}
+		tracks = append(tracks, track{id: ids[j], rect: rect})
+	}
+	session.tracks = tracks
+
+	return ids
+}
+
+// セッションの追跡情報を破棄
+func (t *Tracker) Reset(sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sessions, sessionID)
+}
+
+// 最も長く更新のないセッションを破棄
+func (t *Tracker) evictOldest() {
+	var oldestID string
+	var oldest *trackSession
+	for id, session := range t.sessions {
+		if oldest == nil || session.lastSeen.Before(oldest.lastSeen) {
+			oldestID, oldest = id, session
+		}
+	}
+	delete(t.sessions, oldestID)
+}
+
+// 有効期限を過ぎたセッションを破棄
+func (t *Tracker) expire(now time.Time) {
+	for id, session := range t.sessions {
+		if now.Sub(session.lastSeen) > t.ttl {
+			delete(t.sessions, id)
+		}
+	}
+}
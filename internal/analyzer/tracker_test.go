@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker_Update(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker(0.3, 1, time.Minute, 0)
+	tracker.now = func() time.Time { return now }
+
+	ids := tracker.Update("s1", []image.Rectangle{image.Rect(0, 0, 100, 100), image.Rect(300, 0, 400, 100)})
+	assert.Equal(t, []int{1, 2}, ids)
+
+	// 少し移動した顔は同じIDを維持し、新しい顔には新しいIDを割り当てる
+	ids = tracker.Update("s1", []image.Rectangle{image.Rect(310, 5, 410, 105), image.Rect(10, 5, 110, 105), image.Rect(600, 0, 700, 100)})
+	assert.Equal(t, []int{2, 1, 3}, ids)
+
+	// 別のセッションは独立してIDを割り当てる
+	assert.Equal(t, []int{1}, tracker.Update("s2", []image.Rectangle{image.Rect(0, 0, 100, 100)}))
+
+	// 見失ったフレーム数が上限以内なら再び同じIDを割り当てる
+	tracker.Update("s1", []image.Rectangle{image.Rect(10, 5, 110, 105)})
+	ids = tracker.Update("s1", []image.Rectangle{image.Rect(10, 5, 110, 105), image.Rect(600, 0, 700, 100)})
+	assert.Equal(t, []int{1, 3}, ids)
+
+	// 有効期限を過ぎたセッションはIDを振り直す
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, []int{1}, tracker.Update("s1", []image.Rectangle{image.Rect(500, 400, 600, 500)}))
+}
+
+func TestTracker_MaxSessions(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewTracker(0.3, 1, time.Hour, 2)
+	tracker.now = func() time.Time { return now }
+	rect := []image.Rectangle{image.Rect(0, 0, 100, 100)}
+	other := []image.Rectangle{image.Rect(300, 0, 400, 100)}
+
+	tracker.Update("s1", rect)
+	now = now.Add(time.Second)
+	tracker.Update("s2", rect)
+	now = now.Add(time.Second)
+	tracker.Update("s1", rect)
+	now = now.Add(time.Second)
+
+	// 上限に達した場合は最も長く更新のないセッションを破棄する
+	tracker.Update("s3", rect)
+	assert.Len(t, tracker.sessions, 2)
+	assert.Equal(t, []int{1}, tracker.Update("s2", other))
+	assert.Equal(t, []int{2}, tracker.Update("s3", other))
+}
@@ -0,0 +1,176 @@
+package annotation
+
+import (
+	_ "embed"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"strings"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// ラベルの言語
+const (
+	LocaleJa = "ja"
+	LocaleEn = "en"
+)
+
+// ラベルのデフォルト設定
+const (
+	defaultFontSize = 16
+	// 背景の矩形の余白（ピクセル）
+	labelPadding = 3
+)
+
+// 背景の矩形の色（半透明の黒）
+var backgroundColor = color.RGBA{0, 0, 0, 160}
+
+// 日本語を含むラベルを描画するための埋め込みフォント（M+ FONTS）
+//
+//go:embed fonts/mplus-1p-regular.ttf
+var defaultFont []byte
+
+// 感情ごとの日本語のラベル
+var japaneseEmotionLabels = map[string]string{
+	"happy":    "喜び",
+	"sad":      "悲しみ",
+	"angry":    "怒り",
+	"neutral":  "普通",
+	"surprise": "驚き",
+}
+
+// 感情のラベルを指定された言語で返す
+// 英語の場合は感情の値をそのまま返す
+func EmotionLabel(emotion, locale string) string {
+	if locale == LocaleEn {
+		if emotion == "" {
+			return "unknown"
+		}
+		return emotion
+	}
+	if label, ok := japaneseEmotionLabels[emotion]; ok {
+		return label
+	}
+	return "不明"
+}
+
+// Accept-Language ヘッダーからラベルの言語を選択
+// 対応する言語がない場合は空を返す
+func LocaleFromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if config.IsValidAnnotationLocale(lang) {
+			return lang
+		}
+	}
+	return ""
+}
+
+// フォントでラベルを描画する
+// Hersheyフォントでは描画できない日本語を処理済み画像に描画するために使用する
+type Renderer struct {
+	font       *opentype.Font
+	size       float64
+	background bool
+	locale     string
+}
+
+// 設定からラベルの描画に使用するフォントを読み込む
+func New(cfg config.AnnotationConfig) (*Renderer, error) {
+	data := defaultFont
+	if cfg.Font != "" {
+		var err error
+		data, err = os.ReadFile(cfg.Font)
+		if err != nil {
+			return nil, fmt.Errorf("フォントの読み込みに失敗: %w", err)
+		}
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("フォントの解析に失敗: %w", err)
+	}
+
+	r := &Renderer{
+		font:       f,
+		size:       cfg.FontSize,
+		background: cfg.Background,
+		locale:     cfg.Locale,
+	}
+	if r.size == 0 {
+		r.size = defaultFontSize
+	}
+	if r.locale == "" {
+		r.locale = LocaleJa
+	}
+	return r, nil
+}
+
+// ロケールが指定されない場合のラベルの言語を返す
+func (r *Renderer) Locale() string {
+	return r.locale
+}
+
+// テキストを描画した透過画像を作成
+// 背景が有効な場合は半透明の矩形の上にテキストを描画する
+func (r *Renderer) Render(text string, fg color.Color) (*image.RGBA, error) {
+	// opentype.Face は並行して使用できないため描画ごとに作成する
+	face, err := opentype.NewFace(r.font, &opentype.FaceOptions{
+		Size:    r.size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("フォントフェイスの作成に失敗: %w", err)
+	}
+	defer face.Close()
+
+	metrics := face.Metrics()
+	ascent := metrics.Ascent.Ceil()
+	width := font.MeasureString(face, text).Ceil() + 2*labelPadding
+	height := ascent + metrics.Descent.Ceil() + 2*labelPadding
+
+	patch := image.NewRGBA(image.Rect(0, 0, width, height))
+	if r.background {
+		draw.Draw(patch, patch.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+	}
+	d := font.Drawer{
+		Dst:  patch,
+		Src:  image.NewUniform(fg),
+		Face: face,
+		Dot:  fixed.P(labelPadding, labelPadding+ascent),
+	}
+	d.DrawString(text)
+	return patch, nil
+}
+
+// BGRの画素列に透過画像を合成
+// dst は幅 width の連続したBGR画素列で、patch の offset の位置から dst の左上に重ねる
+func BlendBGR(dst []byte, width int, patch *image.RGBA, offset image.Point) {
+	height := len(dst) / (width * 3)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := image.Pt(x, y).Add(offset)
+			if !p.In(patch.Bounds()) {
+				continue
+			}
+			i := patch.PixOffset(p.X, p.Y)
+			// image.RGBA の画素は乗算済みアルファ
+			r, g, b, a := patch.Pix[i], patch.Pix[i+1], patch.Pix[i+2], patch.Pix[i+3]
+			if a == 0 {
+				continue
+			}
+			j := (y*width + x) * 3
+			inv := uint32(255 - a)
+			dst[j] = b + uint8(uint32(dst[j])*inv/255)
+			dst[j+1] = g + uint8(uint32(dst[j+1])*inv/255)
+			dst[j+2] = r + uint8(uint32(dst[j+2])*inv/255)
+		}
+	}
+}
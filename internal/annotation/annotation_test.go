@@ -0,0 +1,114 @@
+package annotation
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmotionLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		emotion string
+		locale  string
+		want    string
+	}{
+		{"日本語", "happy", LocaleJa, "喜び"},
+		{"日本語の不明な感情", "unknown", LocaleJa, "不明"},
+		{"英語", "surprise", LocaleEn, "surprise"},
+		{"英語の空の感情", "", LocaleEn, "unknown"},
+		{"未指定の言語", "sad", "", "悲しみ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EmotionLabel(tt.emotion, tt.locale))
+		})
+	}
+}
+
+func TestLocaleFromAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"日本語", "ja-JP,ja;q=0.9,en;q=0.8", LocaleJa},
+		{"英語", "en-US,en;q=0.9", LocaleEn},
+		{"非対応の言語を飛ばす", "fr-FR, en;q=0.5", LocaleEn},
+		{"非対応の言語のみ", "fr-FR", ""},
+		{"空", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LocaleFromAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	r, err := New(config.AnnotationConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, LocaleJa, r.Locale())
+
+	_, err = New(config.AnnotationConfig{Font: filepath.Join(t.TempDir(), "missing.ttf")})
+	assert.Error(t, err)
+}
+
+func TestRenderer_Render(t *testing.T) {
+	r, err := New(config.AnnotationConfig{FontSize: 20})
+	require.NoError(t, err)
+
+	patch, err := r.Render("喜び", color.RGBA{0, 255, 0, 255})
+	require.NoError(t, err)
+	assert.Greater(t, patch.Bounds().Dx(), 2*labelPadding)
+	assert.Greater(t, patch.Bounds().Dy(), 2*labelPadding)
+
+	// 日本語のグリフが描画されている
+	assert.True(t, hasOpaquePixel(patch), "テキストが描画されていません")
+	// 背景が無効な場合は左上の余白が透明
+	assert.Equal(t, uint8(0), patch.RGBAAt(0, 0).A)
+
+	longer, err := r.Render("喜び喜び", color.RGBA{0, 255, 0, 255})
+	require.NoError(t, err)
+	assert.Greater(t, longer.Bounds().Dx(), patch.Bounds().Dx())
+
+	withBackground, err := New(config.AnnotationConfig{FontSize: 20, Background: true})
+	require.NoError(t, err)
+	boxed, err := withBackground.Render("驚き", color.White)
+	require.NoError(t, err)
+	assert.Equal(t, backgroundColor.A, boxed.RGBAAt(0, 0).A)
+}
+
+func TestBlendBGR(t *testing.T) {
+	patch := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	// 不透明な赤と、乗算済みアルファで半透明の白
+	patch.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	patch.SetRGBA(1, 0, color.RGBA{128, 128, 128, 128})
+
+	dst := []byte{
+		0, 0, 0, 100, 100, 100,
+		0, 0, 0, 0, 0, 0,
+	}
+	BlendBGR(dst, 2, patch, image.Point{})
+
+	assert.Equal(t, []byte{0, 0, 255}, dst[0:3])
+	assert.Equal(t, []byte{177, 177, 177}, dst[3:6])
+	// 透過画像の範囲外の行は変更しない
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0}, dst[6:12])
+}
+
+func hasOpaquePixel(img *image.RGBA) bool {
+	for i := 3; i < len(img.Pix); i += 4 {
+		if img.Pix[i] == 255 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// 対応するレスポンスの形式
+const (
+	MediaTypeJSON     = "application/json"
+	MediaTypeMsgPack  = "application/msgpack"
+	MediaTypeProtobuf = "application/x-protobuf"
+)
+
+// 選択する順序（q値が同じ場合は先頭を優先する）
+var mediaTypes = []string{MediaTypeJSON, MediaTypeMsgPack, MediaTypeProtobuf}
+
+// 同じ形式として扱う別名
+var mediaTypeAliases = map[string]string{
+	"application/x-msgpack": MediaTypeMsgPack,
+	"application/protobuf":  MediaTypeProtobuf,
+}
+
+// Accept ヘッダーからレスポンスの形式を選択
+// 未指定の場合や対応する形式がない場合は JSON を返す
+func Negotiate(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return MediaTypeJSON
+	}
+
+	best, bestQ := MediaTypeJSON, 0.0
+	for _, candidate := range mediaTypes {
+		if q := quality(accept, candidate); q > bestQ {
+			best, bestQ = candidate, q
+		}
+	}
+	return best
+}
+
+// 形式に最も具体的に一致する範囲の q値を返す
+func quality(accept, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if alias, ok := mediaTypeAliases[rangeType]; ok {
+			rangeType = alias
+		}
+
+		var s int
+		switch {
+		case rangeType == mediaType:
+			s = 2
+		case rangeType == "*/*":
+			s = 0
+		case strings.HasSuffix(rangeType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(rangeType, "*")):
+			s = 1
+		default:
+			continue
+		}
+		if s < specificity {
+			continue
+		}
+
+		value := 1.0
+		if v, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				value = parsed
+			}
+		}
+		q, specificity = value, s
+	}
+	return q
+}
+
+// Content-Type ヘッダーの値を返す
+// Protobuf の場合はメッセージの型を messageType パラメータで示す
+func ContentType(mediaType string, v interface{}) string {
+	if m, ok := v.(proto.Message); ok && mediaType == MediaTypeProtobuf {
+		return mediaType + "; messageType=" + string(proto.MessageName(m))
+	}
+	return mediaType
+}
+
+// 指定された形式で値を書き込む
+// MessagePack のフィールド名は JSON のタグに合わせ、Protobuf は proto.Message のみ対応する
+func Encode(w io.Writer, mediaType string, v interface{}) error {
+	switch mediaType {
+	case MediaTypeMsgPack:
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("MessagePackのエンコードに失敗: %w", err)
+		}
+		return nil
+	case MediaTypeProtobuf:
+		m, ok := v.(proto.Message)
+		if !ok {
+			return fmt.Errorf("Protobufに対応していない値です: %T", v)
+		}
+		data, err := proto.Marshal(m)
+		if err != nil {
+			return fmt.Errorf("Protobufのエンコードに失敗: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("レスポンスの書き込みに失敗: %w", err)
+		}
+		return nil
+	default:
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			return fmt.Errorf("JSONのエンコードに失敗: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string
+	}{
+		{name: "未指定", accept: "", want: MediaTypeJSON},
+		{name: "JSON", accept: "application/json", want: MediaTypeJSON},
+		{name: "MessagePack", accept: "application/msgpack", want: MediaTypeMsgPack},
+		{name: "MessagePackの別名", accept: "application/x-msgpack", want: MediaTypeMsgPack},
+		{name: "Protobuf", accept: "application/x-protobuf", want: MediaTypeProtobuf},
+		{name: "Protobufの別名", accept: "application/protobuf", want: MediaTypeProtobuf},
+		{name: "q値の高い形式", accept: "application/json;q=0.5, application/msgpack", want: MediaTypeMsgPack},
+		{name: "q値が同じ場合はJSON", accept: "application/msgpack, application/json", want: MediaTypeJSON},
+		{name: "ワイルドカード", accept: "*/*", want: MediaTypeJSON},
+		{name: "具体的な指定を優先", accept: "application/*, application/json;q=0", want: MediaTypeMsgPack},
+		{name: "除外した形式", accept: "application/msgpack;q=0", want: MediaTypeJSON},
+		{name: "対応していない形式", accept: "text/html", want: MediaTypeJSON},
+		{name: "不正な値", accept: ";;;", want: MediaTypeJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Negotiate(tt.accept))
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, MediaTypeMsgPack, ContentType(MediaTypeMsgPack, struct{}{}))
+	assert.Equal(t, "application/x-protobuf; messageType=faceemotion.v1.Problem",
+		ContentType(MediaTypeProtobuf, &faceemotionv1.Problem{}))
+}
+
+func TestEncode(t *testing.T) {
+	type response struct {
+		Emotion string `json:"emotion"`
+		Image   string `json:"image" msgpack:"-"`
+		Data    []byte `json:"-" msgpack:"image,omitempty"`
+	}
+	v := response{Emotion: "happy", Image: "data:image/jpeg;base64,anBlZw==", Data: []byte("jpeg")}
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, MediaTypeJSON, v))
+		assert.JSONEq(t, `{"emotion":"happy","image":"data:image/jpeg;base64,anBlZw=="}`, buf.String())
+	})
+
+	t.Run("MessagePackはJSONのフィールド名と画像のバイト列", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, MediaTypeMsgPack, v))
+		var got map[string]interface{}
+		require.NoError(t, msgpack.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, map[string]interface{}{"emotion": "happy", "image": []byte("jpeg")}, got)
+	})
+
+	t.Run("Protobuf", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, MediaTypeProtobuf, &faceemotionv1.Problem{Code: "INVALID_IMAGE", Status: 400}))
+		var got faceemotionv1.Problem
+		require.NoError(t, proto.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, "INVALID_IMAGE", got.GetCode())
+		assert.Equal(t, int32(400), got.GetStatus())
+	})
+
+	t.Run("Protobufに対応していない値", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.Error(t, Encode(&buf, MediaTypeProtobuf, v))
+	})
+}
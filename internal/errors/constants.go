@@ -17,6 +17,23 @@ const (
 	ErrCodeUnauthorized      = "UNAUTHORIZED"
 	ErrCodeForbidden         = "FORBIDDEN"
 	ErrCodeNotFound          = "NOT_FOUND"
+	ErrCodeMethodNotAllowed  = "METHOD_NOT_ALLOWED"
+	ErrCodeConflict          = "CONFLICT"
+	// 対応していない形式の画像やリクエスト
+	ErrCodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	// 画像から顔を1つだけ検出する必要がある処理のエラー
+	ErrCodeFaceNotDetected = "FACE_NOT_DETECTED"
+	ErrCodeMultipleFaces   = "MULTIPLE_FACES"
+	// URLで指定された画像の取得のエラー
+	ErrCodeInvalidURL               = "INVALID_URL"
+	ErrCodeURLSchemeNotAllowed      = "URL_SCHEME_NOT_ALLOWED"
+	ErrCodeURLHostNotAllowed        = "URL_HOST_NOT_ALLOWED"
+	ErrCodeURLAddressBlocked        = "URL_ADDRESS_BLOCKED"
+	ErrCodeURLTooManyRedirects      = "URL_TOO_MANY_REDIRECTS"
+	ErrCodeURLContentTooLarge       = "URL_CONTENT_TOO_LARGE"
+	ErrCodeURLContentTypeNotAllowed = "URL_CONTENT_TYPE_NOT_ALLOWED"
+	ErrCodeURLFetchFailed           = "URL_FETCH_FAILED"
+	ErrCodeURLFetchTimeout          = "URL_FETCH_TIMEOUT"
 
 	// 処理エラー (5xx)
 	ErrCodeInternalError     = "INTERNAL_ERROR"
@@ -66,24 +83,39 @@ const (
 
 // HTTPステータスコードとエラーコードのマッピング
 var statusCodeMap = map[string]int{
-	ErrCodeInvalidInput:      http.StatusBadRequest,
-	ErrCodeInvalidImage:      http.StatusBadRequest,
-	ErrCodeInvalidRequest:    http.StatusBadRequest,
-	ErrCodeInvalidToken:      http.StatusUnauthorized,
-	ErrCodeRequestTooLarge:   http.StatusRequestEntityTooLarge,
-	ErrCodeRateLimitExceeded: http.StatusTooManyRequests,
-	ErrCodeUnauthorized:      http.StatusUnauthorized,
-	ErrCodeForbidden:         http.StatusForbidden,
-	ErrCodeNotFound:          http.StatusNotFound,
-	ErrCodeInternalError:     http.StatusInternalServerError,
-	ErrCodeDatabaseError:     http.StatusInternalServerError,
-	ErrCodeOpenCVError:       http.StatusInternalServerError,
-	ErrCodeResourceExhausted: http.StatusServiceUnavailable,
-	ErrCodeTimeout:           http.StatusGatewayTimeout,
-	ErrCodeUnavailable:       http.StatusServiceUnavailable,
-	ErrCodeCacheError:        http.StatusInternalServerError,
-	ErrCodeAWSError:          http.StatusInternalServerError,
-	ErrCodeSecurityError:     http.StatusForbidden,
+	ErrCodeInvalidInput:             http.StatusBadRequest,
+	ErrCodeInvalidImage:             http.StatusBadRequest,
+	ErrCodeInvalidRequest:           http.StatusBadRequest,
+	ErrCodeInvalidToken:             http.StatusUnauthorized,
+	ErrCodeRequestTooLarge:          http.StatusRequestEntityTooLarge,
+	ErrCodeRateLimitExceeded:        http.StatusTooManyRequests,
+	ErrCodeUnauthorized:             http.StatusUnauthorized,
+	ErrCodeForbidden:                http.StatusForbidden,
+	ErrCodeNotFound:                 http.StatusNotFound,
+	ErrCodeMethodNotAllowed:         http.StatusMethodNotAllowed,
+	ErrCodeConflict:                 http.StatusConflict,
+	ErrCodeUnsupportedMediaType:     http.StatusUnsupportedMediaType,
+	ErrCodeFaceNotDetected:          http.StatusUnprocessableEntity,
+	ErrCodeMultipleFaces:            http.StatusUnprocessableEntity,
+	ErrCodeInvalidURL:               http.StatusBadRequest,
+	ErrCodeURLSchemeNotAllowed:      http.StatusBadRequest,
+	ErrCodeURLHostNotAllowed:        http.StatusBadRequest,
+	ErrCodeURLAddressBlocked:        http.StatusBadRequest,
+	ErrCodeURLTooManyRedirects:      http.StatusBadRequest,
+	ErrCodeURLContentTooLarge:       http.StatusRequestEntityTooLarge,
+	ErrCodeURLContentTypeNotAllowed: http.StatusUnsupportedMediaType,
+	ErrCodeURLFetchFailed:           http.StatusBadGateway,
+	ErrCodeURLFetchTimeout:          http.StatusGatewayTimeout,
+	ErrCodeInternalError:            http.StatusInternalServerError,
+	ErrCodeDatabaseError:            http.StatusInternalServerError,
+	ErrCodeOpenCVError:              http.StatusInternalServerError,
+	ErrCodeResourceExhausted:        http.StatusServiceUnavailable,
+	ErrCodeTimeout:                  http.StatusGatewayTimeout,
+	ErrCodeUnavailable:              http.StatusServiceUnavailable,
+	ErrCodeCacheError:               http.StatusInternalServerError,
+	ErrCodeAWSError:                 http.StatusInternalServerError,
+	ErrCodeCSRFError:                http.StatusForbidden,
+	ErrCodeSecurityError:            http.StatusForbidden,
 }
 
 var (
@@ -91,6 +123,14 @@ var (
 	ErrKeyNotFound = errors.New("key not found")
 	// 値のサイズがキャッシュの最大サイズを超えた場合のエラー
 	ErrSizeExceeded = errors.New("value size exceeds cache max size")
+	// ギャラリーに登録されていない人物を指定した場合のエラー
+	ErrPersonNotFound = errors.New("person not found")
+	// 存在しない、または保持期間を過ぎたジョブを指定した場合のエラー
+	ErrJobNotFound = errors.New("job not found")
+	// 終了したジョブをキャンセルしようとした場合のエラー
+	ErrJobFinished = errors.New("job already finished")
+	// 待機中と実行中のジョブ数が上限に達している場合のエラー
+	ErrJobQueueFull = errors.New("too many pending jobs")
 )
 
 // エラーコードに対応するHTTPステータスコードを返す
@@ -74,14 +74,13 @@ func (e *Error) WithStack() *Error {
 	stack := make([]Frame, 0, n)
 	for {
 		frame, more := frames.Next()
-		if !strings.Contains(frame.File, "github.com/okamyuji") {
-			continue
+		if strings.Contains(frame.File, "github.com/okamyuji") {
+			stack = append(stack, Frame{
+				File:     frame.File,
+				Line:     frame.Line,
+				Function: frame.Function,
+			})
 		}
-		stack = append(stack, Frame{
-			File:     frame.File,
-			Line:     frame.Line,
-			Function: frame.Function,
-		})
 		if !more {
 			break
 		}
@@ -90,6 +89,59 @@ func (e *Error) WithStack() *Error {
 	return e
 }
 
+// エラーコードを設定
+func (e *Error) WithCode(code string) *Error {
+	e.Code = code
+	return e
+}
+
+// エラーに対応するHTTPステータスコードを返す
+func (e *Error) StatusCode() int {
+	return GetStatusCode(e.code())
+}
+
+// エラーコードを返す
+// 未設定の場合はエラーの種類から決める
+func (e *Error) code() string {
+	if e.Code != "" {
+		return e.Code
+	}
+	switch e.Type {
+	case ErrorTypeValidation:
+		return ErrCodeInvalidInput
+	case ErrorTypeSecurity:
+		return ErrCodeSecurityError
+	case ErrorTypeOpenCV:
+		return ErrCodeOpenCVError
+	case ErrorTypeAWS:
+		return ErrCodeAWSError
+	case ErrorTypeResource:
+		return ErrCodeResourceExhausted
+	default:
+		return ErrCodeInternalError
+	}
+}
+
+// エラーコードに対応するエラーの種類を返す
+func typeForCode(code string) ErrorType {
+	switch code {
+	case ErrCodeInvalidToken, ErrCodeUnauthorized, ErrCodeForbidden, ErrCodeRateLimitExceeded,
+		ErrCodeCSRFError, ErrCodeXSSError, ErrCodeSecurityError,
+		ErrCodeURLSchemeNotAllowed, ErrCodeURLHostNotAllowed, ErrCodeURLAddressBlocked:
+		return ErrorTypeSecurity
+	case ErrCodeOpenCVError:
+		return ErrorTypeOpenCV
+	case ErrCodeAWSError, ErrCodeCloudWatchError, ErrCodeS3Error:
+		return ErrorTypeAWS
+	case ErrCodeResourceExhausted, ErrCodeTimeout, ErrCodeUnavailable:
+		return ErrorTypeResource
+	}
+	if status := GetStatusCode(code); status >= 400 && status < 500 {
+		return ErrorTypeValidation
+	}
+	return ErrorTypeUnexpected
+}
+
 // 新しいエラーを作成
 func NewError(errType ErrorType, message string, err error) *Error {
 	e := &Error{
@@ -134,6 +186,14 @@ func ResourceError(message string, err error) *Error {
 	return NewError(ErrorTypeResource, message, err)
 }
 
+// エラーコードを指定してエラーを作成
+// エラーの種類はエラーコードから決める
+func CodeError(code, message string, err error) *Error {
+	e := NewError(typeForCode(code), message, err)
+	e.Code = code
+	return e
+}
+
 // AWSエラーを作成
 func AWSError(service, operation string, err error) *Error {
 	message := fmt.Sprintf("AWS %s: %s failed", service, operation)
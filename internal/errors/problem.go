@@ -0,0 +1,170 @@
+package errors
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
+)
+
+// RFC 7807 のエラーレスポンスのContent-Type
+const ProblemContentType = "application/problem+json"
+
+// リクエストIDを受け渡すヘッダー
+const RequestIDHeader = "X-Request-ID"
+
+// problem の type に使用するURIの接頭辞
+const problemTypePrefix = "urn:face-emotion-analyzer:problem:"
+
+// タイトルの言語
+const (
+	localeJa = "ja"
+	localeEn = "en"
+)
+
+// RFC 7807 のエラーレスポンス
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	Code      string `json:"code"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// エラーコードごとのタイトル
+var problemTitles = map[string]map[string]string{
+	ErrCodeInvalidInput:             {localeJa: "不正な入力です", localeEn: "Invalid input"},
+	ErrCodeInvalidImage:             {localeJa: "不正な画像です", localeEn: "Invalid image"},
+	ErrCodeInvalidRequest:           {localeJa: "不正なリクエストです", localeEn: "Invalid request"},
+	ErrCodeInvalidToken:             {localeJa: "無効なトークンです", localeEn: "Invalid token"},
+	ErrCodeRequestTooLarge:          {localeJa: "リクエストサイズが大きすぎます", localeEn: "Request too large"},
+	ErrCodeRateLimitExceeded:        {localeJa: "レート制限を超過しました", localeEn: "Rate limit exceeded"},
+	ErrCodeUnauthorized:             {localeJa: "認証が必要です", localeEn: "Unauthorized"},
+	ErrCodeForbidden:                {localeJa: "アクセスが拒否されました", localeEn: "Forbidden"},
+	ErrCodeNotFound:                 {localeJa: "リソースが見つかりません", localeEn: "Not found"},
+	ErrCodeMethodNotAllowed:         {localeJa: "メソッドは許可されていません", localeEn: "Method not allowed"},
+	ErrCodeConflict:                 {localeJa: "リソースの状態と競合しています", localeEn: "Conflict"},
+	ErrCodeFaceNotDetected:          {localeJa: "顔が検出されませんでした", localeEn: "No face detected"},
+	ErrCodeMultipleFaces:            {localeJa: "複数の顔が検出されました", localeEn: "Multiple faces detected"},
+	ErrCodeInvalidURL:               {localeJa: "不正なURLです", localeEn: "Invalid URL"},
+	ErrCodeURLSchemeNotAllowed:      {localeJa: "許可されていないURLのスキームです", localeEn: "URL scheme not allowed"},
+	ErrCodeURLHostNotAllowed:        {localeJa: "許可されていないホストです", localeEn: "URL host not allowed"},
+	ErrCodeURLAddressBlocked:        {localeJa: "接続が禁止されているアドレスです", localeEn: "URL address blocked"},
+	ErrCodeURLTooManyRedirects:      {localeJa: "リダイレクトが多すぎます", localeEn: "Too many redirects"},
+	ErrCodeURLContentTooLarge:       {localeJa: "取得した画像が大きすぎます", localeEn: "URL content too large"},
+	ErrCodeURLContentTypeNotAllowed: {localeJa: "取得した内容の形式に対応していません", localeEn: "URL content type not allowed"},
+	ErrCodeURLFetchFailed:           {localeJa: "画像の取得に失敗しました", localeEn: "Failed to fetch URL"},
+	ErrCodeURLFetchTimeout:          {localeJa: "画像の取得がタイムアウトしました", localeEn: "URL fetch timed out"},
+	ErrCodeInternalError:            {localeJa: "内部エラーが発生しました", localeEn: "Internal error"},
+	ErrCodeDatabaseError:            {localeJa: "データベースエラーが発生しました", localeEn: "Database error"},
+	ErrCodeOpenCVError:              {localeJa: "画像処理エラーが発生しました", localeEn: "Image processing error"},
+	ErrCodeResourceExhausted:        {localeJa: "リソースが枯渇しました", localeEn: "Resource exhausted"},
+	ErrCodeTimeout:                  {localeJa: "処理がタイムアウトしました", localeEn: "Timeout"},
+	ErrCodeUnavailable:              {localeJa: "サービスが利用できません", localeEn: "Service unavailable"},
+	ErrCodeCacheError:               {localeJa: "キャッシュエラーが発生しました", localeEn: "Cache error"},
+	ErrCodeAWSError:                 {localeJa: "AWSエラーが発生しました", localeEn: "AWS error"},
+	ErrCodeCSRFError:                {localeJa: "CSRFトークンが無効です", localeEn: "Invalid CSRF token"},
+	ErrCodeSecurityError:            {localeJa: "セキュリティエラーが発生しました", localeEn: "Security error"},
+	ErrCodeUnsupportedMediaType:     {localeJa: "対応していない形式です", localeEn: "Unsupported media type"},
+}
+
+// エラーコードのタイトルを指定された言語で返す
+// 対応する言語がない場合は日本語を返す
+func Title(code, locale string) string {
+	titles, ok := problemTitles[code]
+	if !ok {
+		titles = problemTitles[ErrCodeInternalError]
+	}
+	if title, ok := titles[locale]; ok {
+		return title
+	}
+	return titles[localeJa]
+}
+
+// エラーから problem を作成
+// Error 以外のエラーは内部エラーとして扱い、詳細を含めない
+func NewProblem(err error, locale string) Problem {
+	code := ErrCodeInternalError
+	detail := MsgInternalError
+	var requestID string
+
+	var e *Error
+	if As(err, &e) {
+		code = e.code()
+		detail = e.Message
+		requestID = e.RequestID
+	}
+	return Problem{
+		Type:      problemTypePrefix + strings.ToLower(code),
+		Title:     Title(code, locale),
+		Status:    GetStatusCode(code),
+		Detail:    detail,
+		Code:      code,
+		RequestID: requestID,
+	}
+}
+
+// エラーを application/problem+json で送信
+// リクエストIDはミドルウェアが設定したレスポンスヘッダーから取得する
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	p := NewProblem(err, problemLocale(r.Header.Get("Accept-Language")))
+	p.Instance = r.URL.Path
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get(RequestIDHeader)
+	}
+
+	if p.Status >= http.StatusInternalServerError {
+		slog.Error("リクエストの処理に失敗",
+			"code", p.Code,
+			"path", r.URL.Path,
+			"request_id", p.RequestID,
+			"error", err)
+	}
+
+	// 成功時のレスポンスと同じく Accept ヘッダーに応じた形式で返す
+	mediaType := codec.Negotiate(r.Header.Get("Accept"))
+	var body interface{} = p
+	contentType := ProblemContentType
+	switch mediaType {
+	case codec.MediaTypeMsgPack:
+		contentType = mediaType
+	case codec.MediaTypeProtobuf:
+		body = p.proto()
+		contentType = codec.ContentType(mediaType, body)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(p.Status)
+	if err := codec.Encode(w, mediaType, body); err != nil {
+		slog.Error("エラーレスポンスの送信に失敗", "error", err)
+	}
+}
+
+func (p Problem) proto() *faceemotionv1.Problem {
+	return &faceemotionv1.Problem{
+		Type:      p.Type,
+		Title:     p.Title,
+		Status:    int32(p.Status),
+		Detail:    p.Detail,
+		Instance:  p.Instance,
+		Code:      p.Code,
+		RequestId: p.RequestID,
+	}
+}
+
+// Accept-Language ヘッダーからタイトルの言語を選択
+func problemLocale(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if lang == localeJa || lang == localeEn {
+			return lang
+		}
+	}
+	return localeJa
+}
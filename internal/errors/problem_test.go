@@ -0,0 +1,204 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNewProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		locale     string
+		wantCode   string
+		wantStatus int
+		wantTitle  string
+		wantDetail string
+	}{
+		{
+			name:       "エラーコード付きのエラー",
+			err:        CodeError(ErrCodeNotFound, "session not found", nil),
+			locale:     "ja",
+			wantCode:   ErrCodeNotFound,
+			wantStatus: http.StatusNotFound,
+			wantTitle:  "リソースが見つかりません",
+			wantDetail: "session not found",
+		},
+		{
+			name:       "英語のタイトル",
+			err:        CodeError(ErrCodeRequestTooLarge, "image size exceeds limit", nil),
+			locale:     "en",
+			wantCode:   ErrCodeRequestTooLarge,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantTitle:  "Request too large",
+			wantDetail: "image size exceeds limit",
+		},
+		{
+			name:       "エラーコードなしの場合は種類から決める",
+			err:        ValidationError("不正な入力です", nil),
+			locale:     "ja",
+			wantCode:   ErrCodeInvalidInput,
+			wantStatus: http.StatusBadRequest,
+			wantTitle:  "不正な入力です",
+			wantDetail: "不正な入力です",
+		},
+		{
+			name:       "ラップされたエラー",
+			err:        fmt.Errorf("分析に失敗: %w", CodeError(ErrCodeInvalidImage, "無効な画像データです", nil)),
+			locale:     "ja",
+			wantCode:   ErrCodeInvalidImage,
+			wantStatus: http.StatusBadRequest,
+			wantTitle:  "不正な画像です",
+			wantDetail: "無効な画像データです",
+		},
+		{
+			name:       "型のないエラーは詳細を含めない",
+			err:        fmt.Errorf("secret internal state"),
+			locale:     "ja",
+			wantCode:   ErrCodeInternalError,
+			wantStatus: http.StatusInternalServerError,
+			wantTitle:  "内部エラーが発生しました",
+			wantDetail: MsgInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProblem(tt.err, tt.locale)
+			assert.Equal(t, tt.wantCode, p.Code)
+			assert.Equal(t, tt.wantStatus, p.Status)
+			assert.Equal(t, tt.wantTitle, p.Title)
+			assert.Equal(t, tt.wantDetail, p.Detail)
+			assert.NotEmpty(t, p.Type)
+		})
+	}
+}
+
+func TestCodeError(t *testing.T) {
+	tests := []struct {
+		code     string
+		wantType ErrorType
+	}{
+		{ErrCodeInvalidRequest, ErrorTypeValidation},
+		{ErrCodeCSRFError, ErrorTypeSecurity},
+		{ErrCodeRateLimitExceeded, ErrorTypeSecurity},
+		{ErrCodeOpenCVError, ErrorTypeOpenCV},
+		{ErrCodeUnavailable, ErrorTypeResource},
+		{ErrCodeInternalError, ErrorTypeUnexpected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			e := CodeError(tt.code, "message", nil)
+			assert.Equal(t, tt.wantType, e.Type)
+			assert.Equal(t, GetStatusCode(tt.code), e.StatusCode())
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/sessions/s1", nil)
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	rec := httptest.NewRecorder()
+	rec.Header().Set(RequestIDHeader, "req-1")
+
+	WriteProblem(rec, req, CodeError(ErrCodeNotFound, "session not found", nil))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	var p Problem
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+	assert.Equal(t, Problem{
+		Type:      "urn:face-emotion-analyzer:problem:not_found",
+		Title:     "Not found",
+		Status:    http.StatusNotFound,
+		Detail:    "session not found",
+		Instance:  "/sessions/s1",
+		Code:      ErrCodeNotFound,
+		RequestID: "req-1",
+	}, p)
+}
+
+func TestWriteProblem_Negotiation(t *testing.T) {
+	want := Problem{
+		Type:     "urn:face-emotion-analyzer:problem:invalid_image",
+		Title:    "不正な画像です",
+		Status:   http.StatusBadRequest,
+		Detail:   "invalid image data",
+		Instance: "/api/v1/analyze",
+		Code:     ErrCodeInvalidImage,
+	}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		decode          func(t *testing.T, body []byte) Problem
+	}{
+		{
+			name:            "MessagePack",
+			accept:          codec.MediaTypeMsgPack,
+			wantContentType: codec.MediaTypeMsgPack,
+			decode: func(t *testing.T, body []byte) Problem {
+				dec := msgpack.NewDecoder(bytes.NewReader(body))
+				dec.SetCustomStructTag("json")
+				var p Problem
+				require.NoError(t, dec.Decode(&p))
+				return p
+			},
+		},
+		{
+			name:            "Protobuf",
+			accept:          "application/x-protobuf, application/json;q=0.5",
+			wantContentType: `application/x-protobuf; messageType=faceemotion.v1.Problem`,
+			decode: func(t *testing.T, body []byte) Problem {
+				var m faceemotionv1.Problem
+				require.NoError(t, proto.Unmarshal(body, &m))
+				return Problem{
+					Type:     m.GetType(),
+					Title:    m.GetTitle(),
+					Status:   int(m.GetStatus()),
+					Detail:   m.GetDetail(),
+					Instance: m.GetInstance(),
+					Code:     m.GetCode(),
+				}
+			},
+		},
+		{
+			name:            "対応していない形式はJSON",
+			accept:          "text/html",
+			wantContentType: ProblemContentType,
+			decode: func(t *testing.T, body []byte) Problem {
+				var p Problem
+				require.NoError(t, json.Unmarshal(body, &p))
+				return p
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/analyze", nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+
+			WriteProblem(rec, req, CodeError(ErrCodeInvalidImage, "invalid image data", nil))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+			assert.Equal(t, want, tt.decode(t, rec.Body.Bytes()))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package evaluation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ラベル付きの評価用サンプル
+type Sample struct {
+	Path  string
+	Label string
+}
+
+// 評価対象とする画像の拡張子
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+}
+
+// データセットを読み込む
+// pathがCSVファイルの場合はマニフェストとして、ディレクトリの場合はラベルごとのサブフォルダとして扱う
+func LoadDataset(path string) ([]Sample, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("データセットの参照に失敗: %w", err)
+	}
+
+	if info.IsDir() {
+		return LoadDirectory(path)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return LoadManifest(path)
+	}
+	return nil, fmt.Errorf("不正なデータセット形式です: %s", path)
+}
+
+// ラベルごとのサブフォルダからサンプルを読み込む
+func LoadDirectory(dir string) ([]Sample, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("データセットディレクトリの読み込みに失敗: %w", err)
+	}
+
+	var samples []Sample
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		label := NormalizeLabel(entry.Name())
+		labelDir := filepath.Join(dir, entry.Name())
+		files, err := os.ReadDir(labelDir)
+		if err != nil {
+			return nil, fmt.Errorf("ラベルディレクトリの読み込みに失敗: %w", err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || !imageExtensions[strings.ToLower(filepath.Ext(file.Name()))] {
+				continue
+			}
+			samples = append(samples, Sample{
+				Path:  filepath.Join(labelDir, file.Name()),
+				Label: label,
+			})
+		}
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("評価用の画像が見つかりません: %s", dir)
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Path < samples[j].Path
+	})
+	return samples, nil
+}
+
+// CSVマニフェスト（path,label）からサンプルを読み込む
+// 相対パスはマニフェストのディレクトリを基準に解決する
+func LoadManifest(path string) ([]Sample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("マニフェストのオープンに失敗: %w", err)
+	}
+	defer file.Close()
+
+	baseDir := filepath.Dir(path)
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var samples []Sample
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("マニフェストのパースに失敗: %w", err)
+		}
+
+		// 空行とヘッダー行はスキップ
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "path") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("マニフェストの%d行目に列が不足しています", line)
+		}
+
+		imgPath := strings.TrimSpace(record[0])
+		if !filepath.IsAbs(imgPath) {
+			imgPath = filepath.Join(baseDir, imgPath)
+		}
+		samples = append(samples, Sample{
+			Path:  imgPath,
+			Label: NormalizeLabel(record[1]),
+		})
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("マニフェストにサンプルがありません: %s", path)
+	}
+	return samples, nil
+}
+
+// ラベル表記を正規化
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
@@ -0,0 +1,85 @@
+package evaluation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Happy/a.jpg":   "a",
+		"Happy/b.PNG":   "b",
+		"sad/c.jpeg":    "c",
+		"sad/notes.txt": "ignored",
+		".hidden/d.jpg": "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	samples, err := LoadDataset(dir)
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+
+	labels := map[string]int{}
+	for _, s := range samples {
+		labels[s.Label]++
+	}
+	assert.Equal(t, map[string]int{"happy": 2, "sad": 1}, labels)
+}
+
+func TestLoadDirectory_Empty(t *testing.T) {
+	_, err := LoadDirectory(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "labels.csv")
+	content := "path,label\nimages/a.jpg, Happy\n\n/abs/b.jpg,sad\n"
+	require.NoError(t, os.WriteFile(manifest, []byte(content), 0644))
+
+	samples, err := LoadDataset(manifest)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Sample{
+		{Path: filepath.Join(dir, "images/a.jpg"), Label: "happy"},
+		{Path: "/abs/b.jpg", Label: "sad"},
+	}, samples)
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"列不足", "a.jpg\n"},
+		{"ヘッダーのみ", "path,label\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := filepath.Join(dir, "manifest.csv")
+			require.NoError(t, os.WriteFile(manifest, []byte(tt.content), 0644))
+			_, err := LoadManifest(manifest)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadDataset_UnsupportedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0644))
+
+	_, err := LoadDataset(path)
+	assert.Error(t, err)
+}
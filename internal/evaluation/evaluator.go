@@ -0,0 +1,58 @@
+package evaluation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// 1枚の画像に対する予測を行う関数
+// 主要な顔の感情ラベルと検出された顔の数を返す
+type PredictFunc func(imgData []byte) (label string, faces int, err error)
+
+// データセット全体に対して予測を実行し、評価レポートを作成
+func Evaluate(ctx context.Context, samples []Sample, predict PredictFunc) (Report, []Prediction, error) {
+	if predict == nil {
+		return Report{}, nil, fmt.Errorf("予測関数が指定されていません")
+	}
+
+	predictions := make([]Prediction, 0, len(samples))
+	for _, sample := range samples {
+		select {
+		case <-ctx.Done():
+			return Report{}, nil, ctx.Err()
+		default:
+		}
+
+		predictions = append(predictions, predictSample(sample, predict))
+	}
+
+	return ComputeReport(predictions), predictions, nil
+}
+
+// 1サンプルの予測を実行
+func predictSample(sample Sample, predict PredictFunc) Prediction {
+	p := Prediction{
+		Path:  sample.Path,
+		Label: sample.Label,
+	}
+
+	data, err := os.ReadFile(sample.Path)
+	if err != nil {
+		p.Err = fmt.Errorf("画像の読み込みに失敗: %w", err)
+		return p
+	}
+
+	start := time.Now()
+	label, faces, err := predict(data)
+	p.Latency = time.Since(start)
+	if err != nil {
+		p.Err = err
+		return p
+	}
+
+	p.Predicted = NormalizeLabel(label)
+	p.FaceDetected = faces > 0
+	return p
+}
@@ -0,0 +1,214 @@
+package evaluation
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// 顔が検出されなかった場合に予測ラベルとして使用する値
+const LabelUnknown = "unknown"
+
+// 1サンプル分の予測結果
+type Prediction struct {
+	Path         string
+	Label        string
+	Predicted    string
+	FaceDetected bool
+	Latency      time.Duration
+	Err          error
+}
+
+// クラスごとの評価指標
+type ClassMetrics struct {
+	Label     string  `json:"label"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int     `json:"support"`
+}
+
+// 混同行列（行が正解ラベル、列が予測ラベル）
+type ConfusionMatrix struct {
+	Labels []string `json:"labels"`
+	Matrix [][]int  `json:"matrix"`
+}
+
+// レイテンシのパーセンタイル（ミリ秒）
+type LatencyStats struct {
+	Mean float64 `json:"meanMs"`
+	P50  float64 `json:"p50Ms"`
+	P90  float64 `json:"p90Ms"`
+	P95  float64 `json:"p95Ms"`
+	P99  float64 `json:"p99Ms"`
+	Max  float64 `json:"maxMs"`
+}
+
+// 評価結果のレポート
+type Report struct {
+	Total         int             `json:"total"`
+	Evaluated     int             `json:"evaluated"`
+	Errors        int             `json:"errors"`
+	Accuracy      float64         `json:"accuracy"`
+	MacroF1       float64         `json:"macroF1"`
+	FaceMissRate  float64         `json:"faceDetectionMissRate"`
+	Classes       []ClassMetrics  `json:"classes"`
+	Confusion     ConfusionMatrix `json:"confusionMatrix"`
+	Latency       LatencyStats    `json:"latency"`
+	FailedSamples []string        `json:"failedSamples,omitempty"`
+}
+
+// 予測結果から評価レポートを作成
+// 顔が検出されなかったサンプルは unknown と予測したものとして扱う
+func ComputeReport(predictions []Prediction) Report {
+	report := Report{Total: len(predictions)}
+
+	var latencies []time.Duration
+	var misses, correct int
+	labelSet := make(map[string]bool)
+	evaluated := make([]Prediction, 0, len(predictions))
+
+	for _, p := range predictions {
+		if p.Err != nil {
+			report.Errors++
+			report.FailedSamples = append(report.FailedSamples, p.Path)
+			continue
+		}
+		if !p.FaceDetected {
+			misses++
+			p.Predicted = LabelUnknown
+		}
+		if p.Predicted == p.Label {
+			correct++
+		}
+		labelSet[p.Label] = true
+		labelSet[p.Predicted] = true
+		latencies = append(latencies, p.Latency)
+		evaluated = append(evaluated, p)
+	}
+
+	report.Evaluated = len(evaluated)
+	if report.Evaluated == 0 {
+		report.Classes = []ClassMetrics{}
+		report.Confusion = ConfusionMatrix{Labels: []string{}, Matrix: [][]int{}}
+		return report
+	}
+
+	report.Accuracy = float64(correct) / float64(report.Evaluated)
+	report.FaceMissRate = float64(misses) / float64(report.Evaluated)
+	report.Confusion = buildConfusionMatrix(evaluated, labelSet)
+	report.Classes, report.MacroF1 = classMetrics(report.Confusion)
+	report.Latency = computeLatencyStats(latencies)
+
+	return report
+}
+
+// 混同行列を作成
+func buildConfusionMatrix(predictions []Prediction, labelSet map[string]bool) ConfusionMatrix {
+	labels := make([]string, 0, len(labelSet))
+	for label := range labelSet {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	index := make(map[string]int, len(labels))
+	for i, label := range labels {
+		index[label] = i
+	}
+
+	matrix := make([][]int, len(labels))
+	for i := range matrix {
+		matrix[i] = make([]int, len(labels))
+	}
+	for _, p := range predictions {
+		matrix[index[p.Label]][index[p.Predicted]]++
+	}
+
+	return ConfusionMatrix{Labels: labels, Matrix: matrix}
+}
+
+// 混同行列からクラスごとの指標とマクロF1を計算
+// 正解ラベルとして一度も出現しないクラス（unknownなど）はマクロ平均に含めない
+func classMetrics(cm ConfusionMatrix) ([]ClassMetrics, float64) {
+	classes := make([]ClassMetrics, 0, len(cm.Labels))
+	var f1Sum float64
+	var supported int
+
+	for i, label := range cm.Labels {
+		var tp, predicted, support int
+		tp = cm.Matrix[i][i]
+		for j := range cm.Labels {
+			predicted += cm.Matrix[j][i]
+			support += cm.Matrix[i][j]
+		}
+
+		m := ClassMetrics{
+			Label:     label,
+			Precision: safeDiv(tp, predicted),
+			Recall:    safeDiv(tp, support),
+			Support:   support,
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		classes = append(classes, m)
+
+		if support > 0 {
+			f1Sum += m.F1
+			supported++
+		}
+	}
+
+	if supported == 0 {
+		return classes, 0
+	}
+	return classes, f1Sum / float64(supported)
+}
+
+// レイテンシの統計値を計算
+func computeLatencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+
+	return LatencyStats{
+		Mean: toMillis(total / time.Duration(len(sorted))),
+		P50:  toMillis(percentile(sorted, 50)),
+		P90:  toMillis(percentile(sorted, 90)),
+		P95:  toMillis(percentile(sorted, 95)),
+		P99:  toMillis(percentile(sorted, 99)),
+		Max:  toMillis(sorted[len(sorted)-1]),
+	}
+}
+
+// ソート済みの値からnearest-rank法でパーセンタイルを求める
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func safeDiv(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,138 @@
+package evaluation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeReport(t *testing.T) {
+	predictions := []Prediction{
+		{Label: "happy", Predicted: "happy", FaceDetected: true, Latency: 10 * time.Millisecond},
+		{Label: "happy", Predicted: "sad", FaceDetected: true, Latency: 20 * time.Millisecond},
+		{Label: "sad", Predicted: "sad", FaceDetected: true, Latency: 30 * time.Millisecond},
+		{Label: "sad", FaceDetected: false, Latency: 40 * time.Millisecond},
+		{Label: "happy", Path: "broken.jpg", Err: errors.New("decode error")},
+	}
+
+	report := ComputeReport(predictions)
+
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 4, report.Evaluated)
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, []string{"broken.jpg"}, report.FailedSamples)
+	assert.InDelta(t, 0.5, report.Accuracy, 1e-9)
+	assert.InDelta(t, 0.25, report.FaceMissRate, 1e-9)
+
+	// 混同行列の検証
+	assert.Equal(t, []string{"happy", "sad", "unknown"}, report.Confusion.Labels)
+	assert.Equal(t, [][]int{
+		{1, 1, 0},
+		{0, 1, 1},
+		{0, 0, 0},
+	}, report.Confusion.Matrix)
+
+	// クラスごとの指標の検証
+	byLabel := make(map[string]ClassMetrics)
+	for _, c := range report.Classes {
+		byLabel[c.Label] = c
+	}
+	assert.InDelta(t, 1.0, byLabel["happy"].Precision, 1e-9)
+	assert.InDelta(t, 0.5, byLabel["happy"].Recall, 1e-9)
+	assert.InDelta(t, 0.5, byLabel["sad"].Precision, 1e-9)
+	assert.InDelta(t, 0.5, byLabel["sad"].Recall, 1e-9)
+	assert.Equal(t, 0, byLabel["unknown"].Support)
+
+	// unknownは正解ラベルに存在しないためマクロF1に含めない
+	wantMacro := (2.0/3.0 + 0.5) / 2
+	assert.InDelta(t, wantMacro, report.MacroF1, 1e-9)
+
+	// レイテンシはエラーサンプルを除外して計算
+	assert.InDelta(t, 25.0, report.Latency.Mean, 1e-9)
+	assert.InDelta(t, 20.0, report.Latency.P50, 1e-9)
+	assert.InDelta(t, 40.0, report.Latency.P99, 1e-9)
+	assert.InDelta(t, 40.0, report.Latency.Max, 1e-9)
+}
+
+func TestComputeReport_Empty(t *testing.T) {
+	report := ComputeReport(nil)
+
+	assert.Equal(t, 0, report.Evaluated)
+	assert.Empty(t, report.Classes)
+	assert.Empty(t, report.Confusion.Labels)
+
+	// 空でもJSONとして出力できること
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, report))
+	assert.True(t, json.Valid(buf.Bytes()))
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		name string
+		p    float64
+		want time.Duration
+	}{
+		{"最小", 0, 1},
+		{"中央値", 50, 5},
+		{"90パーセンタイル", 90, 9},
+		{"最大", 100, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, percentile(sorted, tt.p))
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	dir := t.TempDir()
+	happy := filepath.Join(dir, "happy.jpg")
+	missing := filepath.Join(dir, "missing.jpg")
+	require.NoError(t, os.WriteFile(happy, []byte("happy"), 0644))
+
+	samples := []Sample{
+		{Path: happy, Label: "happy"},
+		{Path: missing, Label: "sad"},
+	}
+
+	predict := func(imgData []byte) (string, int, error) {
+		return "HAPPY", 1, nil
+	}
+
+	report, predictions, err := Evaluate(context.Background(), samples, predict)
+	require.NoError(t, err)
+	require.Len(t, predictions, 2)
+
+	assert.Equal(t, "happy", predictions[0].Predicted)
+	assert.Error(t, predictions[1].Err)
+	assert.Equal(t, 1, report.Evaluated)
+	assert.InDelta(t, 1.0, report.Accuracy, 1e-9)
+
+	// 表形式の出力に主要な項目が含まれること
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, report))
+	assert.Contains(t, buf.String(), "accuracy")
+	assert.Contains(t, buf.String(), "true \\ pred")
+}
+
+func TestEvaluate_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := Evaluate(ctx, []Sample{{Path: "a.jpg", Label: "happy"}}, func([]byte) (string, int, error) {
+		return "happy", 1, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,55 @@
+package evaluation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// レポートや探索結果をJSON形式で出力
+func WriteJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("レポートのJSON出力に失敗: %w", err)
+	}
+	return nil
+}
+
+// レポートを表形式で出力
+func WriteTable(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "samples\t%d (evaluated %d, errors %d)\n", report.Total, report.Evaluated, report.Errors)
+	fmt.Fprintf(tw, "accuracy\t%.4f\n", report.Accuracy)
+	fmt.Fprintf(tw, "macro F1\t%.4f\n", report.MacroF1)
+	fmt.Fprintf(tw, "face miss rate\t%.4f\n", report.FaceMissRate)
+	fmt.Fprintf(tw, "latency (ms)\tmean %.1f / p50 %.1f / p90 %.1f / p95 %.1f / p99 %.1f / max %.1f\n",
+		report.Latency.Mean, report.Latency.P50, report.Latency.P90,
+		report.Latency.P95, report.Latency.P99, report.Latency.Max)
+	fmt.Fprintln(tw)
+
+	// クラスごとの指標
+	fmt.Fprintln(tw, "class\tprecision\trecall\tf1\tsupport")
+	for _, c := range report.Classes {
+		fmt.Fprintf(tw, "%s\t%.4f\t%.4f\t%.4f\t%d\n", c.Label, c.Precision, c.Recall, c.F1, c.Support)
+	}
+	fmt.Fprintln(tw)
+
+	// 混同行列
+	fmt.Fprintf(tw, "true \\ pred\t%s\n", strings.Join(report.Confusion.Labels, "\t"))
+	for i, label := range report.Confusion.Labels {
+		cells := make([]string, len(report.Confusion.Matrix[i]))
+		for j, v := range report.Confusion.Matrix[i] {
+			cells[j] = fmt.Sprintf("%d", v)
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", label, strings.Join(cells, "\t"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("レポートの表出力に失敗: %w", err)
+	}
+	return nil
+}
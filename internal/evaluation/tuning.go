@@ -0,0 +1,209 @@
+package evaluation
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+)
+
+// 感情分類パラメータの探索範囲
+type SearchSpace struct {
+	VariationMin   float64
+	VariationMax   float64
+	VariationStep  float64
+	BrightnessMin  float64
+	BrightnessMax  float64
+	BrightnessStep float64
+}
+
+// パラメータ探索の結果
+type TuningResult struct {
+	Baseline       config.EmotionConfig `json:"baseline"`
+	BaselineReport Report               `json:"baselineReport"`
+	Best           config.EmotionConfig `json:"best"`
+	BestReport     Report               `json:"bestReport"`
+	Candidates     int                  `json:"candidates"`
+	AccuracyGain   float64              `json:"accuracyGain"`
+}
+
+// パラメータ候補を評価する関数
+type ScoreFunc func(params config.EmotionConfig) Report
+
+// デフォルトの探索範囲を返す
+func DefaultSearchSpace() SearchSpace {
+	return SearchSpace{
+		VariationMin:   20,
+		VariationMax:   100,
+		VariationStep:  5,
+		BrightnessMin:  100,
+		BrightnessMax:  180,
+		BrightnessStep: 10,
+	}
+}
+
+// 探索範囲の検証
+func (s SearchSpace) Validate() error {
+	if s.VariationStep <= 0 || s.BrightnessStep <= 0 {
+		return fmt.Errorf("探索ステップは正の値である必要があります")
+	}
+	if s.VariationMax <= s.VariationMin || s.BrightnessMax < s.BrightnessMin {
+		return fmt.Errorf("探索範囲の上限が下限以下です")
+	}
+	if s.VariationMin < 0 {
+		return fmt.Errorf("変動の下限は0以上である必要があります")
+	}
+	return nil
+}
+
+// グリッドサーチの候補を列挙
+// 変動の閾値は surprise > happy > mid > low となる組み合わせのみを生成する
+func GridCandidates(space SearchSpace) ([]config.EmotionConfig, error) {
+	if err := space.Validate(); err != nil {
+		return nil, err
+	}
+
+	variations := steps(space.VariationMin, space.VariationMax, space.VariationStep)
+	brightness := steps(space.BrightnessMin, space.BrightnessMax, space.BrightnessStep)
+	if len(variations) < 4 {
+		return nil, fmt.Errorf("変動の探索範囲が狭すぎます")
+	}
+
+	var candidates []config.EmotionConfig
+	n := len(variations)
+	for a := 0; a < n; a++ {
+		for b := a + 1; b < n; b++ {
+			for c := b + 1; c < n; c++ {
+				for d := c + 1; d < n; d++ {
+					for _, br := range brightness {
+						candidates = append(candidates, config.EmotionConfig{
+							SurpriseVariation:   variations[d],
+							HappyVariation:      variations[c],
+							MidVariation:        variations[b],
+							LowVariation:        variations[a],
+							BrightnessThreshold: br,
+						})
+					}
+				}
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// ランダムサーチの候補を生成
+func RandomCandidates(space SearchSpace, n int, rng *rand.Rand) ([]config.EmotionConfig, error) {
+	if err := space.Validate(); err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, fmt.Errorf("候補数は正の値である必要があります")
+	}
+
+	candidates := make([]config.EmotionConfig, 0, n)
+	for len(candidates) < n {
+		v := make([]float64, 4)
+		for i := range v {
+			v[i] = round1(space.VariationMin + rng.Float64()*(space.VariationMax-space.VariationMin))
+		}
+		sort.Float64s(v)
+
+		candidate := config.EmotionConfig{
+			SurpriseVariation:   v[3],
+			HappyVariation:      v[2],
+			MidVariation:        v[1],
+			LowVariation:        v[0],
+			BrightnessThreshold: round1(space.BrightnessMin + rng.Float64()*(space.BrightnessMax-space.BrightnessMin)),
+		}
+		// 同じ値が重なった候補は閾値として不正なので捨てる
+		if candidate.Validate() != nil {
+			continue
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+// 候補を評価し、最も正解率の高いパラメータを選択
+// 正解率が同じ場合はマクロF1が高いものを優先し、ベースラインを上回らない場合はベースラインを返す
+func Tune(baseline config.EmotionConfig, candidates []config.EmotionConfig, score ScoreFunc) TuningResult {
+	result := TuningResult{
+		Baseline:   baseline,
+		Candidates: len(candidates),
+	}
+	result.BaselineReport = score(baseline)
+	result.Best = baseline
+	result.BestReport = result.BaselineReport
+
+	for _, candidate := range candidates {
+		report := score(candidate)
+		if isBetter(report, result.BestReport) {
+			result.Best = candidate
+			result.BestReport = report
+		}
+	}
+
+	result.AccuracyGain = result.BestReport.Accuracy - result.BaselineReport.Accuracy
+	return result
+}
+
+// 探索結果を表形式で出力
+func WriteTuningTable(w io.Writer, result TuningResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "parameter\tdefault\ttuned")
+	rows := []struct {
+		name          string
+		before, after float64
+	}{
+		{"surprise_variation", result.Baseline.SurpriseVariation, result.Best.SurpriseVariation},
+		{"happy_variation", result.Baseline.HappyVariation, result.Best.HappyVariation},
+		{"mid_variation", result.Baseline.MidVariation, result.Best.MidVariation},
+		{"low_variation", result.Baseline.LowVariation, result.Best.LowVariation},
+		{"brightness_threshold", result.Baseline.BrightnessThreshold, result.Best.BrightnessThreshold},
+	}
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%.1f\t%.1f\n", r.name, r.before, r.after)
+	}
+	fmt.Fprintln(tw)
+
+	fmt.Fprintf(tw, "candidates\t%d\n", result.Candidates)
+	fmt.Fprintf(tw, "accuracy\t%.4f\t%.4f\n", result.BaselineReport.Accuracy, result.BestReport.Accuracy)
+	fmt.Fprintf(tw, "macro F1\t%.4f\t%.4f\n", result.BaselineReport.MacroF1, result.BestReport.MacroF1)
+	fmt.Fprintf(tw, "accuracy gain\t%+.4f\n", result.AccuracyGain)
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("探索結果の出力に失敗: %w", err)
+	}
+	return nil
+}
+
+// レポートaがbより良いかを判定
+func isBetter(a, b Report) bool {
+	const epsilon = 1e-12
+	if a.Accuracy > b.Accuracy+epsilon {
+		return true
+	}
+	return math.Abs(a.Accuracy-b.Accuracy) <= epsilon && a.MacroF1 > b.MacroF1+epsilon
+}
+
+// minからmaxまでstep刻みの値を列挙
+func steps(min, max, step float64) []float64 {
+	var values []float64
+	for i := 0; ; i++ {
+		v := min + float64(i)*step
+		if v > max+step/1000 {
+			break
+		}
+		values = append(values, round1(v))
+	}
+	return values
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
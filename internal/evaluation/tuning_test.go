@@ -0,0 +1,108 @@
+package evaluation
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGridCandidates(t *testing.T) {
+	space := SearchSpace{
+		VariationMin:   10,
+		VariationMax:   50,
+		VariationStep:  10,
+		BrightnessMin:  100,
+		BrightnessMax:  120,
+		BrightnessStep: 10,
+	}
+
+	candidates, err := GridCandidates(space)
+	require.NoError(t, err)
+
+	// 5つの変動値から4つを選ぶ組み合わせ × 3つの輝度
+	assert.Len(t, candidates, 5*3)
+	for _, c := range candidates {
+		assert.NoError(t, c.Validate())
+	}
+}
+
+func TestGridCandidates_InvalidSpace(t *testing.T) {
+	tests := []struct {
+		name  string
+		space SearchSpace
+	}{
+		{"ステップが0", SearchSpace{VariationMin: 0, VariationMax: 100, BrightnessMin: 100, BrightnessMax: 150, BrightnessStep: 10}},
+		{"上限が下限以下", SearchSpace{VariationMin: 50, VariationMax: 50, VariationStep: 5, BrightnessMin: 100, BrightnessMax: 150, BrightnessStep: 10}},
+		{"候補が不足", SearchSpace{VariationMin: 10, VariationMax: 30, VariationStep: 10, BrightnessMin: 100, BrightnessMax: 150, BrightnessStep: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GridCandidates(tt.space)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRandomCandidates(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	candidates, err := RandomCandidates(DefaultSearchSpace(), 50, rng)
+	require.NoError(t, err)
+	require.Len(t, candidates, 50)
+
+	space := DefaultSearchSpace()
+	for _, c := range candidates {
+		assert.NoError(t, c.Validate())
+		assert.GreaterOrEqual(t, c.LowVariation, space.VariationMin)
+		assert.LessOrEqual(t, c.SurpriseVariation, space.VariationMax)
+		assert.GreaterOrEqual(t, c.BrightnessThreshold, space.BrightnessMin)
+		assert.LessOrEqual(t, c.BrightnessThreshold, space.BrightnessMax)
+	}
+}
+
+func TestTune(t *testing.T) {
+	baseline := config.DefaultEmotionConfig()
+	better := config.EmotionConfig{
+		SurpriseVariation:   90,
+		HappyVariation:      70,
+		MidVariation:        40,
+		LowVariation:        30,
+		BrightnessThreshold: 120,
+	}
+	worse := config.EmotionConfig{
+		SurpriseVariation:   60,
+		HappyVariation:      50,
+		MidVariation:        40,
+		LowVariation:        30,
+		BrightnessThreshold: 100,
+	}
+
+	score := func(p config.EmotionConfig) Report {
+		switch p {
+		case better:
+			return Report{Accuracy: 0.8, MacroF1: 0.7}
+		case worse:
+			return Report{Accuracy: 0.4}
+		default:
+			return Report{Accuracy: 0.6, MacroF1: 0.5}
+		}
+	}
+
+	result := Tune(baseline, []config.EmotionConfig{worse, better}, score)
+	assert.Equal(t, better, result.Best)
+	assert.InDelta(t, 0.2, result.AccuracyGain, 1e-9)
+	assert.Equal(t, 2, result.Candidates)
+
+	// ベースラインより良い候補がなければベースラインを維持する
+	result = Tune(baseline, []config.EmotionConfig{worse}, score)
+	assert.Equal(t, baseline, result.Best)
+	assert.Zero(t, result.AccuracyGain)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTuningTable(&buf, result))
+	assert.Contains(t, buf.String(), "accuracy gain")
+}
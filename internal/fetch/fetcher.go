@@ -0,0 +1,110 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+)
+
+// URLで指定された画像を制限付きで取得する
+type Fetcher struct {
+	*Guard
+	client  *http.Client
+	types   map[string]bool
+	accept  string
+	maxSize int64
+}
+
+// 設定を検証して取得を作成
+func New(cfg config.ImageURLConfig) (*Fetcher, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	guard, err := NewGuard(cfg.AllowedSchemes, cfg.AllowedHosts, cfg.AllowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fetcher{
+		Guard:   guard,
+		types:   make(map[string]bool, len(cfg.AllowedTypes)),
+		accept:  strings.Join(cfg.AllowedTypes, ", "),
+		maxSize: cfg.MaxSize,
+	}
+	for _, t := range cfg.AllowedTypes {
+		f.types[strings.ToLower(t)] = true
+	}
+
+	f.client = &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: guard.Transport(cfg.Timeout),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > cfg.MaxRedirects {
+				return errors.CodeError(errors.ErrCodeURLTooManyRedirects, "リダイレクトの回数が上限を超えました", nil)
+			}
+			// リダイレクト先もスキームとホストを検証する
+			return guard.CheckURL(req.URL)
+		},
+	}
+	return f, nil
+}
+
+// URLの画像を取得
+// 拒否した場合や失敗した場合は理由ごとのエラーコードのエラーを返す
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	u, err := f.ParseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.CodeError(errors.ErrCodeInvalidURL, "不正な画像のURL", err)
+	}
+	req.Header.Set("Accept", f.accept)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fetchError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.CodeError(errors.ErrCodeURLFetchFailed, fmt.Sprintf("画像の取得に失敗しました（ステータス: %d）", resp.StatusCode), nil)
+	}
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !f.types[strings.ToLower(mediaType)] {
+		return nil, errors.CodeError(errors.ErrCodeURLContentTypeNotAllowed, "許可されていない画像の形式", err)
+	}
+	if resp.ContentLength > f.maxSize {
+		return nil, errors.CodeError(errors.ErrCodeURLContentTooLarge, "画像サイズが上限を超えました", nil)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, f.maxSize+1))
+	if err != nil {
+		return nil, fetchError(err)
+	}
+	if int64(len(data)) > f.maxSize {
+		return nil, errors.CodeError(errors.ErrCodeURLContentTooLarge, "画像サイズが上限を超えました", nil)
+	}
+	return data, nil
+}
+
+// 取得のエラーを理由ごとのエラーコードのエラーに変換
+func fetchError(err error) error {
+	var codeErr *errors.Error
+	if errors.As(err, &codeErr) {
+		return codeErr
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errors.CodeError(errors.ErrCodeURLFetchTimeout, "画像の取得がタイムアウトしました", err)
+	}
+	return errors.CodeError(errors.ErrCodeURLFetchFailed, "画像の取得に失敗しました", err)
+}
@@ -0,0 +1,255 @@
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// テスト用のサーバーに接続できるようループバックを許可した設定
+func testConfig() config.ImageURLConfig {
+	return config.ImageURLConfig{
+		Enabled:         true,
+		AllowedSchemes:  []string{"http"},
+		AllowedHosts:    []string{"127.0.0.1", "localhost"},
+		AllowedNetworks: []string{"127.0.0.0/8"},
+		AllowedTypes:    []string{"image/jpeg"},
+		MaxSize:         16,
+		Timeout:         time.Second,
+		MaxRedirects:    1,
+	}
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte("jpeg"))
+	})
+	mux.HandleFunc("/large.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write([]byte(strings.Repeat("x", 32)))
+	})
+	mux.HandleFunc("/page.html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/missing.jpg", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/image.jpg", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/slow.jpg", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name     string
+		modify   func(c *config.ImageURLConfig)
+		url      string
+		want     string
+		wantCode string
+	}{
+		{
+			name: "画像を取得",
+			url:  server.URL + "/image.jpg",
+			want: "jpeg",
+		},
+		{
+			name: "リダイレクトに追従",
+			url:  server.URL + "/redirect",
+			want: "jpeg",
+		},
+		{
+			name:     "不正なURL",
+			url:      "/image.jpg",
+			wantCode: errors.ErrCodeInvalidURL,
+		},
+		{
+			name:     "認証情報を含むURL",
+			url:      strings.Replace(server.URL, "http://", "http://user:pass@", 1) + "/image.jpg",
+			wantCode: errors.ErrCodeInvalidURL,
+		},
+		{
+			name:     "許可されていないスキーム",
+			url:      "file:///etc/passwd",
+			wantCode: errors.ErrCodeURLSchemeNotAllowed,
+		},
+		{
+			name:     "許可されていないホスト",
+			modify:   func(c *config.ImageURLConfig) { c.AllowedHosts = []string{"images.example.com"} },
+			url:      server.URL + "/image.jpg",
+			wantCode: errors.ErrCodeURLHostNotAllowed,
+		},
+		{
+			name:     "ループバックアドレス",
+			modify:   func(c *config.ImageURLConfig) { c.AllowedNetworks = nil },
+			url:      server.URL + "/image.jpg",
+			wantCode: errors.ErrCodeURLAddressBlocked,
+		},
+		{
+			name:     "名前解決後のループバックアドレス",
+			modify:   func(c *config.ImageURLConfig) { c.AllowedNetworks = nil },
+			url:      strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/image.jpg",
+			wantCode: errors.ErrCodeURLAddressBlocked,
+		},
+		{
+			name:     "リダイレクトが多すぎる",
+			url:      server.URL + "/loop",
+			wantCode: errors.ErrCodeURLTooManyRedirects,
+		},
+		{
+			name:     "サイズの上限を超える",
+			url:      server.URL + "/large.jpg",
+			wantCode: errors.ErrCodeURLContentTooLarge,
+		},
+		{
+			name:     "許可されていない形式",
+			url:      server.URL + "/page.html",
+			wantCode: errors.ErrCodeURLContentTypeNotAllowed,
+		},
+		{
+			name:     "取得に失敗",
+			url:      server.URL + "/missing.jpg",
+			wantCode: errors.ErrCodeURLFetchFailed,
+		},
+		{
+			name:     "タイムアウト",
+			modify:   func(c *config.ImageURLConfig) { c.Timeout = 100 * time.Millisecond },
+			url:      server.URL + "/slow.jpg",
+			wantCode: errors.ErrCodeURLFetchTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			f, err := New(cfg)
+			require.NoError(t, err)
+
+			data, err := f.Fetch(context.Background(), tt.url)
+			if tt.wantCode != "" {
+				var e *errors.Error
+				require.True(t, errors.As(err, &e), "err = %v", err)
+				assert.Equal(t, tt.wantCode, e.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *config.ImageURLConfig)
+	}{
+		{
+			name:   "ホストが未設定",
+			modify: func(c *config.ImageURLConfig) { c.AllowedHosts = nil },
+		},
+		{
+			name:   "形式が未設定",
+			modify: func(c *config.ImageURLConfig) { c.AllowedTypes = nil },
+		},
+		{
+			name:   "タイムアウトが0",
+			modify: func(c *config.ImageURLConfig) { c.Timeout = 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(&cfg)
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFetcher_HostAllowed(t *testing.T) {
+	f, err := New(config.ImageURLConfig{AllowedHosts: []string{"images.example.com", "*.cdn.example.com"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "images.example.com", want: true},
+		{host: "IMAGES.example.com.", want: true},
+		{host: "a.cdn.example.com", want: true},
+		{host: "cdn.example.com", want: false},
+		{host: "evilcdn.example.com", want: false},
+		{host: "example.com", want: false},
+	}
+	all, err := NewGuard(nil, []string{"*"}, nil)
+	require.NoError(t, err)
+	assert.True(t, all.hostAllowed("any.example.org"))
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			assert.Equal(t, tt.want, f.hostAllowed(tt.host))
+		})
+	}
+}
+
+func TestFetcher_Blocked(t *testing.T) {
+	f, err := New(config.ImageURLConfig{AllowedNetworks: []string{"10.1.0.0/16"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "127.0.0.1", want: true},
+		{addr: "10.0.0.1", want: true},
+		{addr: "10.1.2.3", want: false},
+		{addr: "172.16.0.1", want: true},
+		{addr: "192.168.1.1", want: true},
+		{addr: "169.254.169.254", want: true},
+		{addr: "100.64.0.1", want: true},
+		{addr: "0.0.0.0", want: true},
+		{addr: "::1", want: true},
+		{addr: "fe80::1", want: true},
+		{addr: "fc00::1", want: true},
+		{addr: "::ffff:127.0.0.1", want: true},
+		{addr: "64:ff9b::7f00:1", want: true},
+		{addr: "64:ff9b:1::a00:1", want: true},
+		{addr: "2002:a00:1::1", want: true},
+		{addr: "93.184.216.34", want: false},
+		{addr: "2606:2800:220:1:248:1893:25c8:1946", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, f.blocked(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
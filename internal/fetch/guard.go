@@ -0,0 +1,161 @@
+package fetch
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+)
+
+// 許可する設定でもブロックする特殊な用途のアドレス
+// ループバック、プライベート、リンクローカルなどは netip.Addr のメソッドで判定する
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	// IPv4アドレスを埋め込んだIPv6アドレスの変換（NAT64、6to4）を経由した内部アドレスへの接続を防ぐ
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+// 外部のURLへの接続を制限する
+// 接続先のアドレスは名前解決の後に検証するため、DNSリバインディングによる回避も防ぐ
+type Guard struct {
+	schemes         map[string]bool
+	hosts           []string
+	allowedNetworks []netip.Prefix
+}
+
+// 許可するスキーム、ホスト、例外として許可するネットワーク（CIDR）からガードを作成
+func NewGuard(schemes, hosts, networks []string) (*Guard, error) {
+	g := &Guard{schemes: make(map[string]bool, len(schemes))}
+	for _, scheme := range schemes {
+		g.schemes[strings.ToLower(scheme)] = true
+	}
+	for _, host := range hosts {
+		g.hosts = append(g.hosts, strings.ToLower(host))
+	}
+	for _, network := range networks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("不正なネットワークの指定です: %w", err)
+		}
+		g.allowedNetworks = append(g.allowedNetworks, prefix)
+	}
+	return g, nil
+}
+
+// URLを解析して検証
+// 拒否した場合は理由ごとのエラーコードのエラーを返す
+func (g *Guard) ParseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() {
+		return nil, errors.CodeError(errors.ErrCodeInvalidURL, "不正なURL", err)
+	}
+	if !g.schemes[strings.ToLower(u.Scheme)] {
+		return nil, errors.CodeError(errors.ErrCodeURLSchemeNotAllowed, "許可されていないURLのスキーム: "+u.Scheme, nil)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.CodeError(errors.ErrCodeInvalidURL, "不正なURL", nil)
+	}
+	if u.User != nil {
+		return nil, errors.CodeError(errors.ErrCodeInvalidURL, "URLに認証情報は指定できません", nil)
+	}
+	if err := g.CheckURL(u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// スキームとホストが許可されているかを検証
+func (g *Guard) CheckURL(u *url.URL) error {
+	if !g.schemes[strings.ToLower(u.Scheme)] {
+		return errors.CodeError(errors.ErrCodeURLSchemeNotAllowed, "許可されていないURLのスキーム: "+u.Scheme, nil)
+	}
+	if !g.hostAllowed(u.Hostname()) {
+		return errors.CodeError(errors.ErrCodeURLHostNotAllowed, "許可されていないURLのホスト: "+u.Hostname(), nil)
+	}
+	return nil
+}
+
+// 接続先のアドレスを検証するトランスポートを作成
+func (g *Guard) Transport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{Timeout: timeout, Control: g.checkDial}
+	return &http.Transport{
+		// 環境変数のプロキシを経由すると接続先のアドレスを検証できない
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+	}
+}
+
+// ホストが許可リストに一致するかを返す
+// 許可リストが空の場合はすべてのホストを許可する
+func (g *Guard) hostAllowed(host string) bool {
+	if len(g.hosts) == 0 {
+		return true
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, allowed := range g.hosts {
+		if suffix, ok := strings.CutPrefix(allowed, "*"); ok {
+			if strings.HasSuffix(host, suffix) && len(host) > len(suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// 名前解決した後の接続先のアドレスを検証
+func (g *Guard) checkDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.CodeError(errors.ErrCodeURLAddressBlocked, "不正な接続先のアドレス", err)
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return errors.CodeError(errors.ErrCodeURLAddressBlocked, "不正な接続先のアドレス", err)
+	}
+	if g.blocked(addr) {
+		return errors.CodeError(errors.ErrCodeURLAddressBlocked, "許可されていない接続先のアドレス: "+addr.String(), nil)
+	}
+	return nil
+}
+
+// ループバック、プライベート、リンクローカルなどのアドレスかを返す
+// 許可するネットワークに含まれる場合はブロックしない
+func (g *Guard) blocked(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range g.allowedNetworks {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
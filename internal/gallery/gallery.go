@@ -0,0 +1,236 @@
+package gallery
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+)
+
+// 人物IDとして使用できる文字列
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ギャラリーに登録された人物
+type Person struct {
+	ID         string      `json:"id"`
+	Name       string      `json:"name,omitempty"`
+	Embeddings [][]float32 `json:"embeddings"`
+	EnrolledAt time.Time   `json:"enrolledAt"`
+	UpdatedAt  time.Time   `json:"updatedAt"`
+}
+
+// 顔の埋め込みと最も類似する人物
+type Match struct {
+	ID         string
+	Name       string
+	Similarity float64
+}
+
+// 登録済み人物の顔の埋め込みを保持し、ファイルに永続化するギャラリー
+// パスが空の場合はメモリ上でのみ保持する
+type Gallery struct {
+	mu     sync.RWMutex
+	path   string
+	people map[string]*Person
+	now    func() time.Time
+}
+
+// ギャラリーファイルを読み込んでギャラリーを作成
+// ファイルが存在しない場合は空のギャラリーを作成する
+func Open(path string) (*Gallery, error) {
+	g := &Gallery{
+		path:   path,
+		people: make(map[string]*Person),
+		now:    time.Now,
+	}
+	if path == "" {
+		return g, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return g, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ギャラリーファイルの読み込みに失敗: %w", err)
+	}
+
+	var people []*Person
+	if err := json.Unmarshal(data, &people); err != nil {
+		return nil, fmt.Errorf("ギャラリーファイルの解析に失敗: %w", err)
+	}
+	for _, p := range people {
+		g.people[p.ID] = p
+	}
+	return g, nil
+}
+
+// 人物の顔の埋め込みを登録
+// 登録済みの人物の場合は埋め込みを追加し、名前が指定されていれば更新する
+func (g *Gallery) Enroll(id, name string, embedding []float32) (Person, error) {
+	if !validID.MatchString(id) {
+		return Person{}, errors.CodeError(errors.ErrCodeInvalidInput, fmt.Sprintf("不正な人物IDです: %q", id), nil)
+	}
+	if len(embedding) == 0 {
+		return Person{}, errors.CodeError(errors.ErrCodeInvalidInput, "顔の埋め込みが空です", nil)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if dim := g.dimension(); dim > 0 && dim != len(embedding) {
+		return Person{}, errors.CodeError(errors.ErrCodeInvalidInput, fmt.Sprintf("埋め込みの次元数が一致しません: %d != %d", len(embedding), dim), nil)
+	}
+
+	now := g.now()
+	p, ok := g.people[id]
+	if !ok {
+		p = &Person{ID: id, EnrolledAt: now}
+		g.people[id] = p
+	}
+	if name != "" {
+		p.Name = name
+	}
+	p.Embeddings = append(p.Embeddings, append([]float32(nil), embedding...))
+	p.UpdatedAt = now
+
+	if err := g.save(); err != nil {
+		return Person{}, err
+	}
+	return copyPerson(p), nil
+}
+
+// 登録済みの人物をID順に返す
+func (g *Gallery) List() []Person {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	people := make([]Person, 0, len(g.people))
+	for _, p := range g.people {
+		people = append(people, copyPerson(p))
+	}
+	sort.Slice(people, func(i, j int) bool {
+		return people[i].ID < people[j].ID
+	})
+	return people
+}
+
+// 人物を削除
+func (g *Gallery) Delete(id string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.people[id]; !ok {
+		return fmt.Errorf("%w: %s", errors.ErrPersonNotFound, id)
+	}
+	delete(g.people, id)
+	return g.save()
+}
+
+// 顔の埋め込みと最もコサイン類似度の高い人物を返す
+// 類似度が閾値未満の場合は一致なしとする
+func (g *Gallery) Match(embedding []float32, threshold float64) (Match, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	best := Match{Similarity: math.Inf(-1)}
+	for _, p := range g.people {
+		for _, e := range p.Embeddings {
+			if len(e) != len(embedding) {
+				continue
+			}
+			if s := CosineSimilarity(e, embedding); s > best.Similarity {
+				best = Match{ID: p.ID, Name: p.Name, Similarity: s}
+			}
+		}
+	}
+	if best.ID == "" || best.Similarity < threshold {
+		return Match{}, false
+	}
+	return best, true
+}
+
+// 2つのベクトルのコサイン類似度を計算
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// 登録済みの埋め込みの次元数（未登録の場合は0）
+func (g *Gallery) dimension() int {
+	for _, p := range g.people {
+		if len(p.Embeddings) > 0 {
+			return len(p.Embeddings[0])
+		}
+	}
+	return 0
+}
+
+// ギャラリーをファイルに書き出す
+// 書き込み途中のファイルを読み込まないよう一時ファイルに書いてから置き換える
+func (g *Gallery) save() error {
+	if g.path == "" {
+		return nil
+	}
+
+	people := make([]*Person, 0, len(g.people))
+	for _, p := range g.people {
+		people = append(people, p)
+	}
+	sort.Slice(people, func(i, j int) bool {
+		return people[i].ID < people[j].ID
+	})
+
+	data, err := json.Marshal(people)
+	if err != nil {
+		return fmt.Errorf("ギャラリーのエンコードに失敗: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(g.path), 0o755); err != nil {
+		return fmt.Errorf("ギャラリーディレクトリの作成に失敗: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(g.path), ".gallery-*.tmp")
+	if err != nil {
+		return fmt.Errorf("一時ファイルの作成に失敗: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ギャラリーの書き込みに失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ギャラリーの書き込みに失敗: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), g.path); err != nil {
+		return fmt.Errorf("ギャラリーファイルの置き換えに失敗: %w", err)
+	}
+	return nil
+}
+
+func copyPerson(p *Person) Person {
+	c := *p
+	c.Embeddings = make([][]float32, len(p.Embeddings))
+	for i, e := range p.Embeddings {
+		c.Embeddings[i] = append([]float32(nil), e...)
+	}
+	return c
+}
@@ -0,0 +1,109 @@
+package gallery
+
+import (
+	stderrors "errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"同じ向き", []float32{1, 2, 3}, []float32{2, 4, 6}, 1},
+		{"直交", []float32{1, 0}, []float32{0, 1}, 0},
+		{"逆向き", []float32{1, 1}, []float32{-1, -1}, -1},
+		{"次元数が異なる", []float32{1, 0}, []float32{1, 0, 0}, 0},
+		{"ゼロベクトル", []float32{0, 0}, []float32{1, 0}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, CosineSimilarity(tt.a, tt.b), 1e-6)
+		})
+	}
+}
+
+func TestGallery_EnrollAndMatch(t *testing.T) {
+	g, err := Open("")
+	require.NoError(t, err)
+
+	_, err = g.Enroll("alice", "Alice", []float32{1, 0, 0})
+	require.NoError(t, err)
+	_, err = g.Enroll("bob", "", []float32{0, 1, 0})
+	require.NoError(t, err)
+
+	// 同じ人物に複数の埋め込みを登録できる
+	p, err := g.Enroll("alice", "", []float32{0.9, 0.1, 0})
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", p.Name)
+	assert.Len(t, p.Embeddings, 2)
+
+	match, ok := g.Match([]float32{0.95, 0.05, 0}, 0.8)
+	require.True(t, ok)
+	assert.Equal(t, "alice", match.ID)
+	assert.Equal(t, "Alice", match.Name)
+	assert.Greater(t, match.Similarity, 0.99)
+
+	// 閾値未満の場合は一致なし
+	_, ok = g.Match([]float32{0, 0, 1}, 0.5)
+	assert.False(t, ok)
+}
+
+func TestGallery_EnrollValidation(t *testing.T) {
+	g, err := Open("")
+	require.NoError(t, err)
+	_, err = g.Enroll("alice", "", []float32{1, 0, 0})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		id        string
+		embedding []float32
+	}{
+		{"不正なID", "../alice", []float32{1, 0, 0}},
+		{"空のID", "", []float32{1, 0, 0}},
+		{"空の埋め込み", "bob", nil},
+		{"次元数の不一致", "bob", []float32{1, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.Enroll(tt.id, "", tt.embedding)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGallery_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gallery", "people.json")
+
+	g, err := Open(path)
+	require.NoError(t, err)
+	_, err = g.Enroll("carol", "Carol", []float32{0.1, 0.2})
+	require.NoError(t, err)
+	_, err = g.Enroll("alice", "Alice", []float32{0.3, 0.4})
+	require.NoError(t, err)
+	require.NoError(t, g.Delete("carol"))
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	people := reopened.List()
+	require.Len(t, people, 1)
+	assert.Equal(t, "alice", people[0].ID)
+	assert.Equal(t, [][]float32{{0.3, 0.4}}, people[0].Embeddings)
+}
+
+func TestGallery_DeleteNotFound(t *testing.T) {
+	g, err := Open("")
+	require.NoError(t, err)
+
+	err = g.Delete("nobody")
+	assert.True(t, stderrors.Is(err, errors.ErrPersonNotFound))
+}
@@ -0,0 +1,2615 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: faceemotion/v1/analyzer.proto
+
+package faceemotionv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 感情の列挙値
+type Emotion int32
+
+const (
+	Emotion_EMOTION_UNSPECIFIED Emotion = 0
+	Emotion_EMOTION_HAPPY       Emotion = 1
+	Emotion_EMOTION_SAD         Emotion = 2
+	Emotion_EMOTION_ANGRY       Emotion = 3
+	Emotion_EMOTION_SURPRISE    Emotion = 4
+	Emotion_EMOTION_NEUTRAL     Emotion = 5
+	Emotion_EMOTION_UNKNOWN     Emotion = 6
+)
+
+// Enum value maps for Emotion.
+var (
+	Emotion_name = map[int32]string{
+		0: "EMOTION_UNSPECIFIED",
+		1: "EMOTION_HAPPY",
+		2: "EMOTION_SAD",
+		3: "EMOTION_ANGRY",
+		4: "EMOTION_SURPRISE",
+		5: "EMOTION_NEUTRAL",
+		6: "EMOTION_UNKNOWN",
+	}
+	Emotion_value = map[string]int32{
+		"EMOTION_UNSPECIFIED": 0,
+		"EMOTION_HAPPY":       1,
+		"EMOTION_SAD":         2,
+		"EMOTION_ANGRY":       3,
+		"EMOTION_SURPRISE":    4,
+		"EMOTION_NEUTRAL":     5,
+		"EMOTION_UNKNOWN":     6,
+	}
+)
+
+func (x Emotion) Enum() *Emotion {
+	p := new(Emotion)
+	*p = x
+	return p
+}
+
+func (x Emotion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Emotion) Descriptor() protoreflect.EnumDescriptor {
+	return file_faceemotion_v1_analyzer_proto_enumTypes[0].Descriptor()
+}
+
+func (Emotion) Type() protoreflect.EnumType {
+	return &file_faceemotion_v1_analyzer_proto_enumTypes[0]
+}
+
+func (x Emotion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Emotion.Descriptor instead.
+func (Emotion) EnumDescriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{0}
+}
+
+// 画像のサイズで正規化した座標（0〜1）
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y float64 `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Point) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+// 分析対象領域（画像のサイズで正規化した座標）
+type ROI struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X      float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y      float64 `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width  float64 `protobuf:"fixed64,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height float64 `protobuf:"fixed64,4,opt,name=height,proto3" json:"height,omitempty"`
+	// 指定した場合は矩形の代わりに多角形を使用する（3点以上）
+	Polygon []*Point `protobuf:"bytes,5,rep,name=polygon,proto3" json:"polygon,omitempty"`
+}
+
+func (x *ROI) Reset() {
+	*x = ROI{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ROI) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ROI) ProtoMessage() {}
+
+func (x *ROI) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ROI.ProtoReflect.Descriptor instead.
+func (*ROI) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ROI) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *ROI) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *ROI) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ROI) GetHeight() float64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ROI) GetPolygon() []*Point {
+	if x != nil {
+		return x.Polygon
+	}
+	return nil
+}
+
+// 分析オプション（/api/v1/analyze のリクエストと同じ）
+type AnalyzeOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PrimaryPolicy string `protobuf:"bytes,1,opt,name=primary_policy,json=primaryPolicy,proto3" json:"primary_policy,omitempty"`
+	TrackId       int32  `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	SessionId     string `protobuf:"bytes,3,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Roi           *ROI   `protobuf:"bytes,4,opt,name=roi,proto3" json:"roi,omitempty"`
+	Crowd         bool   `protobuf:"varint,5,opt,name=crowd,proto3" json:"crowd,omitempty"`
+	Locale        string `protobuf:"bytes,6,opt,name=locale,proto3" json:"locale,omitempty"`
+	// 各顔の判定根拠を含める（設定で有効な場合のみ）
+	Explain bool `protobuf:"varint,7,opt,name=explain,proto3" json:"explain,omitempty"`
+	// 判定根拠に中間画像を含める
+	ExplainImages bool `protobuf:"varint,8,opt,name=explain_images,json=explainImages,proto3" json:"explain_images,omitempty"`
+}
+
+func (x *AnalyzeOptions) Reset() {
+	*x = AnalyzeOptions{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeOptions) ProtoMessage() {}
+
+func (x *AnalyzeOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeOptions.ProtoReflect.Descriptor instead.
+func (*AnalyzeOptions) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AnalyzeOptions) GetPrimaryPolicy() string {
+	if x != nil {
+		return x.PrimaryPolicy
+	}
+	return ""
+}
+
+func (x *AnalyzeOptions) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *AnalyzeOptions) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *AnalyzeOptions) GetRoi() *ROI {
+	if x != nil {
+		return x.Roi
+	}
+	return nil
+}
+
+func (x *AnalyzeOptions) GetCrowd() bool {
+	if x != nil {
+		return x.Crowd
+	}
+	return false
+}
+
+func (x *AnalyzeOptions) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *AnalyzeOptions) GetExplain() bool {
+	if x != nil {
+		return x.Explain
+	}
+	return false
+}
+
+func (x *AnalyzeOptions) GetExplainImages() bool {
+	if x != nil {
+		return x.ExplainImages
+	}
+	return false
+}
+
+type AnalyzeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// JPEG、PNG、WebP、BMPの画像のバイナリ
+	Image   []byte          `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Options *AnalyzeOptions `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// image の代わりに指定する画像のURL（設定で有効な場合のみ）
+	ImageUrl string `protobuf:"bytes,3,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
+}
+
+func (x *AnalyzeRequest) Reset() {
+	*x = AnalyzeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRequest) ProtoMessage() {}
+
+func (x *AnalyzeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeRequest) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnalyzeRequest) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *AnalyzeRequest) GetOptions() *AnalyzeOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *AnalyzeRequest) GetImageUrl() string {
+	if x != nil {
+		return x.ImageUrl
+	}
+	return ""
+}
+
+type ImageInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Width  int32 `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (x *ImageInfo) Reset() {
+	*x = ImageInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageInfo) ProtoMessage() {}
+
+func (x *ImageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageInfo.ProtoReflect.Descriptor instead.
+func (*ImageInfo) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ImageInfo) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImageInfo) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// ピクセル単位の顔の矩形
+type Box struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X      int32 `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y      int32 `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width  int32 `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height int32 `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (x *Box) Reset() {
+	*x = Box{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Box) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Box) ProtoMessage() {}
+
+func (x *Box) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Box.ProtoReflect.Descriptor instead.
+func (*Box) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Box) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Box) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Box) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Box) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// 画像のサイズで正規化した顔の矩形（0〜1）
+type NormalizedBox struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	X      float64 `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y      float64 `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width  float64 `protobuf:"fixed64,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height float64 `protobuf:"fixed64,4,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (x *NormalizedBox) Reset() {
+	*x = NormalizedBox{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NormalizedBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NormalizedBox) ProtoMessage() {}
+
+func (x *NormalizedBox) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NormalizedBox.ProtoReflect.Descriptor instead.
+func (*NormalizedBox) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NormalizedBox) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *NormalizedBox) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *NormalizedBox) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *NormalizedBox) GetHeight() float64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// 目の開閉と視線方向
+type Eyes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Openness float64 `protobuf:"fixed64,1,opt,name=openness,proto3" json:"openness,omitempty"`
+	State    string  `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Gaze     string  `protobuf:"bytes,3,opt,name=gaze,proto3" json:"gaze,omitempty"`
+}
+
+func (x *Eyes) Reset() {
+	*x = Eyes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Eyes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Eyes) ProtoMessage() {}
+
+func (x *Eyes) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Eyes.ProtoReflect.Descriptor instead.
+func (*Eyes) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Eyes) GetOpenness() float64 {
+	if x != nil {
+		return x.Openness
+	}
+	return 0
+}
+
+func (x *Eyes) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Eyes) GetGaze() string {
+	if x != nil {
+		return x.Gaze
+	}
+	return ""
+}
+
+// 感情の判定根拠
+type FaceExplain struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Brightness        float64            `protobuf:"fixed64,1,opt,name=brightness,proto3" json:"brightness,omitempty"`
+	Variation         float64            `protobuf:"fixed64,2,opt,name=variation,proto3" json:"variation,omitempty"`
+	SmileScore        float64            `protobuf:"fixed64,3,opt,name=smile_score,json=smileScore,proto3" json:"smile_score,omitempty"`
+	LaplacianVariance float64            `protobuf:"fixed64,4,opt,name=laplacian_variance,json=laplacianVariance,proto3" json:"laplacian_variance,omitempty"`
+	Sharpness         float64            `protobuf:"fixed64,5,opt,name=sharpness,proto3" json:"sharpness,omitempty"`
+	SizeScore         float64            `protobuf:"fixed64,6,opt,name=size_score,json=sizeScore,proto3" json:"size_score,omitempty"`
+	Quality           float64            `protobuf:"fixed64,7,opt,name=quality,proto3" json:"quality,omitempty"`
+	Emotion           Emotion            `protobuf:"varint,8,opt,name=emotion,proto3,enum=faceemotion.v1.Emotion" json:"emotion,omitempty"`
+	Rule              string             `protobuf:"bytes,9,opt,name=rule,proto3" json:"rule,omitempty"`
+	Thresholds        map[string]float64 `protobuf:"bytes,10,rep,name=thresholds,proto3" json:"thresholds,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	Margins           map[string]float64 `protobuf:"bytes,11,rep,name=margins,proto3" json:"margins,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	// explain_images を指定した場合の平坦化した顔領域のPNG画像
+	RoiImage []byte `protobuf:"bytes,12,opt,name=roi_image,json=roiImage,proto3" json:"roi_image,omitempty"`
+}
+
+func (x *FaceExplain) Reset() {
+	*x = FaceExplain{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FaceExplain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FaceExplain) ProtoMessage() {}
+
+func (x *FaceExplain) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FaceExplain.ProtoReflect.Descriptor instead.
+func (*FaceExplain) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FaceExplain) GetBrightness() float64 {
+	if x != nil {
+		return x.Brightness
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetVariation() float64 {
+	if x != nil {
+		return x.Variation
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetSmileScore() float64 {
+	if x != nil {
+		return x.SmileScore
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetLaplacianVariance() float64 {
+	if x != nil {
+		return x.LaplacianVariance
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetSharpness() float64 {
+	if x != nil {
+		return x.Sharpness
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetSizeScore() float64 {
+	if x != nil {
+		return x.SizeScore
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetQuality() float64 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *FaceExplain) GetEmotion() Emotion {
+	if x != nil {
+		return x.Emotion
+	}
+	return Emotion_EMOTION_UNSPECIFIED
+}
+
+func (x *FaceExplain) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *FaceExplain) GetThresholds() map[string]float64 {
+	if x != nil {
+		return x.Thresholds
+	}
+	return nil
+}
+
+func (x *FaceExplain) GetMargins() map[string]float64 {
+	if x != nil {
+		return x.Margins
+	}
+	return nil
+}
+
+func (x *FaceExplain) GetRoiImage() []byte {
+	if x != nil {
+		return x.RoiImage
+	}
+	return nil
+}
+
+// 検出された顔ごとの分析結果
+type Face struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Box           *Box           `protobuf:"bytes,1,opt,name=box,proto3" json:"box,omitempty"`
+	NormalizedBox *NormalizedBox `protobuf:"bytes,2,opt,name=normalized_box,json=normalizedBox,proto3" json:"normalized_box,omitempty"`
+	Emotion       Emotion        `protobuf:"varint,3,opt,name=emotion,proto3,enum=faceemotion.v1.Emotion" json:"emotion,omitempty"`
+	Detector      string         `protobuf:"bytes,4,opt,name=detector,proto3" json:"detector,omitempty"`
+	Score         float64        `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	TrackId       int32          `protobuf:"varint,6,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Quality       float64        `protobuf:"fixed64,7,opt,name=quality,proto3" json:"quality,omitempty"`
+	Engagement    float64        `protobuf:"fixed64,8,opt,name=engagement,proto3" json:"engagement,omitempty"`
+	ParticipantId string         `protobuf:"bytes,9,opt,name=participant_id,json=participantId,proto3" json:"participant_id,omitempty"`
+	Similarity    float64        `protobuf:"fixed64,10,opt,name=similarity,proto3" json:"similarity,omitempty"`
+	Occluded      bool           `protobuf:"varint,11,opt,name=occluded,proto3" json:"occluded,omitempty"`
+	Masked        bool           `protobuf:"varint,12,opt,name=masked,proto3" json:"masked,omitempty"`
+	// 目の推定が有効な場合のみ設定する
+	Eyes *Eyes `protobuf:"bytes,13,opt,name=eyes,proto3" json:"eyes,omitempty"`
+	// explain を指定した場合のみ設定する
+	Explain *FaceExplain `protobuf:"bytes,14,opt,name=explain,proto3" json:"explain,omitempty"`
+}
+
+func (x *Face) Reset() {
+	*x = Face{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Face) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Face) ProtoMessage() {}
+
+func (x *Face) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Face.ProtoReflect.Descriptor instead.
+func (*Face) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Face) GetBox() *Box {
+	if x != nil {
+		return x.Box
+	}
+	return nil
+}
+
+func (x *Face) GetNormalizedBox() *NormalizedBox {
+	if x != nil {
+		return x.NormalizedBox
+	}
+	return nil
+}
+
+func (x *Face) GetEmotion() Emotion {
+	if x != nil {
+		return x.Emotion
+	}
+	return Emotion_EMOTION_UNSPECIFIED
+}
+
+func (x *Face) GetDetector() string {
+	if x != nil {
+		return x.Detector
+	}
+	return ""
+}
+
+func (x *Face) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Face) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *Face) GetQuality() float64 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *Face) GetEngagement() float64 {
+	if x != nil {
+		return x.Engagement
+	}
+	return 0
+}
+
+func (x *Face) GetParticipantId() string {
+	if x != nil {
+		return x.ParticipantId
+	}
+	return ""
+}
+
+func (x *Face) GetSimilarity() float64 {
+	if x != nil {
+		return x.Similarity
+	}
+	return 0
+}
+
+func (x *Face) GetOccluded() bool {
+	if x != nil {
+		return x.Occluded
+	}
+	return false
+}
+
+func (x *Face) GetMasked() bool {
+	if x != nil {
+		return x.Masked
+	}
+	return false
+}
+
+func (x *Face) GetEyes() *Eyes {
+	if x != nil {
+		return x.Eyes
+	}
+	return nil
+}
+
+func (x *Face) GetExplain() *FaceExplain {
+	if x != nil {
+		return x.Explain
+	}
+	return nil
+}
+
+// セッション全体の集計結果
+type SessionAggregate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SessionId         string           `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Frames            int32            `protobuf:"varint,2,opt,name=frames,proto3" json:"frames,omitempty"`
+	FramesWithFaces   int32            `protobuf:"varint,3,opt,name=frames_with_faces,json=framesWithFaces,proto3" json:"frames_with_faces,omitempty"`
+	FacesObserved     int32            `protobuf:"varint,4,opt,name=faces_observed,json=facesObserved,proto3" json:"faces_observed,omitempty"`
+	MeanEngagement    float64          `protobuf:"fixed64,5,opt,name=mean_engagement,json=meanEngagement,proto3" json:"mean_engagement,omitempty"`
+	MinEngagement     float64          `protobuf:"fixed64,6,opt,name=min_engagement,json=minEngagement,proto3" json:"min_engagement,omitempty"`
+	MaxEngagement     float64          `protobuf:"fixed64,7,opt,name=max_engagement,json=maxEngagement,proto3" json:"max_engagement,omitempty"`
+	EngagedFrameRatio float64          `protobuf:"fixed64,8,opt,name=engaged_frame_ratio,json=engagedFrameRatio,proto3" json:"engaged_frame_ratio,omitempty"`
+	EmotionCounts     map[string]int32 `protobuf:"bytes,9,rep,name=emotion_counts,json=emotionCounts,proto3" json:"emotion_counts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *SessionAggregate) Reset() {
+	*x = SessionAggregate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SessionAggregate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionAggregate) ProtoMessage() {}
+
+func (x *SessionAggregate) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionAggregate.ProtoReflect.Descriptor instead.
+func (*SessionAggregate) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SessionAggregate) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionAggregate) GetFrames() int32 {
+	if x != nil {
+		return x.Frames
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetFramesWithFaces() int32 {
+	if x != nil {
+		return x.FramesWithFaces
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetFacesObserved() int32 {
+	if x != nil {
+		return x.FacesObserved
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetMeanEngagement() float64 {
+	if x != nil {
+		return x.MeanEngagement
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetMinEngagement() float64 {
+	if x != nil {
+		return x.MinEngagement
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetMaxEngagement() float64 {
+	if x != nil {
+		return x.MaxEngagement
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetEngagedFrameRatio() float64 {
+	if x != nil {
+		return x.EngagedFrameRatio
+	}
+	return 0
+}
+
+func (x *SessionAggregate) GetEmotionCounts() map[string]int32 {
+	if x != nil {
+		return x.EmotionCounts
+	}
+	return nil
+}
+
+// 群衆モードの集計結果
+type Crowd struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FaceCount    int32              `protobuf:"varint,1,opt,name=face_count,json=faceCount,proto3" json:"face_count,omitempty"`
+	Emotions     map[string]int32   `protobuf:"bytes,2,rep,name=emotions,proto3" json:"emotions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Distribution map[string]float64 `protobuf:"bytes,3,rep,name=distribution,proto3" json:"distribution,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	Tiles        int32              `protobuf:"varint,4,opt,name=tiles,proto3" json:"tiles,omitempty"`
+}
+
+func (x *Crowd) Reset() {
+	*x = Crowd{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Crowd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Crowd) ProtoMessage() {}
+
+func (x *Crowd) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Crowd.ProtoReflect.Descriptor instead.
+func (*Crowd) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Crowd) GetFaceCount() int32 {
+	if x != nil {
+		return x.FaceCount
+	}
+	return 0
+}
+
+func (x *Crowd) GetEmotions() map[string]int32 {
+	if x != nil {
+		return x.Emotions
+	}
+	return nil
+}
+
+func (x *Crowd) GetDistribution() map[string]float64 {
+	if x != nil {
+		return x.Distribution
+	}
+	return nil
+}
+
+func (x *Crowd) GetTiles() int32 {
+	if x != nil {
+		return x.Tiles
+	}
+	return 0
+}
+
+// パイプラインの段階ごとの処理時間
+type StageTiming struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Stage      string  `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	DurationMs float64 `protobuf:"fixed64,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+}
+
+func (x *StageTiming) Reset() {
+	*x = StageTiming{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StageTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageTiming) ProtoMessage() {}
+
+func (x *StageTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageTiming.ProtoReflect.Descriptor instead.
+func (*StageTiming) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StageTiming) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *StageTiming) GetDurationMs() float64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// サーバーと分析モデルのバージョン情報
+type Meta struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ApiVersion    string   `protobuf:"bytes,1,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	ServerVersion string   `protobuf:"bytes,2,opt,name=server_version,json=serverVersion,proto3" json:"server_version,omitempty"`
+	Detectors     []string `protobuf:"bytes,3,rep,name=detectors,proto3" json:"detectors,omitempty"`
+	Pipeline      []string `protobuf:"bytes,4,rep,name=pipeline,proto3" json:"pipeline,omitempty"`
+}
+
+func (x *Meta) Reset() {
+	*x = Meta{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Meta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Meta) ProtoMessage() {}
+
+func (x *Meta) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Meta.ProtoReflect.Descriptor instead.
+func (*Meta) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Meta) GetApiVersion() string {
+	if x != nil {
+		return x.ApiVersion
+	}
+	return ""
+}
+
+func (x *Meta) GetServerVersion() string {
+	if x != nil {
+		return x.ServerVersion
+	}
+	return ""
+}
+
+func (x *Meta) GetDetectors() []string {
+	if x != nil {
+		return x.Detectors
+	}
+	return nil
+}
+
+func (x *Meta) GetPipeline() []string {
+	if x != nil {
+		return x.Pipeline
+	}
+	return nil
+}
+
+type AnalyzeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Image      *ImageInfo `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	Emotion    Emotion    `protobuf:"varint,2,opt,name=emotion,proto3,enum=faceemotion.v1.Emotion" json:"emotion,omitempty"`
+	Confidence float64    `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// 主要な顔のインデックス（顔がない場合は -1）
+	PrimaryIndex   int32    `protobuf:"varint,4,opt,name=primary_index,json=primaryIndex,proto3" json:"primary_index,omitempty"`
+	PrimaryTrackId int32    `protobuf:"varint,5,opt,name=primary_track_id,json=primaryTrackId,proto3" json:"primary_track_id,omitempty"`
+	PrimaryPolicy  string   `protobuf:"bytes,6,opt,name=primary_policy,json=primaryPolicy,proto3" json:"primary_policy,omitempty"`
+	Faces          []*Face  `protobuf:"bytes,7,rep,name=faces,proto3" json:"faces,omitempty"`
+	Preprocessing  []string `protobuf:"bytes,8,rep,name=preprocessing,proto3" json:"preprocessing,omitempty"`
+	// session_id を指定した場合のみ設定する
+	Session *SessionAggregate `protobuf:"bytes,9,opt,name=session,proto3" json:"session,omitempty"`
+	Meta    *Meta             `protobuf:"bytes,10,opt,name=meta,proto3" json:"meta,omitempty"`
+	// 顔の矩形を描画したJPEG画像
+	ProcessedImage []byte `protobuf:"bytes,11,opt,name=processed_image,json=processedImage,proto3" json:"processed_image,omitempty"`
+	// crowd を指定した場合のみ設定する
+	Crowd *Crowd `protobuf:"bytes,12,opt,name=crowd,proto3" json:"crowd,omitempty"`
+	// デバッグモードの場合のみ設定する
+	Timings []*StageTiming `protobuf:"bytes,13,rep,name=timings,proto3" json:"timings,omitempty"`
+	// explain_images を指定した場合のグレースケール画像のPNG画像
+	GrayscaleImage []byte `protobuf:"bytes,14,opt,name=grayscale_image,json=grayscaleImage,proto3" json:"grayscale_image,omitempty"`
+}
+
+func (x *AnalyzeResponse) Reset() {
+	*x = AnalyzeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeResponse) ProtoMessage() {}
+
+func (x *AnalyzeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeResponse) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *AnalyzeResponse) GetImage() *ImageInfo {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetEmotion() Emotion {
+	if x != nil {
+		return x.Emotion
+	}
+	return Emotion_EMOTION_UNSPECIFIED
+}
+
+func (x *AnalyzeResponse) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *AnalyzeResponse) GetPrimaryIndex() int32 {
+	if x != nil {
+		return x.PrimaryIndex
+	}
+	return 0
+}
+
+func (x *AnalyzeResponse) GetPrimaryTrackId() int32 {
+	if x != nil {
+		return x.PrimaryTrackId
+	}
+	return 0
+}
+
+func (x *AnalyzeResponse) GetPrimaryPolicy() string {
+	if x != nil {
+		return x.PrimaryPolicy
+	}
+	return ""
+}
+
+func (x *AnalyzeResponse) GetFaces() []*Face {
+	if x != nil {
+		return x.Faces
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetPreprocessing() []string {
+	if x != nil {
+		return x.Preprocessing
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetSession() *SessionAggregate {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetMeta() *Meta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetProcessedImage() []byte {
+	if x != nil {
+		return x.ProcessedImage
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetCrowd() *Crowd {
+	if x != nil {
+		return x.Crowd
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetTimings() []*StageTiming {
+	if x != nil {
+		return x.Timings
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetGrayscaleImage() []byte {
+	if x != nil {
+		return x.GrayscaleImage
+	}
+	return nil
+}
+
+// 分析に失敗した場合のエラー（HTTPのエラーレスポンスと同じエラーコード）
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code   string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Title  string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Detail string `protobuf:"bytes,3,opt,name=detail,proto3" json:"detail,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Error) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+// Protobuf で返すHTTPのエラーレスポンス（RFC 7807 の problem と同じ項目）
+type Problem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type      string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Title     string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Status    int32  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	Detail    string `protobuf:"bytes,4,opt,name=detail,proto3" json:"detail,omitempty"`
+	Instance  string `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"`
+	Code      string `protobuf:"bytes,6,opt,name=code,proto3" json:"code,omitempty"`
+	RequestId string `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+}
+
+func (x *Problem) Reset() {
+	*x = Problem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Problem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Problem) ProtoMessage() {}
+
+func (x *Problem) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Problem.ProtoReflect.Descriptor instead.
+func (*Problem) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *Problem) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Problem) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Problem) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Problem) GetDetail() string {
+	if x != nil {
+		return x.Detail
+	}
+	return ""
+}
+
+func (x *Problem) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *Problem) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Problem) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type BatchAnalyzeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Requests []*AnalyzeRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+}
+
+func (x *BatchAnalyzeRequest) Reset() {
+	*x = BatchAnalyzeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchAnalyzeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAnalyzeRequest) ProtoMessage() {}
+
+func (x *BatchAnalyzeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAnalyzeRequest.ProtoReflect.Descriptor instead.
+func (*BatchAnalyzeRequest) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BatchAnalyzeRequest) GetRequests() []*AnalyzeRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+type BatchAnalyzeResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Outcome:
+	//	*BatchAnalyzeResult_Response
+	//	*BatchAnalyzeResult_Error
+	Outcome isBatchAnalyzeResult_Outcome `protobuf_oneof:"outcome"`
+}
+
+func (x *BatchAnalyzeResult) Reset() {
+	*x = BatchAnalyzeResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchAnalyzeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAnalyzeResult) ProtoMessage() {}
+
+func (x *BatchAnalyzeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAnalyzeResult.ProtoReflect.Descriptor instead.
+func (*BatchAnalyzeResult) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{18}
+}
+
+func (m *BatchAnalyzeResult) GetOutcome() isBatchAnalyzeResult_Outcome {
+	if m != nil {
+		return m.Outcome
+	}
+	return nil
+}
+
+func (x *BatchAnalyzeResult) GetResponse() *AnalyzeResponse {
+	if x, ok := x.GetOutcome().(*BatchAnalyzeResult_Response); ok {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *BatchAnalyzeResult) GetError() *Error {
+	if x, ok := x.GetOutcome().(*BatchAnalyzeResult_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isBatchAnalyzeResult_Outcome interface {
+	isBatchAnalyzeResult_Outcome()
+}
+
+type BatchAnalyzeResult_Response struct {
+	Response *AnalyzeResponse `protobuf:"bytes,1,opt,name=response,proto3,oneof"`
+}
+
+type BatchAnalyzeResult_Error struct {
+	Error *Error `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchAnalyzeResult_Response) isBatchAnalyzeResult_Outcome() {}
+
+func (*BatchAnalyzeResult_Error) isBatchAnalyzeResult_Outcome() {}
+
+type BatchAnalyzeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// リクエストと同じ順序の結果
+	Results []*BatchAnalyzeResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+}
+
+func (x *BatchAnalyzeResponse) Reset() {
+	*x = BatchAnalyzeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchAnalyzeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchAnalyzeResponse) ProtoMessage() {}
+
+func (x *BatchAnalyzeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchAnalyzeResponse.ProtoReflect.Descriptor instead.
+func (*BatchAnalyzeResponse) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BatchAnalyzeResponse) GetResults() []*BatchAnalyzeResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type AnalyzeStreamRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 分析オプション（最初のメッセージでのみ指定できる）
+	Options *AnalyzeOptions `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"`
+	// 画像のフレームのバイナリ（最初のメッセージでは省略できる）
+	Frame []byte `protobuf:"bytes,2,opt,name=frame,proto3" json:"frame,omitempty"`
+}
+
+func (x *AnalyzeStreamRequest) Reset() {
+	*x = AnalyzeStreamRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeStreamRequest) ProtoMessage() {}
+
+func (x *AnalyzeStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeStreamRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeStreamRequest) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *AnalyzeStreamRequest) GetOptions() *AnalyzeOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *AnalyzeStreamRequest) GetFrame() []byte {
+	if x != nil {
+		return x.Frame
+	}
+	return nil
+}
+
+type AnalyzeStreamResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 分析したフレームの通し番号（1から）
+	Seq uint64 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	// 前回の送信以降に破棄したフレーム数
+	Dropped int64 `protobuf:"varint,2,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	// Types that are assignable to Outcome:
+	//	*AnalyzeStreamResponse_Result
+	//	*AnalyzeStreamResponse_Error
+	Outcome isAnalyzeStreamResponse_Outcome `protobuf_oneof:"outcome"`
+}
+
+func (x *AnalyzeStreamResponse) Reset() {
+	*x = AnalyzeStreamResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_faceemotion_v1_analyzer_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeStreamResponse) ProtoMessage() {}
+
+func (x *AnalyzeStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_faceemotion_v1_analyzer_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeStreamResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeStreamResponse) Descriptor() ([]byte, []int) {
+	return file_faceemotion_v1_analyzer_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AnalyzeStreamResponse) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *AnalyzeStreamResponse) GetDropped() int64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (m *AnalyzeStreamResponse) GetOutcome() isAnalyzeStreamResponse_Outcome {
+	if m != nil {
+		return m.Outcome
+	}
+	return nil
+}
+
+func (x *AnalyzeStreamResponse) GetResult() *AnalyzeResponse {
+	if x, ok := x.GetOutcome().(*AnalyzeStreamResponse_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *AnalyzeStreamResponse) GetError() *Error {
+	if x, ok := x.GetOutcome().(*AnalyzeStreamResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isAnalyzeStreamResponse_Outcome interface {
+	isAnalyzeStreamResponse_Outcome()
+}
+
+type AnalyzeStreamResponse_Result struct {
+	Result *AnalyzeResponse `protobuf:"bytes,3,opt,name=result,proto3,oneof"`
+}
+
+type AnalyzeStreamResponse_Error struct {
+	Error *Error `protobuf:"bytes,4,opt,name=error,proto3,oneof"`
+}
+
+func (*AnalyzeStreamResponse_Result) isAnalyzeStreamResponse_Outcome() {}
+
+func (*AnalyzeStreamResponse_Error) isAnalyzeStreamResponse_Outcome() {}
+
+var File_faceemotion_v1_analyzer_proto protoreflect.FileDescriptor
+
+var file_faceemotion_v1_analyzer_proto_rawDesc = []byte{
+	0x0a, 0x1d, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31,
+	0x2f, 0x61, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x22,
+	0x23, 0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x01, 0x79, 0x22, 0x80, 0x01, 0x0a, 0x03, 0x52, 0x4f, 0x49, 0x12, 0x0c, 0x0a, 0x01,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64, 0x74,
+	0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12, 0x16,
+	0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x2f, 0x0a, 0x07, 0x70, 0x6f, 0x6c, 0x79, 0x67, 0x6f,
+	0x6e, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x07,
+	0x70, 0x6f, 0x6c, 0x79, 0x67, 0x6f, 0x6e, 0x22, 0x87, 0x02, 0x0a, 0x0e, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x7a, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72,
+	0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x03, 0x72,
+	0x6f, 0x69, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65,
+	0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x4f, 0x49, 0x52, 0x03, 0x72,
+	0x6f, 0x69, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x05, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x61,
+	0x6c, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x07, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x65, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0d, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x49, 0x6d, 0x61, 0x67, 0x65,
+	0x73, 0x22, 0x7d, 0x0a, 0x0e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x38, 0x0a, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x66, 0x61, 0x63,
+	0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x7a, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x5f, 0x75, 0x72, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x55, 0x72, 0x6c,
+	0x22, 0x39, 0x0a, 0x09, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x14, 0x0a,
+	0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77, 0x69,
+	0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x4f, 0x0a, 0x03, 0x42,
+	0x6f, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x78,
+	0x12, 0x0c, 0x0a, 0x01, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x01, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x77,
+	0x69, 0x64, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x59, 0x0a, 0x0d,
+	0x4e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x42, 0x6f, 0x78, 0x12, 0x0c, 0x0a,
+	0x01, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x78, 0x12, 0x0c, 0x0a, 0x01, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x01, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x77, 0x69, 0x64, 0x74, 0x68, 0x12,
+	0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x4c, 0x0a, 0x04, 0x45, 0x79, 0x65, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x08, 0x6f, 0x70, 0x65, 0x6e, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x67, 0x61, 0x7a, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x67, 0x61, 0x7a, 0x65, 0x22, 0xe2, 0x04, 0x0a, 0x0b, 0x46, 0x61, 0x63, 0x65, 0x45, 0x78,
+	0x70, 0x6c, 0x61, 0x69, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68, 0x74, 0x6e,
+	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x62, 0x72, 0x69, 0x67, 0x68,
+	0x74, 0x6e, 0x65, 0x73, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x76, 0x61, 0x72, 0x69, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x76, 0x61, 0x72, 0x69, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x6d, 0x69, 0x6c, 0x65, 0x5f, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x73, 0x6d, 0x69, 0x6c, 0x65, 0x53,
+	0x63, 0x6f, 0x72, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x6c, 0x61, 0x70, 0x6c, 0x61, 0x63, 0x69, 0x61,
+	0x6e, 0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x11, 0x6c, 0x61, 0x70, 0x6c, 0x61, 0x63, 0x69, 0x61, 0x6e, 0x56, 0x61, 0x72, 0x69, 0x61,
+	0x6e, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x68, 0x61, 0x72, 0x70, 0x6e, 0x65, 0x73, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x73, 0x68, 0x61, 0x72, 0x70, 0x6e, 0x65, 0x73,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x53, 0x63, 0x6f, 0x72, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x31, 0x0a, 0x07, 0x65, 0x6d,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x66, 0x61,
+	0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6d, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a,
+	0x04, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75, 0x6c,
+	0x65, 0x12, 0x4b, 0x0a, 0x0a, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x18,
+	0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x63, 0x65, 0x45, 0x78, 0x70, 0x6c, 0x61,
+	0x69, 0x6e, 0x2e, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x0a, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x12, 0x42,
+	0x0a, 0x07, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x28, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x61, 0x63, 0x65, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x2e, 0x4d, 0x61, 0x72,
+	0x67, 0x69, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x6d, 0x61, 0x72, 0x67, 0x69,
+	0x6e, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x6f, 0x69, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x72, 0x6f, 0x69, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x1a,
+	0x3d, 0x0a, 0x0f, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3a,
+	0x0a, 0x0c, 0x4d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x89, 0x04, 0x0a, 0x04, 0x46,
+	0x61, 0x63, 0x65, 0x12, 0x25, 0x0a, 0x03, 0x62, 0x6f, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x42, 0x6f, 0x78, 0x52, 0x03, 0x62, 0x6f, 0x78, 0x12, 0x44, 0x0a, 0x0e, 0x6e, 0x6f,
+	0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x5f, 0x62, 0x6f, 0x78, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x42, 0x6f,
+	0x78, 0x52, 0x0d, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x64, 0x42, 0x6f, 0x78,
+	0x12, 0x31, 0x0a, 0x07, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x17, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x65, 0x6d, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12,
+	0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x5f, 0x69,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x64,
+	0x12, 0x18, 0x0a, 0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x07, 0x71, 0x75, 0x61, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1e, 0x0a, 0x0a, 0x65, 0x6e,
+	0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a,
+	0x65, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x70, 0x61, 0x72, 0x74, 0x69, 0x63, 0x69, 0x70, 0x61, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x73, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74,
+	0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6f, 0x63, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x08, 0x6f, 0x63, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x6d, 0x61, 0x73, 0x6b, 0x65, 0x64, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6d,
+	0x61, 0x73, 0x6b, 0x65, 0x64, 0x12, 0x28, 0x0a, 0x04, 0x65, 0x79, 0x65, 0x73, 0x18, 0x0d, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x79, 0x65, 0x73, 0x52, 0x04, 0x65, 0x79, 0x65, 0x73, 0x12,
+	0x35, 0x0a, 0x07, 0x65, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x46, 0x61, 0x63, 0x65, 0x45, 0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x52, 0x07, 0x65,
+	0x78, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x22, 0xe1, 0x03, 0x0a, 0x10, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x72,
+	0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x66, 0x72, 0x61, 0x6d,
+	0x65, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x73, 0x5f, 0x77, 0x69, 0x74,
+	0x68, 0x5f, 0x66, 0x61, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f, 0x66,
+	0x72, 0x61, 0x6d, 0x65, 0x73, 0x57, 0x69, 0x74, 0x68, 0x46, 0x61, 0x63, 0x65, 0x73, 0x12, 0x25,
+	0x0a, 0x0e, 0x66, 0x61, 0x63, 0x65, 0x73, 0x5f, 0x6f, 0x62, 0x73, 0x65, 0x72, 0x76, 0x65, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x66, 0x61, 0x63, 0x65, 0x73, 0x4f, 0x62, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x65, 0x61, 0x6e, 0x5f, 0x65, 0x6e,
+	0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e,
+	0x6d, 0x65, 0x61, 0x6e, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x25,
+	0x0a, 0x0e, 0x6d, 0x69, 0x6e, 0x5f, 0x65, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6d, 0x69, 0x6e, 0x45, 0x6e, 0x67, 0x61, 0x67,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x6e, 0x67,
+	0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6d,
+	0x61, 0x78, 0x45, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x13,
+	0x65, 0x6e, 0x67, 0x61, 0x67, 0x65, 0x64, 0x5f, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x5f, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x65, 0x6e, 0x67, 0x61, 0x67,
+	0x65, 0x64, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x5a, 0x0a, 0x0e,
+	0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x09,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x41, 0x67, 0x67,
+	0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x2e, 0x45, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x65, 0x6d, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x1a, 0x40, 0x0a, 0x12, 0x45, 0x6d, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc8, 0x02, 0x0a, 0x05, 0x43,
+	0x72, 0x6f, 0x77, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x61, 0x63, 0x65, 0x5f, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x66, 0x61, 0x63, 0x65, 0x43, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x3f, 0x0a, 0x08, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x6f, 0x77, 0x64, 0x2e, 0x45, 0x6d, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x65, 0x6d, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x4b, 0x0a, 0x0c, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x66, 0x61, 0x63,
+	0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x6f, 0x77,
+	0x64, 0x2e, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0c, 0x64, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x74, 0x69, 0x6c, 0x65, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x45, 0x6d, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x1a, 0x3f, 0x0a, 0x11, 0x44, 0x69, 0x73, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x44, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x67, 0x65, 0x54, 0x69,
+	0x6d, 0x69, 0x6e, 0x67, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x67, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x22, 0x88, 0x01, 0x0a, 0x04,
+	0x4d, 0x65, 0x74, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x70, 0x69, 0x5f, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x70, 0x69, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f,
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09,
+	0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x09, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x69,
+	0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x70, 0x69,
+	0x70, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0xf9, 0x04, 0x0a, 0x0f, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2f, 0x0a, 0x05, 0x69, 0x6d,
+	0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x66, 0x61, 0x63, 0x65,
+	0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6d, 0x61, 0x67, 0x65,
+	0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x31, 0x0a, 0x07, 0x65,
+	0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x66,
+	0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6d,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e,
+	0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x23,
+	0x0a, 0x0d, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x70,
+	0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x25, 0x0a,
+	0x0e, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x12, 0x2a, 0x0a, 0x05, 0x66, 0x61, 0x63, 0x65, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x63, 0x65, 0x52, 0x05, 0x66, 0x61, 0x63, 0x65, 0x73,
+	0x12, 0x24, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x69, 0x6e,
+	0x67, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x72, 0x65, 0x70, 0x72, 0x6f, 0x63,
+	0x65, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x12, 0x3a, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d,
+	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x41, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x14, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x52, 0x04, 0x6d, 0x65, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x0f,
+	0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x65, 0x64,
+	0x49, 0x6d, 0x61, 0x67, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x63, 0x72, 0x6f, 0x77, 0x64, 0x18, 0x0c,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x6f, 0x77, 0x64, 0x52, 0x05, 0x63, 0x72, 0x6f,
+	0x77, 0x64, 0x12, 0x35, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x0d, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x67, 0x65, 0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67,
+	0x52, 0x07, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x67, 0x72, 0x61,
+	0x79, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x5f, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0e, 0x67, 0x72, 0x61, 0x79, 0x73, 0x63, 0x61, 0x6c, 0x65, 0x49, 0x6d, 0x61,
+	0x67, 0x65, 0x22, 0x49, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x22, 0xb2, 0x01,
+	0x0a, 0x07, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69,
+	0x74, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x64,
+	0x65, 0x74, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x64, 0x65, 0x74,
+	0x61, 0x69, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x69,
+	0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x49, 0x64, 0x22, 0x51, 0x0a, 0x13, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x08, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x66, 0x61,
+	0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x08, 0x72, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x73, 0x22, 0x8d, 0x01, 0x0a, 0x12, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41,
+	0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x3d, 0x0a, 0x08,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f,
+	0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48,
+	0x00, 0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x61, 0x63,
+	0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x6f, 0x75,
+	0x74, 0x63, 0x6f, 0x6d, 0x65, 0x22, 0x54, 0x0a, 0x14, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x6e,
+	0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3c, 0x0a,
+	0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22,
+	0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x22, 0x66, 0x0a, 0x14, 0x41,
+	0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x4f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a,
+	0x05, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x66, 0x72,
+	0x61, 0x6d, 0x65, 0x22, 0xb8, 0x01, 0x0a, 0x15, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a,
+	0x03, 0x73, 0x65, 0x71, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x73, 0x65, 0x71, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x12, 0x39, 0x0a, 0x06, 0x72, 0x65, 0x73,
+	0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x66, 0x61, 0x63, 0x65,
+	0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x00, 0x52, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x2d, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x2a, 0x99,
+	0x01, 0x0a, 0x07, 0x45, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x4d,
+	0x4f, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x4d, 0x4f, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x48,
+	0x41, 0x50, 0x50, 0x59, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x4d, 0x4f, 0x54, 0x49, 0x4f,
+	0x4e, 0x5f, 0x53, 0x41, 0x44, 0x10, 0x02, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x4d, 0x4f, 0x54, 0x49,
+	0x4f, 0x4e, 0x5f, 0x41, 0x4e, 0x47, 0x52, 0x59, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x4d,
+	0x4f, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x55, 0x52, 0x50, 0x52, 0x49, 0x53, 0x45, 0x10, 0x04,
+	0x12, 0x13, 0x0a, 0x0f, 0x45, 0x4d, 0x4f, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x45, 0x55, 0x54,
+	0x52, 0x41, 0x4c, 0x10, 0x05, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x4d, 0x4f, 0x54, 0x49, 0x4f, 0x4e,
+	0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x06, 0x32, 0x9a, 0x02, 0x0a, 0x0f, 0x41,
+	0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4a,
+	0x0a, 0x07, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x12, 0x1e, 0x2e, 0x66, 0x61, 0x63, 0x65,
+	0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x66, 0x61, 0x63, 0x65,
+	0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79,
+	0x7a, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x0c, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x12, 0x23, 0x2e, 0x66, 0x61, 0x63,
+	0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x24, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x60, 0x0a, 0x0d, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x24, 0x2e, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x66,
+	0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e,
+	0x61, 0x6c, 0x79, 0x7a, 0x65, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42, 0x55, 0x5a, 0x53, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x6b, 0x61, 0x6d, 0x79, 0x75, 0x6a, 0x69, 0x2f, 0x66,
+	0x61, 0x63, 0x65, 0x2d, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2d, 0x61, 0x6e, 0x61, 0x6c,
+	0x79, 0x7a, 0x65, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x65,
+	0x6e, 0x2f, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31,
+	0x3b, 0x66, 0x61, 0x63, 0x65, 0x65, 0x6d, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x76, 0x31, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_faceemotion_v1_analyzer_proto_rawDescOnce sync.Once
+	file_faceemotion_v1_analyzer_proto_rawDescData = file_faceemotion_v1_analyzer_proto_rawDesc
+)
+
+func file_faceemotion_v1_analyzer_proto_rawDescGZIP() []byte {
+	file_faceemotion_v1_analyzer_proto_rawDescOnce.Do(func() {
+		file_faceemotion_v1_analyzer_proto_rawDescData = protoimpl.X.CompressGZIP(file_faceemotion_v1_analyzer_proto_rawDescData)
+	})
+	return file_faceemotion_v1_analyzer_proto_rawDescData
+}
+
+var file_faceemotion_v1_analyzer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_faceemotion_v1_analyzer_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_faceemotion_v1_analyzer_proto_goTypes = []any{
+	(Emotion)(0),                  // 0: faceemotion.v1.Emotion
+	(*Point)(nil),                 // 1: faceemotion.v1.Point
+	(*ROI)(nil),                   // 2: faceemotion.v1.ROI
+	(*AnalyzeOptions)(nil),        // 3: faceemotion.v1.AnalyzeOptions
+	(*AnalyzeRequest)(nil),        // 4: faceemotion.v1.AnalyzeRequest
+	(*ImageInfo)(nil),             // 5: faceemotion.v1.ImageInfo
+	(*Box)(nil),                   // 6: faceemotion.v1.Box
+	(*NormalizedBox)(nil),         // 7: faceemotion.v1.NormalizedBox
+	(*Eyes)(nil),                  // 8: faceemotion.v1.Eyes
+	(*FaceExplain)(nil),           // 9: faceemotion.v1.FaceExplain
+	(*Face)(nil),                  // 10: faceemotion.v1.Face
+	(*SessionAggregate)(nil),      // 11: faceemotion.v1.SessionAggregate
+	(*Crowd)(nil),                 // 12: faceemotion.v1.Crowd
+	(*StageTiming)(nil),           // 13: faceemotion.v1.StageTiming
+	(*Meta)(nil),                  // 14: faceemotion.v1.Meta
+	(*AnalyzeResponse)(nil),       // 15: faceemotion.v1.AnalyzeResponse
+	(*Error)(nil),                 // 16: faceemotion.v1.Error
+	(*Problem)(nil),               // 17: faceemotion.v1.Problem
+	(*BatchAnalyzeRequest)(nil),   // 18: faceemotion.v1.BatchAnalyzeRequest
+	(*BatchAnalyzeResult)(nil),    // 19: faceemotion.v1.BatchAnalyzeResult
+	(*BatchAnalyzeResponse)(nil),  // 20: faceemotion.v1.BatchAnalyzeResponse
+	(*AnalyzeStreamRequest)(nil),  // 21: faceemotion.v1.AnalyzeStreamRequest
+	(*AnalyzeStreamResponse)(nil), // 22: faceemotion.v1.AnalyzeStreamResponse
+	nil,                           // 23: faceemotion.v1.FaceExplain.ThresholdsEntry
+	nil,                           // 24: faceemotion.v1.FaceExplain.MarginsEntry
+	nil,                           // 25: faceemotion.v1.SessionAggregate.EmotionCountsEntry
+	nil,                           // 26: faceemotion.v1.Crowd.EmotionsEntry
+	nil,                           // 27: faceemotion.v1.Crowd.DistributionEntry
+}
+var file_faceemotion_v1_analyzer_proto_depIdxs = []int32{
+	1,  // 0: faceemotion.v1.ROI.polygon:type_name -> faceemotion.v1.Point
+	2,  // 1: faceemotion.v1.AnalyzeOptions.roi:type_name -> faceemotion.v1.ROI
+	3,  // 2: faceemotion.v1.AnalyzeRequest.options:type_name -> faceemotion.v1.AnalyzeOptions
+	0,  // 3: faceemotion.v1.FaceExplain.emotion:type_name -> faceemotion.v1.Emotion
+	23, // 4: faceemotion.v1.FaceExplain.thresholds:type_name -> faceemotion.v1.FaceExplain.ThresholdsEntry
+	24, // 5: faceemotion.v1.FaceExplain.margins:type_name -> faceemotion.v1.FaceExplain.MarginsEntry
+	6,  // 6: faceemotion.v1.Face.box:type_name -> faceemotion.v1.Box
+	7,  // 7: faceemotion.v1.Face.normalized_box:type_name -> faceemotion.v1.NormalizedBox
+	0,  // 8: faceemotion.v1.Face.emotion:type_name -> faceemotion.v1.Emotion
+	8,  // 9: faceemotion.v1.Face.eyes:type_name -> faceemotion.v1.Eyes
+	9,  // 10: faceemotion.v1.Face.explain:type_name -> faceemotion.v1.FaceExplain
+	25, // 11: faceemotion.v1.SessionAggregate.emotion_counts:type_name -> faceemotion.v1.SessionAggregate.EmotionCountsEntry
+	26, // 12: faceemotion.v1.Crowd.emotions:type_name -> faceemotion.v1.Crowd.EmotionsEntry
+	27, // 13: faceemotion.v1.Crowd.distribution:type_name -> faceemotion.v1.Crowd.DistributionEntry
+	5,  // 14: faceemotion.v1.AnalyzeResponse.image:type_name -> faceemotion.v1.ImageInfo
+	0,  // 15: faceemotion.v1.AnalyzeResponse.emotion:type_name -> faceemotion.v1.Emotion
+	10, // 16: faceemotion.v1.AnalyzeResponse.faces:type_name -> faceemotion.v1.Face
+	11, // 17: faceemotion.v1.AnalyzeResponse.session:type_name -> faceemotion.v1.SessionAggregate
+	14, // 18: faceemotion.v1.AnalyzeResponse.meta:type_name -> faceemotion.v1.Meta
+	12, // 19: faceemotion.v1.AnalyzeResponse.crowd:type_name -> faceemotion.v1.Crowd
+	13, // 20: faceemotion.v1.AnalyzeResponse.timings:type_name -> faceemotion.v1.StageTiming
+	4,  // 21: faceemotion.v1.BatchAnalyzeRequest.requests:type_name -> faceemotion.v1.AnalyzeRequest
+	15, // 22: faceemotion.v1.BatchAnalyzeResult.response:type_name -> faceemotion.v1.AnalyzeResponse
+	16, // 23: faceemotion.v1.BatchAnalyzeResult.error:type_name -> faceemotion.v1.Error
+	19, // 24: faceemotion.v1.BatchAnalyzeResponse.results:type_name -> faceemotion.v1.BatchAnalyzeResult
+	3,  // 25: faceemotion.v1.AnalyzeStreamRequest.options:type_name -> faceemotion.v1.AnalyzeOptions
+	15, // 26: faceemotion.v1.AnalyzeStreamResponse.result:type_name -> faceemotion.v1.AnalyzeResponse
+	16, // 27: faceemotion.v1.AnalyzeStreamResponse.error:type_name -> faceemotion.v1.Error
+	4,  // 28: faceemotion.v1.AnalyzerService.Analyze:input_type -> faceemotion.v1.AnalyzeRequest
+	18, // 29: faceemotion.v1.AnalyzerService.BatchAnalyze:input_type -> faceemotion.v1.BatchAnalyzeRequest
+	21, // 30: faceemotion.v1.AnalyzerService.AnalyzeStream:input_type -> faceemotion.v1.AnalyzeStreamRequest
+	15, // 31: faceemotion.v1.AnalyzerService.Analyze:output_type -> faceemotion.v1.AnalyzeResponse
+	20, // 32: faceemotion.v1.AnalyzerService.BatchAnalyze:output_type -> faceemotion.v1.BatchAnalyzeResponse
+	22, // 33: faceemotion.v1.AnalyzerService.AnalyzeStream:output_type -> faceemotion.v1.AnalyzeStreamResponse
+	31, // [31:34] is the sub-list for method output_type
+	28, // [28:31] is the sub-list for method input_type
+	28, // [28:28] is the sub-list for extension type_name
+	28, // [28:28] is the sub-list for extension extendee
+	0,  // [0:28] is the sub-list for field type_name
+}
+
+func init() { file_faceemotion_v1_analyzer_proto_init() }
+func file_faceemotion_v1_analyzer_proto_init() {
+	if File_faceemotion_v1_analyzer_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_faceemotion_v1_analyzer_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Point); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ROI); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*AnalyzeOptions); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*AnalyzeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ImageInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*Box); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*NormalizedBox); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*Eyes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*FaceExplain); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*Face); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*SessionAggregate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*Crowd); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*StageTiming); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*Meta); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*AnalyzeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*Problem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*BatchAnalyzeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*BatchAnalyzeResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*BatchAnalyzeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*AnalyzeStreamRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_faceemotion_v1_analyzer_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*AnalyzeStreamResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_faceemotion_v1_analyzer_proto_msgTypes[18].OneofWrappers = []any{
+		(*BatchAnalyzeResult_Response)(nil),
+		(*BatchAnalyzeResult_Error)(nil),
+	}
+	file_faceemotion_v1_analyzer_proto_msgTypes[21].OneofWrappers = []any{
+		(*AnalyzeStreamResponse_Result)(nil),
+		(*AnalyzeStreamResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_faceemotion_v1_analyzer_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_faceemotion_v1_analyzer_proto_goTypes,
+		DependencyIndexes: file_faceemotion_v1_analyzer_proto_depIdxs,
+		EnumInfos:         file_faceemotion_v1_analyzer_proto_enumTypes,
+		MessageInfos:      file_faceemotion_v1_analyzer_proto_msgTypes,
+	}.Build()
+	File_faceemotion_v1_analyzer_proto = out.File
+	file_faceemotion_v1_analyzer_proto_rawDesc = nil
+	file_faceemotion_v1_analyzer_proto_goTypes = nil
+	file_faceemotion_v1_analyzer_proto_depIdxs = nil
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+)
+
+// /api/v1 のバージョン
+const APIVersionV1 = "v1"
+
+// サーバーと分析モデルのバージョン情報
+type ModelInfo struct {
+	ServerVersion string   `json:"serverVersion"`
+	Detectors     []string `json:"detectors"`
+	Pipeline      []string `json:"pipeline"`
+}
+
+// /api/v1 のレスポンスに含めるメタデータ
+type MetaV1 struct {
+	APIVersion string `json:"apiVersion"`
+	ModelInfo
+}
+
+// /api/v1/analyze のレスポンス
+// 表示用の文字列を含めず、感情などは列挙値で返す
+type AnalyzeResponseV1 struct {
+	Image          ImageInfoV1           `json:"image"`
+	Emotion        string                `json:"emotion"`
+	Confidence     float64               `json:"confidence"`
+	PrimaryIndex   int                   `json:"primaryIndex"`
+	PrimaryTrackID int                   `json:"primaryTrackId,omitempty"`
+	PrimaryPolicy  string                `json:"primaryPolicy,omitempty"`
+	Faces          []FaceV1              `json:"faces"`
+	Preprocessing  []string              `json:"preprocessing"`
+	ProcessedImage string                `json:"processedImage,omitempty" msgpack:"-"`
+	Session        *session.Aggregate    `json:"session,omitempty"`
+	Crowd          *CrowdResponse        `json:"crowd,omitempty"`
+	Timings        []StageTimingResponse `json:"timings,omitempty"`
+	GrayscaleImage string                `json:"grayscaleImage,omitempty"`
+	Meta           MetaV1                `json:"meta"`
+	// バイナリの形式の場合にエンコードせずに返す処理済み画像
+	ProcessedImageData []byte `json:"-" msgpack:"processedImage,omitempty"`
+}
+
+// 入力画像のサイズ（ピクセル）
+type ImageInfoV1 struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ピクセル単位の顔の矩形
+type BoxV1 struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// 画像のサイズで正規化した顔の矩形（0〜1）
+type NormalizedBoxV1 struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// 検出された顔ごとの分析結果
+type FaceV1 struct {
+	Box           BoxV1           `json:"box"`
+	NormalizedBox NormalizedBoxV1 `json:"normalizedBox"`
+	Emotion       string          `json:"emotion"`
+	Detector      string          `json:"detector"`
+	Score         float64         `json:"score"`
+	TrackID       int             `json:"trackId,omitempty"`
+	Quality       float64         `json:"quality"`
+	Engagement    float64         `json:"engagement"`
+	ParticipantID string          `json:"participantId,omitempty"`
+	Similarity    float64         `json:"similarity,omitempty"`
+	Eyes          *EyesRegion     `json:"eyes,omitempty"`
+	Occluded      bool            `json:"occluded"`
+	Masked        bool            `json:"masked"`
+	// explain を指定した場合のみ設定する
+	Explain *FaceExplainResponse `json:"explain,omitempty"`
+}
+
+// /api/v1 のルーティングを作成
+// wrap でセキュリティミドルウェアなどを各ハンドラーに適用する
+// webhooks が nil の場合は Webhook のルートを追加しない
+func NewAPIv1Router(
+	face *FaceHandler,
+	gallery *GalleryHandler,
+	sessions *SessionHandler,
+	jobs *JobHandler,
+	streams *StreamHandler,
+	events *SessionEventsHandler,
+	webhooks *WebhookHandler,
+	wrap func(http.HandlerFunc) http.HandlerFunc,
+) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("POST /analyze", wrap(face.HandleAnalyzeV1))
+	mux.Handle("GET /info", wrap(face.HandleInfoV1))
+	mux.Handle("GET /sessions/{id}", wrap(sessions.HandleGet))
+	mux.Handle("GET /sessions/{id}/events", wrap(events.Handle))
+	mux.Handle("POST /gallery/enroll", wrap(gallery.HandleEnroll))
+	mux.Handle("GET /gallery", wrap(gallery.HandleList))
+	mux.Handle("DELETE /gallery/{id}", wrap(gallery.HandleDelete))
+	mux.Handle("POST /jobs", wrap(jobs.HandleCreate))
+	mux.Handle("GET /jobs/{id}", wrap(jobs.HandleGet))
+	mux.Handle("DELETE /jobs/{id}", wrap(jobs.HandleCancel))
+	mux.Handle("GET /stream", wrap(streams.Handle))
+	if webhooks != nil {
+		mux.Handle("POST /webhooks", wrap(webhooks.HandleCreate))
+		mux.Handle("GET /webhooks", wrap(webhooks.HandleList))
+		mux.Handle("DELETE /webhooks/{id}", wrap(webhooks.HandleDelete))
+		if webhooks.adminToken != "" {
+			mux.Handle("GET /admin/webhooks/dead-letters", wrap(webhooks.HandleDeadLetters))
+		}
+	}
+	mux.Handle("/", wrap(notFoundV1(mux)))
+	return http.StripPrefix("/api/v1", mux)
+}
+
+// どのルートにも一致しないリクエストにエラーを返す
+// 他のメソッドであれば一致する場合は 405 とする
+func notFoundV1(mux *http.ServeMux) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodDelete} {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "/" {
+				errors.WriteProblem(w, r, errMethodNotAllowed)
+				return
+			}
+		}
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeNotFound, "リソースが見つかりません", nil))
+	}
+}
+
+// サーバーと分析モデルのバージョン情報を設定
+func (h *FaceHandler) SetModelInfo(info ModelInfo) {
+	h.modelInfo = info
+}
+
+func (h *FaceHandler) metaV1() MetaV1 {
+	return MetaV1{APIVersion: APIVersionV1, ModelInfo: h.modelInfo}
+}
+
+// サーバーと分析モデルのバージョン情報を返す
+func (h *FaceHandler) HandleInfoV1(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.metaV1()); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
+
+// 画像を分析し、/api/v1 の形式で結果を返す
+func (h *FaceHandler) HandleAnalyzeV1(w http.ResponseWriter, r *http.Request) {
+	req, results, ok := h.runAnalyze(w, r)
+	if !ok {
+		return
+	}
+
+	mediaType := codec.Negotiate(r.Header.Get("Accept"))
+	response := h.responseV1(req, results, mediaType)
+	h.notifyAnalysis(r.Header.Get(APIKeyHeader), response)
+
+	if mediaType == codec.MediaTypeProtobuf {
+		writeResponse(w, r, mediaType, toAnalyzeResponseProto(&response))
+		return
+	}
+	writeResponse(w, r, mediaType, response)
+}
+
+// 分析結果から /api/v1 のレスポンスを作成
+// セッションが指定されていれば集計にも記録する
+// mediaType は処理済み画像をエンコードするかの判定に使用する
+func (h *FaceHandler) responseV1(req AnalyzeRequest, results *analyzer.AnalysisResult, mediaType string) AnalyzeResponseV1 {
+	width, height := imageSize(results)
+	response := AnalyzeResponseV1{
+		Image:          ImageInfoV1{Width: width, Height: height},
+		Emotion:        string(results.PrimaryEmotion),
+		Confidence:     float64(results.Confidence),
+		PrimaryIndex:   results.PrimaryIndex,
+		PrimaryTrackID: results.PrimaryTrackID,
+		PrimaryPolicy:  results.PrimaryPolicy,
+		Faces:          h.facesV1(results, width, height),
+		Preprocessing:  results.Preprocessing,
+		Session:        h.recordSession(req.SessionID, results),
+		Crowd:          toCrowdResponse(results.Crowd),
+		Timings:        h.stageTimings(results),
+		GrayscaleImage: pngDataURL(results.GrayscaleImage),
+		Meta:           h.metaV1(),
+	}
+	if len(results.Faces) == 0 {
+		response.Emotion = string(analyzer.EmotionUnknown)
+		response.PrimaryIndex = -1
+	}
+	if response.Preprocessing == nil {
+		response.Preprocessing = []string{}
+	}
+	response.ProcessedImage, response.ProcessedImageData = processedImage(mediaType, results.ProcessedImageData)
+	return response
+}
+
+// 検出された顔を /api/v1 の形式に変換
+func (h *FaceHandler) facesV1(results *analyzer.AnalysisResult, width, height int) []FaceV1 {
+	faces := make([]FaceV1, len(results.Faces))
+	for i, face := range results.Faces {
+		f := FaceV1{
+			Box:           pixelBox(face),
+			NormalizedBox: normalizedBox(face, width, height),
+			Emotion:       string(face.Emotion),
+			Detector:      face.Detector,
+			Score:         face.DetectionScore,
+			TrackID:       face.TrackID,
+			Quality:       face.Quality,
+			Engagement:    face.Engagement,
+			Eyes:          toEyesRegion(face.Eyes),
+			Explain:       toFaceExplainResponse(face),
+		}
+		if face.Occlusion != nil {
+			f.Occluded = face.Occlusion.Occluded
+			f.Masked = face.Occlusion.Masked
+		}
+		if match, ok := h.matchParticipant(face); ok {
+			f.ParticipantID = match.ID
+			f.Similarity = match.Similarity
+		}
+		faces[i] = f
+	}
+	return faces
+}
+
+func pixelBox(face analyzer.Face) BoxV1 {
+	return BoxV1{
+		X:      int(math.Round(face.X)),
+		Y:      int(math.Round(face.Y)),
+		Width:  int(math.Round(face.Width)),
+		Height: int(math.Round(face.Height)),
+	}
+}
+
+// 画像のサイズが不明な場合はゼロ値を返す
+func normalizedBox(face analyzer.Face, width, height int) NormalizedBoxV1 {
+	if width <= 0 || height <= 0 {
+		return NormalizedBoxV1{}
+	}
+	w := float64(width)
+	h := float64(height)
+	return NormalizedBoxV1{
+		X:      face.X / w,
+		Y:      face.Y / h,
+		Width:  face.Width / w,
+		Height: face.Height / h,
+	}
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/gallery"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIv1Router(t *testing.T, face *FaceHandler) http.Handler {
+	t.Helper()
+	g, err := gallery.Open("")
+	require.NoError(t, err)
+	passThrough := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	return NewAPIv1Router(face, NewGalleryHandler(&mockFaceAnalyzer{}, g),
+		NewSessionHandler(session.NewStore(time.Minute, 0.5, 0)), newTestJobHandler(t, face),
+		NewStreamHandler(face, 0, 1), NewSessionEventsHandler(stream.NewHub(0, 1), 0), nil, passThrough)
+}
+
+func TestAPIv1_HandleAnalyze(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name        string
+		result      *analyzer.AnalysisResult
+		wantEmotion string
+		wantFaces   []FaceV1
+	}{
+		{
+			name: "顔あり",
+			result: &analyzer.AnalysisResult{
+				Faces: []analyzer.Face{{
+					X: 40, Y: 30, Width: 80, Height: 60.4,
+					Detector: analyzer.DetectorFrontal, DetectionScore: 0.8,
+					Quality: 0.7, Emotion: analyzer.EmotionHappy, Engagement: 0.6,
+					Occlusion: &analyzer.OcclusionAnalysis{Occluded: true},
+				}},
+				PrimaryEmotion: analyzer.EmotionHappy,
+				Confidence:     0.9,
+				PrimaryPolicy:  analyzer.PrimaryLargest,
+				ImageWidth:     320,
+				ImageHeight:    240,
+			},
+			wantEmotion: "happy",
+			wantFaces: []FaceV1{{
+				Box:           BoxV1{X: 40, Y: 30, Width: 80, Height: 60},
+				NormalizedBox: NormalizedBoxV1{X: 0.125, Y: 0.125, Width: 0.25, Height: 60.4 / 240},
+				Emotion:       "happy",
+				Detector:      analyzer.DetectorFrontal,
+				Score:         0.8,
+				Quality:       0.7,
+				Engagement:    0.6,
+				Occluded:      true,
+			}},
+		},
+		{
+			name: "顔なし",
+			result: &analyzer.AnalysisResult{
+				PrimaryEmotion: analyzer.EmotionUnknown,
+				PrimaryIndex:   -1,
+				ImageWidth:     320,
+				ImageHeight:    240,
+			},
+			wantEmotion: "unknown",
+			wantFaces:   []FaceV1{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return tt.result, nil
+				},
+			}
+			face := NewFaceHandler(mockRenderer, mockAnalyzer)
+			face.SetModelInfo(ModelInfo{ServerVersion: "1.2.3", Detectors: []string{"frontal"}, Pipeline: []string{"decode"}})
+			router := newTestAPIv1Router(t, face)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, createTestRequest(t, http.MethodPost, "/api/v1/analyze", map[string]interface{}{"image": imageData}))
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var resp AnalyzeResponseV1
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tt.wantEmotion, resp.Emotion)
+			assert.Equal(t, ImageInfoV1{Width: 320, Height: 240}, resp.Image)
+			assert.Equal(t, tt.wantFaces, resp.Faces)
+			assert.NotNil(t, resp.Preprocessing)
+			assert.Equal(t, "v1", resp.Meta.APIVersion)
+			assert.Equal(t, "1.2.3", resp.Meta.ServerVersion)
+			assert.Equal(t, []string{"frontal"}, resp.Meta.Detectors)
+		})
+	}
+}
+
+func TestAPIv1_Routing(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	face := NewFaceHandler(mockRenderer, &mockFaceAnalyzer{})
+	face.SetModelInfo(ModelInfo{ServerVersion: "1.2.3"})
+	router := newTestAPIv1Router(t, face)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"バージョン情報", http.MethodGet, "/api/v1/info", http.StatusOK},
+		{"ギャラリーの一覧", http.MethodGet, "/api/v1/gallery", http.StatusOK},
+		{"存在しないセッション", http.MethodGet, "/api/v1/sessions/missing", http.StatusNotFound},
+		{"分析はPOSTのみ", http.MethodGet, "/api/v1/analyze", http.StatusMethodNotAllowed},
+		{"存在しないパス", http.MethodGet, "/api/v1/unknown", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, createTestRequest(t, tt.method, tt.path, nil))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				// ルートに一致しない場合もエラーは problem+json で返す
+				decodeProblem(t, rec)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, createTestRequest(t, http.MethodGet, "/api/v1/info", nil))
+	var meta MetaV1
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&meta))
+	assert.Equal(t, MetaV1{APIVersion: "v1", ModelInfo: ModelInfo{ServerVersion: "1.2.3"}}, meta)
+}
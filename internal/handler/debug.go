@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+)
+
+// デバッグ用エンドポイントのハンドラー
+type DebugHandler struct{}
+
+func NewDebugHandler() *DebugHandler {
+	return &DebugHandler{}
+}
+
+type MatDebugResponse struct {
+	// matprofile タグ付きでビルドした場合のみ計測する
+	ProfileEnabled bool `json:"profileEnabled"`
+	Live           int  `json:"live"`
+	// stacks=1 を指定した場合のみ、生存中のMatを生成したスタックトレースを含める
+	Stacks string `json:"stacks,omitempty"`
+}
+
+// 生存中のOpenCVのMat数を返す
+func (h *DebugHandler) HandleMats(w http.ResponseWriter, r *http.Request) {
+	live, enabled := analyzer.MatProfileCount()
+	response := MatDebugResponse{
+		ProfileEnabled: enabled,
+		Live:           live,
+	}
+
+	if r.URL.Query().Get("stacks") == "1" {
+		var stacks strings.Builder
+		if err := analyzer.WriteMatProfile(&stacks); err != nil {
+			errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "failed to write mat profile", err))
+			return
+		}
+		response.Stacks = stacks.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
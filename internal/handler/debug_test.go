@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler_HandleMats(t *testing.T) {
+	handler := NewDebugHandler()
+
+	rec := httptest.NewRecorder()
+	handler.HandleMats(rec, createTestRequest(t, http.MethodGet, "/debug/mats?stacks=1", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp MatDebugResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	_, enabled := analyzer.MatProfileCount()
+	assert.Equal(t, enabled, resp.ProfileEnabled)
+	if !enabled {
+		assert.Zero(t, resp.Live)
+		assert.Empty(t, resp.Stacks)
+	}
+}
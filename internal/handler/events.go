@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+)
+
+// ハートビートのデフォルトの間隔
+const defaultHeartbeatInterval = 15 * time.Second
+
+// ハートビートのイベントの種類
+const eventHeartbeat = "heartbeat"
+
+// セッションのイベントをServer-Sent Eventsで配信するハンドラー
+type SessionEventsHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+func NewSessionEventsHandler(hub *stream.Hub, heartbeat time.Duration) *SessionEventsHandler {
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeatInterval
+	}
+	return &SessionEventsHandler{hub: hub, heartbeat: heartbeat}
+}
+
+// SSEで配信するセッションの分析結果
+type SessionResultEvent struct {
+	SessionID    string             `json:"sessionId"`
+	Emotion      string             `json:"emotion"`
+	Confidence   float64            `json:"confidence"`
+	PrimaryIndex int                `json:"primaryIndex"`
+	Faces        []FaceV1           `json:"faces"`
+	Session      *session.Aggregate `json:"session,omitempty"`
+	AnalyzedAt   time.Time          `json:"analyzedAt"`
+}
+
+// ハートビートのイベントのデータ
+type HeartbeatEvent struct {
+	Time time.Time `json:"time"`
+	// バッファが一杯で破棄したイベントの数
+	Dropped int64 `json:"dropped"`
+}
+
+// セッションを購読し、分析結果と平滑化した感情の変化を配信する
+// セッションが始まる前から購読できる
+func (h *SessionEventsHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	sessionID := r.PathValue("id")
+	if !validSessionID.MatchString(sessionID) {
+		errors.WriteProblem(w, r, invalidRequest("invalid session id"))
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// サーバーの書き込みのタイムアウトで配信が切れないようにする
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "failed to start event stream", err))
+		return
+	}
+
+	sub := h.hub.Subscribe(sessionID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// リバースプロキシのバッファリングを無効にする
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		slog.Error("イベントの送信に失敗", "error", err)
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	var id uint64
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			id++
+			err = writeSSE(w, id, event.Type, event.Data)
+		case now := <-ticker.C:
+			err = writeSSE(w, 0, eventHeartbeat, HeartbeatEvent{Time: now, Dropped: sub.Dropped()})
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			slog.Debug("イベントの配信を終了", "error", err)
+			return
+		}
+	}
+}
+
+// イベントを text/event-stream の形式で書き込む
+// id が0の場合は id フィールドを省略する
+func writeSSE(w io.Writer, id uint64, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("イベントのエンコードに失敗: %w", err)
+	}
+	if id > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// セッションの分析結果を購読者に配信
+func (h *FaceHandler) publishSession(sessionID string, results *analyzer.AnalysisResult, agg *session.Aggregate) {
+	if h.events == nil || !h.events.HasSubscribers(sessionID) {
+		return
+	}
+
+	width, height := imageSize(results)
+	event := SessionResultEvent{
+		SessionID:    sessionID,
+		Emotion:      string(results.PrimaryEmotion),
+		Confidence:   float64(results.Confidence),
+		PrimaryIndex: results.PrimaryIndex,
+		Faces:        h.facesV1(results, width, height),
+		Session:      agg,
+		AnalyzedAt:   time.Now(),
+	}
+	if len(results.Faces) == 0 {
+		event.Emotion = string(analyzer.EmotionUnknown)
+		event.PrimaryIndex = -1
+	}
+	h.events.Publish(sessionID, event.Emotion, event)
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// text/event-stream から1つのイベントを読み取る
+func readSSE(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var e sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return e
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		switch name {
+		case "id":
+			e.id = value
+		case "event":
+			e.event = value
+		case "data":
+			e.data = value
+		}
+	}
+}
+
+// イベントの配信を購読
+func subscribeTestEvents(t *testing.T, hub *stream.Hub, heartbeat time.Duration, sessionID string) *bufio.Reader {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sessions/{id}/events", NewSessionEventsHandler(hub, heartbeat).Handle)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "/sessions/" + sessionID + "/events")
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	return bufio.NewReader(resp.Body)
+}
+
+func TestSessionEventsHandler(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+
+	hub := stream.NewHub(0, 1)
+	face := NewFaceHandler(mockRenderer, mockAnalyzer)
+	face.SetSessionStore(session.NewStore(time.Minute, 0.5, 0))
+	face.SetEventHub(hub)
+
+	reader := subscribeTestEvents(t, hub, time.Hour, "s1")
+	require.Eventually(t, func() bool { return hub.HasSubscribers("s1") }, 5*time.Second, 10*time.Millisecond)
+
+	body := map[string]interface{}{"image": testImageDataURL(t), "sessionId": "s1"}
+	rec := httptest.NewRecorder()
+	face.HandleAnalyzeV1(rec, createTestRequest(t, http.MethodPost, "/api/v1/analyze", body))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// 分析結果
+	e := readSSE(t, reader)
+	assert.Equal(t, "1", e.id)
+	assert.Equal(t, stream.EventResult, e.event)
+	var result SessionResultEvent
+	require.NoError(t, json.Unmarshal([]byte(e.data), &result))
+	assert.Equal(t, "s1", result.SessionID)
+	assert.Equal(t, "happy", result.Emotion)
+	assert.Len(t, result.Faces, 1)
+	require.NotNil(t, result.Session)
+	assert.Equal(t, 1, result.Session.Frames)
+
+	// 平滑化した感情の変化
+	e = readSSE(t, reader)
+	assert.Equal(t, "2", e.id)
+	assert.Equal(t, stream.EventEmotion, e.event)
+	var change stream.EmotionChange
+	require.NoError(t, json.Unmarshal([]byte(e.data), &change))
+	assert.Equal(t, "happy", change.Emotion)
+	assert.Empty(t, change.Previous)
+}
+
+func TestSessionEventsHandler_Heartbeat(t *testing.T) {
+	hub := stream.NewHub(0, 1)
+	reader := subscribeTestEvents(t, hub, 10*time.Millisecond, "s1")
+
+	e := readSSE(t, reader)
+	assert.Empty(t, e.id)
+	assert.Equal(t, eventHeartbeat, e.event)
+	var heartbeat HeartbeatEvent
+	require.NoError(t, json.Unmarshal([]byte(e.data), &heartbeat))
+	assert.False(t, heartbeat.Time.IsZero())
+}
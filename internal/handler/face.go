@@ -1,44 +1,172 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
-	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"strings"
 
+	"github.com/okamyuji/face-emotion-analyzer/config"
 	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/fetch"
+	"github.com/okamyuji/face-emotion-analyzer/internal/gallery"
 	"github.com/okamyuji/face-emotion-analyzer/internal/middleware"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"github.com/okamyuji/face-emotion-analyzer/internal/webhook"
 	"gocv.io/x/gocv"
 )
 
+// リクエストボディと画像データのサイズ上限（5MB）
+const maxRequestSize = 5 * 1024 * 1024
+
+var errMethodNotAllowed = errors.CodeError(errors.ErrCodeMethodNotAllowed, "メソッドは許可されていません", nil)
+
 type FaceHandler struct {
-	renderer TemplateRendererInterface
-	analyzer analyzer.FaceAnalyzerInterface
+	renderer       TemplateRendererInterface
+	analyzer       analyzer.FaceAnalyzerInterface
+	gallery        *gallery.Gallery
+	matchThreshold float64
+	sessions       *session.Store
+	metrics        EngagementRecorder
+	// デバッグモードではパイプラインの段階ごとの処理時間をレスポンスに含める
+	debug bool
+	// 判定根拠と中間画像の出力を許可するか
+	explainEnabled bool
+	explainImages  bool
+	// 群衆モードを許可するか
+	crowdEnabled bool
+	// /api/v1 のレスポンスに含めるバージョン情報
+	modelInfo ModelInfo
+	// セッションの分析結果の配信先
+	events *stream.Hub
+	// imageUrl の画像を取得する（nil の場合は imageUrl を受け付けない）
+	fetcher *fetch.Fetcher
+	// 分析とジョブの完了を通知する（nil の場合は通知しない）
+	webhooks *webhook.Dispatcher
+}
+
+// エンゲージメントスコアを記録するメトリクスのインターフェース
+type EngagementRecorder interface {
+	RecordEngagement(score float64)
 }
 
 type AnalyzeRequest struct {
 	Image string `json:"image"`
+	// 画像の代わりに指定する画像のURL（設定で有効な場合のみ）
+	ImageURL      string `json:"imageUrl,omitempty"`
+	PrimaryPolicy string `json:"primaryPolicy,omitempty"`
+	TrackID       int    `json:"trackId,omitempty"`
+	SessionID     string `json:"sessionId,omitempty"`
+	// 各顔の判定根拠を含める（設定で有効な場合のみ）
+	Explain bool `json:"explain,omitempty"`
+	// 判定根拠に中間画像を含める
+	ExplainImages bool `json:"explainImages,omitempty"`
+	// 分析対象領域（省略時は設定ファイルの値を使用）
+	ROI *ROIRequest `json:"roi,omitempty"`
+	// 群衆モードで分析する（設定で有効な場合のみ）
+	Crowd bool `json:"crowd,omitempty"`
+	// 処理済み画像のラベルの言語（ja、en）。省略時は Accept-Language ヘッダーから選択する
+	Locale string `json:"locale,omitempty"`
+	// ジョブの終了を通知するURL（/api/v1/jobs のみ）
+	CallbackURL string `json:"callbackUrl,omitempty"`
+}
+
+// 正規化座標（0〜1）の矩形または多角形で指定する分析対象領域
+type ROIRequest struct {
+	X       float64      `json:"x"`
+	Y       float64      `json:"y"`
+	Width   float64      `json:"width"`
+	Height  float64      `json:"height"`
+	Polygon [][2]float64 `json:"polygon,omitempty"`
 }
 
 type AnalyzeResponse struct {
 	Emotion        string       `json:"emotion"`
 	Confidence     float64      `json:"confidence"`
 	Faces          []FaceRegion `json:"faces"`
-	ProcessedImage string       `json:"processedImage"` // Base64エンコードされた画像
+	ProcessedImage string       `json:"processedImage" msgpack:"-"` // Base64エンコードされた画像
+	Preprocessing  []string     `json:"preprocessing,omitempty"`
+	PrimaryIndex   int          `json:"primaryIndex"`
+	PrimaryTrackID int          `json:"primaryTrackId,omitempty"`
+	PrimaryPolicy  string       `json:"primaryPolicy,omitempty"`
+	// sessionId を指定した場合のセッション全体の集計結果
+	Session *session.Aggregate `json:"session,omitempty"`
+	// デバッグモードの場合のみ設定する
+	Timings []StageTimingResponse `json:"timings,omitempty"`
+	// explainImages を指定した場合の前処理後のグレースケール画像
+	GrayscaleImage string `json:"grayscaleImage,omitempty"`
+	// crowd を指定した場合の群衆全体の集計
+	Crowd *CrowdResponse `json:"crowd,omitempty"`
+	// MessagePack の場合にエンコードせずに返す処理済み画像
+	ProcessedImageData []byte `json:"-" msgpack:"processedImage,omitempty"`
+}
+
+// 群衆全体の顔の数と感情の分布
+type CrowdResponse struct {
+	FaceCount    int                `json:"faceCount"`
+	Emotions     map[string]int     `json:"emotions"`
+	Distribution map[string]float64 `json:"distribution"`
+	Tiles        int                `json:"tiles"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// パイプラインの段階ごとの処理時間
+type StageTimingResponse struct {
+	Stage      string  `json:"stage"`
+	DurationMs float64 `json:"durationMs"`
 }
 
 type FaceRegion struct {
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Width  float64 `json:"width"`
-	Height float64 `json:"height"`
+	X        float64 `json:"x"`
+	Y        float64 `json:"y"`
+	Width    float64 `json:"width"`
+	Height   float64 `json:"height"`
+	Detector string  `json:"detector,omitempty"`
+	Score    float64 `json:"score,omitempty"`
+	TrackID  int     `json:"trackId,omitempty"`
+	Quality  float64 `json:"quality"`
+	// 0〜1のエンゲージメントスコア
+	Engagement float64 `json:"engagement"`
+	// ギャラリーに登録された人物と一致した場合のみ設定する
+	ParticipantID string  `json:"participantId,omitempty"`
+	Similarity    float64 `json:"similarity,omitempty"`
+	// 目の推定が有効な場合のみ設定する
+	Eyes *EyesRegion `json:"eyes,omitempty"`
+	// 遮蔽検出が有効な場合のみ設定する
+	Occluded bool `json:"occluded,omitempty"`
+	Masked   bool `json:"masked,omitempty"`
+	// explain を指定した場合のみ設定する
+	Explain *FaceExplainResponse `json:"explain,omitempty"`
+}
+
+// 顔ごとの判定根拠
+type FaceExplainResponse struct {
+	Brightness        float64            `json:"brightness"`
+	Variation         float64            `json:"variation"`
+	SmileScore        float64            `json:"smileScore"`
+	LaplacianVariance float64            `json:"laplacianVariance"`
+	Sharpness         float64            `json:"sharpness"`
+	SizeScore         float64            `json:"sizeScore"`
+	Quality           float64            `json:"quality"`
+	Emotion           string             `json:"emotion"`
+	Rule              string             `json:"rule"`
+	Thresholds        map[string]float64 `json:"thresholds"`
+	Margins           map[string]float64 `json:"margins"`
+	// explainImages を指定した場合の平坦化した顔領域
+	ROIImage string `json:"roiImage,omitempty"`
+}
+
+type EyesRegion struct {
+	Openness float64 `json:"openness"`
+	State    string  `json:"state"`
+	Gaze     string  `json:"gaze"`
 }
 
 func NewFaceHandler(
@@ -51,6 +179,58 @@ func NewFaceHandler(
 	}
 }
 
+// 顔の埋め込みを照合するギャラリーを設定
+// 類似度がthreshold以上の人物を各顔の参加者IDとしてレスポンスに含める
+// 閾値が0以下の場合はデフォルト値を使用する
+func (h *FaceHandler) SetGallery(g *gallery.Gallery, threshold float64) {
+	if threshold <= 0 {
+		threshold = config.DefaultMatchThreshold
+	}
+	h.gallery = g
+	h.matchThreshold = threshold
+}
+
+// セッションごとの集計を行うストアを設定
+func (h *FaceHandler) SetSessionStore(store *session.Store) {
+	h.sessions = store
+}
+
+// エンゲージメントスコアを記録するメトリクスを設定
+func (h *FaceHandler) SetMetrics(recorder EngagementRecorder) {
+	h.metrics = recorder
+}
+
+// デバッグモードを設定
+func (h *FaceHandler) SetDebug(debug bool) {
+	h.debug = debug
+}
+
+// 判定根拠と中間画像の出力を許可するかを設定
+func (h *FaceHandler) SetExplain(enabled, images bool) {
+	h.explainEnabled = enabled
+	h.explainImages = enabled && images
+}
+
+// セッションの分析結果の配信先を設定
+func (h *FaceHandler) SetEventHub(hub *stream.Hub) {
+	h.events = hub
+}
+
+// 群衆モードを許可するかを設定
+func (h *FaceHandler) SetCrowd(enabled bool) {
+	h.crowdEnabled = enabled
+}
+
+// imageUrl の画像を取得するフェッチャーを設定
+func (h *FaceHandler) SetImageFetcher(f *fetch.Fetcher) {
+	h.fetcher = f
+}
+
+// 分析とジョブの完了を通知するディスパッチャーを設定
+func (h *FaceHandler) SetWebhooks(d *webhook.Dispatcher) {
+	h.webhooks = d
+}
+
 // CSRFトークンを生成
 func generateToken() string {
 	b := make([]byte, 32)
@@ -63,19 +243,18 @@ func generateToken() string {
 // メインページのハンドラ
 func (h *FaceHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "メソッドは許可されていません", http.StatusMethodNotAllowed)
+		errors.WriteProblem(w, r, errMethodNotAllowed)
 		return
 	}
 
 	if r.URL.Path != "/" {
-		http.Error(w, "ページが見つかりません", http.StatusNotFound)
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeNotFound, "ページが見つかりません", nil))
 		return
 	}
 
 	nonce, ok := r.Context().Value(middleware.CSPNonceKey).(string)
 	if !ok {
-		slog.Error("CSPノンスの取得に失敗")
-		http.Error(w, "内部サーバーエラー", http.StatusInternalServerError)
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "CSPノンスの取得に失敗", nil))
 		return
 	}
 
@@ -89,189 +268,428 @@ func (h *FaceHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.renderer.ExecuteTemplate(w, "index.html", data); err != nil {
-		slog.Error("テンプレート実行エラー", "error", err)
-		http.Error(w, "内部サーバーエラー", http.StatusInternalServerError)
-	}
-}
-
-// エラーレスポンスを送信する共通関数
-func sendErrorResponse(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(ErrorResponse{Error: message}); err != nil {
-		slog.Error("エラーレスポンスの送信に失敗", "error", err)
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "テンプレート実行エラー", err))
 	}
 }
 
+// 旧形式の分析エンドポイント
+// 既存のWeb UIとの互換性のために残し、後継の /api/v1/analyze を Link ヘッダーで案内する
 func (h *FaceHandler) HandleAnalyze(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	if r.Method != http.MethodPost {
-		sendErrorResponse(w, http.StatusMethodNotAllowed, "メソッドは許可されていません")
+	w.Header().Set("Link", `</api/v1/analyze>; rel="successor-version"`)
+	req, results, ok := h.runAnalyze(w, r)
+	if !ok {
 		return
 	}
 
-	// Content-Typeの検証
-	contentType := r.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		slog.Error("不正なContent-Type", "content_type", contentType)
-		sendErrorResponse(w, http.StatusBadRequest, "invalid content type")
+	// Protobuf のスキーマは /api/v1 の形式のみ定義しているため、その形式で返す
+	mediaType := codec.Negotiate(r.Header.Get("Accept"))
+	if mediaType == codec.MediaTypeProtobuf {
+		h.writeAnalyzeProto(w, r, req, results)
 		return
 	}
+	writeResponse(w, r, mediaType, h.analyzeResponse(req, results, mediaType))
+}
 
-	// リクエストボディの読み取り前にデバッグログ
-	slog.Debug("リクエスト受信",
-		"content_length", r.ContentLength,
-		"content_type", contentType,
-		"csrf_token", r.Header.Get("X-CSRF-Token"),
-		"transfer_encoding", r.TransferEncoding)
+// 分析結果から旧形式のレスポンスを作成
+// セッションが指定されていれば集計にも記録する
+func (h *FaceHandler) analyzeResponse(req AnalyzeRequest, results *analyzer.AnalysisResult, mediaType string) AnalyzeResponse {
+	// レスポンスの構築
+	width, height := imageSize(results)
+	response := AnalyzeResponse{
+		Emotion:        EmotionToString(results.PrimaryEmotion),
+		Confidence:     float64(results.Confidence),
+		Faces:          h.faceRegions(results, width, height),
+		Preprocessing:  results.Preprocessing,
+		PrimaryIndex:   results.PrimaryIndex,
+		PrimaryTrackID: results.PrimaryTrackID,
+		PrimaryPolicy:  results.PrimaryPolicy,
+		Session:        h.recordSession(req.SessionID, results),
+		Timings:        h.stageTimings(results),
+		GrayscaleImage: pngDataURL(results.GrayscaleImage),
+		Crowd:          toCrowdResponse(results.Crowd),
+	}
+	// 顔が検出されなかった場合
+	if len(results.Faces) == 0 {
+		response.Emotion = EmotionToString(analyzer.EmotionUnknown)
+		response.PrimaryIndex = -1
+	}
+	response.ProcessedImage, response.ProcessedImageData = processedImage(mediaType, results.ProcessedImageData)
+	return response
+}
 
-	// リクエストボディの読み取り
-	if r.Body == nil {
-		slog.Error("リクエストボディが空です")
-		sendErrorResponse(w, http.StatusBadRequest, "empty request body")
-		return
+// 検出された顔を旧形式に変換
+// 座標は画像のサイズで0〜1に正規化し、サイズが不明な場合は元の値をそのまま使用する
+func (h *FaceHandler) faceRegions(results *analyzer.AnalysisResult, width, height int) []FaceRegion {
+	faces := make([]FaceRegion, len(results.Faces))
+	for i, face := range results.Faces {
+		f := FaceRegion{
+			X:          face.X,
+			Y:          face.Y,
+			Width:      face.Width,
+			Height:     face.Height,
+			Detector:   face.Detector,
+			Score:      face.DetectionScore,
+			TrackID:    face.TrackID,
+			Quality:    face.Quality,
+			Engagement: face.Engagement,
+			Eyes:       toEyesRegion(face.Eyes),
+			Explain:    toFaceExplainResponse(face),
+		}
+		if width > 0 && height > 0 {
+			box := normalizedBox(face, width, height)
+			f.X, f.Y, f.Width, f.Height = box.X, box.Y, box.Width, box.Height
+		}
+		if face.Occlusion != nil {
+			f.Occluded = face.Occlusion.Occluded
+			f.Masked = face.Occlusion.Masked
+		}
+		if match, ok := h.matchParticipant(face); ok {
+			f.ParticipantID = match.ID
+			f.Similarity = match.Similarity
+		}
+		faces[i] = f
 	}
+	return faces
+}
 
-	// バッファリーダーを使用してボディを読み取り
-	const maxRequestSize = 5 * 1024 * 1024 // 5MB制限
-	bodyReader := http.MaxBytesReader(w, r.Body, maxRequestSize)
-	body, err := io.ReadAll(bodyReader)
-	if err != nil {
-		slog.Error("リクエストボディの読み取りに失敗",
-			"error", err,
-			"content_length", r.ContentLength,
-			"transfer_encoding", r.TransferEncoding)
-		sendErrorResponse(w, http.StatusBadRequest, "image size exceeds limit")
-		return
+// 処理済み画像をレスポンスの形式に合わせて返す
+// JSON の場合のみBase64のデータURLにし、バイナリの形式ではエンコードせずにそのまま返す
+// 形式が空の場合は画像を含めない
+func processedImage(mediaType string, data []byte) (string, []byte) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	switch mediaType {
+	case codec.MediaTypeJSON:
+		return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data), nil
+	case "":
+		return "", nil
+	default:
+		return "", data
 	}
-	defer r.Body.Close()
+}
 
-	// 読み取ったデータのサイズを確認
-	if len(body) == 0 {
-		slog.Error("リクエストボディが空です",
-			"body_length", len(body),
-			"content_length", r.ContentLength,
-			"transfer_encoding", r.TransferEncoding)
-		sendErrorResponse(w, http.StatusBadRequest, "empty request body")
+// 交渉した形式でレスポンスを送信
+func writeResponse(w http.ResponseWriter, r *http.Request, mediaType string, v interface{}) {
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, mediaType, v); err != nil {
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "response encoding failed", err))
 		return
 	}
+	w.Header().Set("Content-Type", codec.ContentType(mediaType, v))
+	w.Header().Add("Vary", "Accept")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
 
-	// 読み取ったボディの長さとプレフィックスを確認
-	slog.Debug("ボディ読み取り完了",
-		"body_length", len(body),
-		"expected_length", r.ContentLength,
-		"body_prefix", string(body[:min(100, len(body))]))
-
-	// リクエストのデコード
-	var req AnalyzeRequest
-	if err := json.Unmarshal(body, &req); err != nil {
-		slog.Error("JSONのデコードに失敗",
-			"error", err,
-			"body_length", len(body),
-			"body_start", string(body[:min(100, len(body))]))
-		sendErrorResponse(w, http.StatusBadRequest, "invalid request body")
-		return
+// 分析結果を /api/v1 の形式の Protobuf で送信
+func (h *FaceHandler) writeAnalyzeProto(w http.ResponseWriter, r *http.Request, req AnalyzeRequest, results *analyzer.AnalysisResult) {
+	response := h.responseV1(req, results, codec.MediaTypeProtobuf)
+	writeResponse(w, r, codec.MediaTypeProtobuf, toAnalyzeResponseProto(&response))
+}
+
+// リクエストの読み取りから顔分析の実行までを行う
+// 失敗した場合はエラーレスポンスを送信して false を返す
+func (h *FaceHandler) runAnalyze(w http.ResponseWriter, r *http.Request) (AnalyzeRequest, *analyzer.AnalysisResult, bool) {
+	req, imgBytes, opts, ok := h.readAnalyzeRequest(w, r, maxRequestSize)
+	if !ok {
+		return AnalyzeRequest{}, nil, false
+	}
+	// 同期の分析では結果をそのまま返すため、コールバックは受け付けない
+	if req.CallbackURL != "" {
+		errors.WriteProblem(w, r, invalidRequest("callbackUrl is only supported for jobs"))
+		return AnalyzeRequest{}, nil, false
+	}
+	results, err := h.analyzer.AnalyzeWithOptions(imgBytes, opts)
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return AnalyzeRequest{}, nil, false
 	}
+	return req, results, true
+}
 
-	// Base64画像データの検証と抽出
-	imgData := req.Image
-	if !strings.HasPrefix(imgData, "data:image/jpeg;base64,") {
-		slog.Error("不正な画像データ形式")
-		sendErrorResponse(w, http.StatusBadRequest, "invalid image data format")
-		return
+// 分析のリクエストを読み取って検証し、画像と分析オプションを返す
+// 失敗した場合はエラーレスポンスを送信して false を返す
+// limit はリクエストボディと画像データのサイズ上限
+func (h *FaceHandler) readAnalyzeRequest(w http.ResponseWriter, r *http.Request, limit int64) (AnalyzeRequest, []byte, analyzer.AnalyzeOptions, bool) {
+	if r.Method != http.MethodPost {
+		errors.WriteProblem(w, r, errMethodNotAllowed)
+		return AnalyzeRequest{}, nil, analyzer.AnalyzeOptions{}, false
 	}
-	imgData = strings.TrimPrefix(imgData, "data:image/jpeg;base64,")
 
-	// Base64デコード
-	imgBytes, err := base64.StdEncoding.DecodeString(imgData)
+	// Content-Typeに応じてリクエストを読み取る
+	// JSONの場合は画像をBase64のデータURIで、multipart/form-data と image/* の場合はバイナリで受け取る
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		sendErrorResponse(w, http.StatusBadRequest, "invalid image data")
-		return
+		slog.Error("不正なContent-Type", "content_type", contentType)
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeUnsupportedMediaType, "invalid content type", err))
+		return AnalyzeRequest{}, nil, analyzer.AnalyzeOptions{}, false
 	}
 
-	// 画像サイズの検証
-	if len(imgBytes) > maxRequestSize {
-		sendErrorResponse(w, http.StatusBadRequest, "image size exceeds limit")
-		return
+	var (
+		req      AnalyzeRequest
+		imgBytes []byte
+	)
+	switch {
+	case mediaType == "application/json":
+		req, err = readJSONAnalyzeRequest(w, r, limit)
+	case mediaType == "multipart/form-data":
+		req, imgBytes, err = readMultipartAnalyzeRequest(w, r, limit)
+	case supportedImageTypes[mediaType]:
+		req, imgBytes, err = readRawAnalyzeRequest(w, r, limit)
+	default:
+		slog.Error("不正なContent-Type", "content_type", contentType)
+		err = errors.CodeError(errors.ErrCodeUnsupportedMediaType, "invalid content type", nil)
 	}
-
-	// 画像データの検証
-	if len(imgBytes) == 0 {
-		sendErrorResponse(w, http.StatusBadRequest, "empty image data")
-		return
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return AnalyzeRequest{}, nil, analyzer.AnalyzeOptions{}, false
 	}
 
-	// 顔分析の実行
-	results, err := h.analyzer.Analyze(imgBytes)
+	opts, err := h.analyzeOptions(req, r.Header.Get("Accept-Language"))
 	if err != nil {
-		sendErrorResponse(w, http.StatusInternalServerError, err.Error())
-		return
+		errors.WriteProblem(w, r, err)
+		return AnalyzeRequest{}, nil, analyzer.AnalyzeOptions{}, false
 	}
 
-	// 顔が検出されなかった場合
-	if len(results.Faces) == 0 {
-		response := AnalyzeResponse{
-			Emotion:    "不明",
-			Confidence: 0,
-			Faces:      []FaceRegion{},
-		}
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			sendErrorResponse(w, http.StatusInternalServerError, "response encoding failed")
+	// Base64画像データの検証と抽出、またはURLからの取得
+	if imgBytes == nil {
+		imgBytes, err = h.requestImage(r.Context(), req.Image, req.ImageURL, limit)
+		if err != nil {
+			slog.Error("画像データの取得に失敗", "error", err)
+			errors.WriteProblem(w, r, err)
+			return AnalyzeRequest{}, nil, analyzer.AnalyzeOptions{}, false
 		}
-		return
 	}
+	return req, imgBytes, opts, true
+}
 
-	// レスポンスの構築
-	response := AnalyzeResponse{
-		Emotion:    EmotionToString(results.PrimaryEmotion),
-		Confidence: float64(results.Confidence),
-		Faces:      make([]FaceRegion, len(results.Faces)),
+// リクエストの分析オプションを検証し、分析器のオプションを作成
+// 言語の指定がない場合は acceptLanguage から選択する
+func (h *FaceHandler) analyzeOptions(req AnalyzeRequest, acceptLanguage string) (analyzer.AnalyzeOptions, error) {
+	// 主要な顔の選択ポリシーの検証
+	if req.PrimaryPolicy != "" && !config.IsValidPrimaryFacePolicy(req.PrimaryPolicy) {
+		return analyzer.AnalyzeOptions{}, invalidRequest("invalid primary policy")
+	}
+	if req.SessionID != "" && !validSessionID.MatchString(req.SessionID) {
+		return analyzer.AnalyzeOptions{}, invalidRequest("invalid session id")
+	}
+	if req.PrimaryPolicy == analyzer.PrimaryTracked && (req.TrackID <= 0 || req.SessionID == "") {
+		return analyzer.AnalyzeOptions{}, invalidRequest("tracked policy requires trackId and sessionId")
 	}
 
-	// 画像の元のサイズを取得
-	imgWidth := float64(0)
-	imgHeight := float64(0)
-	if len(results.Faces) > 0 {
-		// 画像の元のサイズを取得（ProcessedImageDataから）
-		img, err := gocv.IMDecode(results.ProcessedImageData, gocv.IMReadUnchanged)
-		if err == nil {
-			defer img.Close()
-			imgWidth = float64(img.Cols())
-			imgHeight = float64(img.Rows())
+	// 分析対象領域の検証
+	var roi *config.ROI
+	if req.ROI != nil {
+		roi = &config.ROI{
+			X:       req.ROI.X,
+			Y:       req.ROI.Y,
+			Width:   req.ROI.Width,
+			Height:  req.ROI.Height,
+			Polygon: req.ROI.Polygon,
 		}
+		if roi.IsZero() || roi.Validate() != nil {
+			return analyzer.AnalyzeOptions{}, invalidRequest("invalid roi")
+		}
+	}
+
+	// 判定根拠の出力は設定で有効な場合のみ受け付ける
+	if (req.Explain || req.ExplainImages) && !h.explainEnabled {
+		return analyzer.AnalyzeOptions{}, errors.CodeError(errors.ErrCodeForbidden, "explain is disabled", nil)
+	}
+	if req.ExplainImages && !h.explainImages {
+		return analyzer.AnalyzeOptions{}, errors.CodeError(errors.ErrCodeForbidden, "explain images are disabled", nil)
 	}
 
-	// 座標を正規化（0-1の範囲に変換）
+	if req.Crowd && !h.crowdEnabled {
+		return analyzer.AnalyzeOptions{}, errors.CodeError(errors.ErrCodeForbidden, "crowd mode is disabled", nil)
+	}
+
+	// ラベルの言語の検証
+	locale := req.Locale
+	if locale == "" {
+		locale = annotation.LocaleFromAcceptLanguage(acceptLanguage)
+	} else if !config.IsValidAnnotationLocale(locale) {
+		return analyzer.AnalyzeOptions{}, invalidRequest("invalid locale")
+	}
+
+	return analyzer.AnalyzeOptions{
+		PrimaryPolicy: req.PrimaryPolicy,
+		TrackID:       req.TrackID,
+		SessionID:     req.SessionID,
+		Explain:       req.Explain,
+		ExplainImages: req.ExplainImages,
+		ROI:           roi,
+		Crowd:         req.Crowd,
+		Locale:        locale,
+	}, nil
+}
+
+// エンゲージメントスコアをメトリクスに記録し、セッションが指定されていれば集計する
+func (h *FaceHandler) recordSession(sessionID string, results *analyzer.AnalysisResult) *session.Aggregate {
+	frame := session.Frame{Engagement: make([]float64, len(results.Faces))}
 	for i, face := range results.Faces {
-		if imgWidth > 0 && imgHeight > 0 {
-			response.Faces[i] = FaceRegion{
-				X:      face.X / imgWidth,
-				Y:      face.Y / imgHeight,
-				Width:  face.Width / imgWidth,
-				Height: face.Height / imgHeight,
-			}
-		} else {
-			// 画像サイズが取得できない場合は元の値をそのまま使用
-			response.Faces[i] = FaceRegion{
-				X:      face.X,
-				Y:      face.Y,
-				Width:  face.Width,
-				Height: face.Height,
-			}
+		frame.Engagement[i] = face.Engagement
+		if h.metrics != nil {
+			h.metrics.RecordEngagement(face.Engagement)
 		}
 	}
+	if len(results.Faces) > 0 {
+		frame.Emotion = string(results.PrimaryEmotion)
+	}
+
+	if h.sessions == nil || sessionID == "" {
+		return nil
+	}
+	agg := h.sessions.Record(sessionID, frame)
+	h.publishSession(sessionID, results, &agg)
+	return &agg
+}
 
-	// 処理済み画像データをBase64エンコードしてレスポンスに追加
-	if len(results.ProcessedImageData) > 0 {
-		response.ProcessedImage = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(results.ProcessedImageData)
+// 入力画像のサイズを返す
+// 分析結果にサイズがない場合は処理済み画像から取得する
+func imageSize(results *analyzer.AnalysisResult) (int, int) {
+	if results.ImageWidth > 0 && results.ImageHeight > 0 {
+		return results.ImageWidth, results.ImageHeight
 	}
+	img, err := gocv.IMDecode(results.ProcessedImageData, gocv.IMReadUnchanged)
+	if err != nil {
+		return 0, 0
+	}
+	defer img.Close()
+	return img.Cols(), img.Rows()
+}
 
-	// JSONレスポンスの送信
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		sendErrorResponse(w, http.StatusInternalServerError, "response encoding failed")
-		return
+// 顔の埋め込みを登録済み人物と照合
+func (h *FaceHandler) matchParticipant(face analyzer.Face) (gallery.Match, bool) {
+	if h.gallery == nil || len(face.Embedding) == 0 {
+		return gallery.Match{}, false
+	}
+	return h.gallery.Match(face.Embedding, h.matchThreshold)
+}
+
+// デバッグモードの場合にパイプラインの段階ごとの処理時間を返す
+func (h *FaceHandler) stageTimings(results *analyzer.AnalysisResult) []StageTimingResponse {
+	if !h.debug {
+		return nil
+	}
+	timings := make([]StageTimingResponse, len(results.StageTimings))
+	for i, t := range results.StageTimings {
+		timings[i] = StageTimingResponse{
+			Stage:      t.Name,
+			DurationMs: float64(t.Duration.Microseconds()) / 1000,
+		}
+	}
+	return timings
+}
+
+func toFaceExplainResponse(face analyzer.Face) *FaceExplainResponse {
+	e := face.Explanation
+	if e == nil {
+		return nil
+	}
+	return &FaceExplainResponse{
+		Brightness:        e.Features.Brightness,
+		Variation:         e.Features.Variation,
+		SmileScore:        e.SmileScore,
+		LaplacianVariance: e.LaplacianVariance,
+		Sharpness:         e.Sharpness,
+		SizeScore:         e.SizeScore,
+		Quality:           face.Quality,
+		Emotion:           string(face.Emotion),
+		Rule:              e.Rule,
+		Thresholds:        e.Thresholds,
+		Margins:           e.Margins,
+		ROIImage:          pngDataURL(e.ROIImage),
+	}
+}
+
+func toCrowdResponse(summary *analyzer.CrowdSummary) *CrowdResponse {
+	if summary == nil {
+		return nil
+	}
+	response := &CrowdResponse{
+		FaceCount:    summary.FaceCount,
+		Emotions:     make(map[string]int, len(summary.Emotions)),
+		Distribution: make(map[string]float64, len(summary.Distribution)),
+		Tiles:        summary.Tiles,
+	}
+	for emotion, count := range summary.Emotions {
+		response.Emotions[string(emotion)] = count
+	}
+	for emotion, ratio := range summary.Distribution {
+		response.Distribution[string(emotion)] = ratio
+	}
+	return response
+}
+
+// PNG画像をデータURLに変換
+func pngDataURL(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+func toEyesRegion(eyes *analyzer.EyeAnalysis) *EyesRegion {
+	if eyes == nil {
+		return nil
+	}
+	return &EyesRegion{
+		Openness: eyes.Openness,
+		State:    eyes.State,
+		Gaze:     eyes.Gaze,
+	}
+}
+
+// JSONのリクエストの画像を返す
+// imageUrl を指定した場合はURLから取得し、サイズ上限は取得の設定に従う
+func (h *FaceHandler) requestImage(ctx context.Context, image, imageURL string, limit int64) ([]byte, error) {
+	if imageURL == "" {
+		return decodeImageDataURL(image, limit)
+	}
+	if image != "" {
+		return nil, invalidRequest("image and imageUrl are mutually exclusive")
+	}
+	if h.fetcher == nil {
+		return nil, invalidRequest("imageUrl is disabled")
+	}
+	imgBytes, err := h.fetcher.Fetch(ctx, imageURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateImageBytes(imgBytes); err != nil {
+		return nil, err
+	}
+	return imgBytes, nil
+}
+
+// Base64エンコードされたJPEG画像のデータURLを limit を上限にデコード
+// エラーメッセージはそのままクライアントに返す
+func decodeImageDataURL(dataURL string, limit int64) ([]byte, error) {
+	if !strings.HasPrefix(dataURL, "data:image/jpeg;base64,") {
+		return nil, invalidImage("invalid image data format")
+	}
+
+	// 文字列をバイト列に複製せず、デコード後のサイズ分だけ確保して読み取る
+	encoded := strings.TrimPrefix(dataURL, "data:image/jpeg;base64,")
+	if int64(base64.StdEncoding.DecodedLen(len(encoded))) > limit+2 {
+		return nil, errImageTooLarge
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, base64.StdEncoding.DecodedLen(len(encoded))+bytes.MinRead))
+	if _, err := buf.ReadFrom(base64.NewDecoder(base64.StdEncoding, strings.NewReader(encoded))); err != nil {
+		return nil, invalidImage("invalid image data")
+	}
+	imgBytes := buf.Bytes()
+	if int64(len(imgBytes)) > limit {
+		return nil, errImageTooLarge
+	}
+	if len(imgBytes) == 0 {
+		return nil, invalidImage("empty image data")
 	}
+	return imgBytes, nil
 }
 
 // min関数の追加（ヘルパー関数）
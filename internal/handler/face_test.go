@@ -13,13 +13,20 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/okamyuji/face-emotion-analyzer/config"
 	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
 	"github.com/okamyuji/face-emotion-analyzer/internal/middleware"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
 )
 
 // テスト用の定数
@@ -104,15 +111,27 @@ type mockFaceAnalyzer struct {
 	analyzeFunc func(imgData []byte) (*analyzer.AnalysisResult, error)
 	mu          sync.RWMutex
 	callCount   int
+	lastOptions analyzer.AnalyzeOptions
 }
 
 func (m *mockFaceAnalyzer) Analyze(imgData []byte) (*analyzer.AnalysisResult, error) {
+	return m.AnalyzeWithOptions(imgData, analyzer.AnalyzeOptions{})
+}
+
+func (m *mockFaceAnalyzer) AnalyzeWithOptions(imgData []byte, opts analyzer.AnalyzeOptions) (*analyzer.AnalysisResult, error) {
 	m.mu.Lock()
 	m.callCount++
+	m.lastOptions = opts
 	m.mu.Unlock()
 	return m.analyzeFunc(imgData)
 }
 
+func (m *mockFaceAnalyzer) getLastOptions() analyzer.AnalyzeOptions {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastOptions
+}
+
 func (m *mockFaceAnalyzer) getCallCount() int {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -247,14 +266,15 @@ func TestFaceHandler_HandleAnalyze(t *testing.T) {
 				return nil, errors.New("analysis error")
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantError:  "analysis error",
+			// 型のないエラーの詳細はレスポンスに含めない
+			wantError: "内部エラーが発生しました",
 		},
 		{
 			name: "画像サイズ超過",
 			requestBody: map[string]string{
 				"image": "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(make([]byte, 10*1024*1024)),
 			},
-			wantStatus: http.StatusBadRequest,
+			wantStatus: http.StatusRequestEntityTooLarge,
 			wantError:  "image size exceeds limit",
 		},
 	}
@@ -279,9 +299,7 @@ func TestFaceHandler_HandleAnalyze(t *testing.T) {
 			assert.Equal(t, tt.wantStatus, rec.Code)
 
 			if tt.wantError != "" {
-				var resp ErrorResponse
-				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
-				assert.Contains(t, resp.Error, tt.wantError)
+				assert.Contains(t, decodeProblem(t, rec).Detail, tt.wantError)
 			} else if rec.Code == http.StatusOK {
 				var resp AnalyzeResponse
 				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
@@ -292,6 +310,95 @@ func TestFaceHandler_HandleAnalyze(t *testing.T) {
 	}
 }
 
+func TestFaceHandler_HandleAnalyze_PrimaryPolicy(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	img := createTestImage(testImageWidth, testImageHeight)
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: testQuality}))
+	imageData := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	tests := []struct {
+		name        string
+		requestBody map[string]interface{}
+		wantStatus  int
+		wantOptions analyzer.AnalyzeOptions
+	}{
+		{
+			name:        "ポリシーの指定",
+			requestBody: map[string]interface{}{"image": imageData, "primaryPolicy": "center"},
+			wantStatus:  http.StatusOK,
+			wantOptions: analyzer.AnalyzeOptions{PrimaryPolicy: "center"},
+		},
+		{
+			name:        "トラッキングIDの指定",
+			requestBody: map[string]interface{}{"image": imageData, "primaryPolicy": "tracked", "trackId": 2, "sessionId": "s1"},
+			wantStatus:  http.StatusOK,
+			wantOptions: analyzer.AnalyzeOptions{PrimaryPolicy: "tracked", TrackID: 2, SessionID: "s1"},
+		},
+		{
+			name:        "不明なポリシー",
+			requestBody: map[string]interface{}{"image": imageData, "primaryPolicy": "random"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "トラッキングIDなし",
+			requestBody: map[string]interface{}{"image": imageData, "primaryPolicy": "tracked"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "不正なセッションID",
+			requestBody: map[string]interface{}{"image": imageData, "sessionId": "lab 1/../x"},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "長すぎるセッションID",
+			requestBody: map[string]interface{}{"image": imageData, "sessionId": strings.Repeat("a", 65)},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return &analyzer.AnalysisResult{
+						Faces: []analyzer.Face{
+							{X: 10, Y: 10, Width: 20, Height: 20, TrackID: 1},
+							{X: 100, Y: 100, Width: 40, Height: 40, TrackID: 2},
+						},
+						PrimaryEmotion: analyzer.EmotionHappy,
+						Confidence:     0.9,
+						PrimaryIndex:   1,
+						PrimaryTrackID: 2,
+						PrimaryPolicy:  "tracked",
+					}, nil
+				},
+			}
+
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+			req := createTestRequest(t, http.MethodPost, "/analyze", tt.requestBody)
+			rec := httptest.NewRecorder()
+
+			handler.HandleAnalyze(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, 0, mockAnalyzer.getCallCount())
+				return
+			}
+			assert.Equal(t, tt.wantOptions, mockAnalyzer.getLastOptions())
+
+			var resp AnalyzeResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, 1, resp.PrimaryIndex)
+			assert.Equal(t, 2, resp.PrimaryTrackID)
+			assert.Equal(t, 2, resp.Faces[1].TrackID)
+		})
+	}
+}
+
 func TestFaceHandler_Concurrency(t *testing.T) {
 	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
 	defer cleanup()
@@ -403,3 +510,577 @@ func drawEllipse(img *image.RGBA, centerX, centerY, radiusX, radiusY int, c colo
 		}
 	}
 }
+
+func TestFaceHandler_HandleAnalyze_Occlusion(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	mockAnalyzer := &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			return &analyzer.AnalysisResult{
+				Faces: []analyzer.Face{
+					{X: 10, Y: 10, Width: 20, Height: 20, Occlusion: &analyzer.OcclusionAnalysis{Occluded: true, Masked: true}},
+					{X: 100, Y: 100, Width: 20, Height: 20, Occlusion: &analyzer.OcclusionAnalysis{}},
+				},
+				PrimaryEmotion: analyzer.EmotionUnknown,
+			}, nil
+		},
+	}
+
+	handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+	req := createTestRequest(t, http.MethodPost, "/analyze", map[string]string{"image": testImageDataURL(t)})
+	rec := httptest.NewRecorder()
+	handler.HandleAnalyze(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp AnalyzeResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Faces, 2)
+	assert.True(t, resp.Faces[0].Occluded)
+	assert.True(t, resp.Faces[0].Masked)
+	assert.False(t, resp.Faces[1].Occluded)
+	assert.Equal(t, string(analyzer.EmotionUnknown), resp.Emotion)
+}
+
+func TestFaceHandler_HandleAnalyze_StageTimings(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	mockAnalyzer := &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			return &analyzer.AnalysisResult{
+				Faces:          []analyzer.Face{{X: 32, Y: 24, Width: 64, Height: 48}},
+				PrimaryEmotion: analyzer.EmotionHappy,
+				ImageWidth:     320,
+				ImageHeight:    240,
+				StageTimings: []analyzer.StageTiming{
+					{Name: analyzer.StageDecode, Duration: 1500 * time.Microsecond},
+					{Name: analyzer.StageDetect, Duration: 12 * time.Millisecond},
+				},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		debug       bool
+		wantTimings []StageTimingResponse
+	}{
+		{
+			name:  "デバッグモード",
+			debug: true,
+			wantTimings: []StageTimingResponse{
+				{Stage: "decode", DurationMs: 1.5},
+				{Stage: "detect", DurationMs: 12},
+			},
+		},
+		{
+			name:  "通常モード",
+			debug: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+			handler.SetDebug(tt.debug)
+
+			req := createTestRequest(t, http.MethodPost, "/analyze", map[string]string{"image": testImageDataURL(t)})
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			var resp AnalyzeResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			assert.Equal(t, tt.wantTimings, resp.Timings)
+			// 画像サイズは分析結果の値で正規化する
+			require.Len(t, resp.Faces, 1)
+			assert.InDelta(t, 0.1, resp.Faces[0].X, 1e-9)
+			assert.InDelta(t, 0.2, resp.Faces[0].Width, 1e-9)
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_Explain(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imageData := testImageDataURL(t)
+	explanation := &analyzer.FaceExplanation{
+		Features:   analyzer.EmotionFeatures{Brightness: 150, Variation: 55},
+		SmileScore: 0.4,
+		Rule:       "variation > mid_variation && brightness > brightness_threshold",
+		Thresholds: map[string]float64{"mid_variation": 50},
+		Margins:    map[string]float64{"mid_variation": 5},
+		ROIImage:   []byte{0x89, 'P', 'N', 'G'},
+	}
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		images      bool
+		requestBody map[string]interface{}
+		wantStatus  int
+		wantOptions analyzer.AnalyzeOptions
+	}{
+		{
+			name:        "判定根拠の出力",
+			enabled:     true,
+			requestBody: map[string]interface{}{"image": imageData, "explain": true},
+			wantStatus:  http.StatusOK,
+			wantOptions: analyzer.AnalyzeOptions{Explain: true},
+		},
+		{
+			name:        "中間画像の出力",
+			enabled:     true,
+			images:      true,
+			requestBody: map[string]interface{}{"image": imageData, "explain": true, "explainImages": true},
+			wantStatus:  http.StatusOK,
+			wantOptions: analyzer.AnalyzeOptions{Explain: true, ExplainImages: true},
+		},
+		{
+			name:        "判定根拠の出力が無効",
+			requestBody: map[string]interface{}{"image": imageData, "explain": true},
+			wantStatus:  http.StatusForbidden,
+		},
+		{
+			name:        "中間画像の出力が無効",
+			enabled:     true,
+			requestBody: map[string]interface{}{"image": imageData, "explainImages": true},
+			wantStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return &analyzer.AnalysisResult{
+						Faces: []analyzer.Face{{
+							X: 10, Y: 10, Width: 20, Height: 20,
+							Quality: 0.7, Emotion: analyzer.EmotionHappy, Explanation: explanation,
+						}},
+						PrimaryEmotion: analyzer.EmotionHappy,
+						GrayscaleImage: []byte{0x89, 'P', 'N', 'G'},
+					}, nil
+				},
+			}
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+			handler.SetExplain(tt.enabled, tt.images)
+
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, createTestRequest(t, http.MethodPost, "/analyze", tt.requestBody))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.wantOptions, mockAnalyzer.getLastOptions())
+
+			var resp AnalyzeResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp.Faces, 1)
+			explain := resp.Faces[0].Explain
+			require.NotNil(t, explain)
+			assert.Equal(t, 150.0, explain.Brightness)
+			assert.Equal(t, 0.4, explain.SmileScore)
+			assert.Equal(t, 0.7, explain.Quality)
+			assert.Equal(t, "happy", explain.Emotion)
+			assert.Equal(t, 5.0, explain.Margins["mid_variation"])
+			assert.True(t, strings.HasPrefix(explain.ROIImage, "data:image/png;base64,"))
+			assert.True(t, strings.HasPrefix(resp.GrayscaleImage, "data:image/png;base64,"))
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_ROI(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name        string
+		requestBody map[string]interface{}
+		wantStatus  int
+		wantROI     *config.ROI
+	}{
+		{
+			name:        "矩形の指定",
+			requestBody: map[string]interface{}{"image": imageData, "roi": map[string]float64{"x": 0.25, "y": 0.2, "width": 0.5, "height": 0.6}},
+			wantStatus:  http.StatusOK,
+			wantROI:     &config.ROI{X: 0.25, Y: 0.2, Width: 0.5, Height: 0.6},
+		},
+		{
+			name: "多角形の指定",
+			requestBody: map[string]interface{}{"image": imageData, "roi": map[string]interface{}{
+				"polygon": [][2]float64{{0.2, 0.2}, {0.8, 0.2}, {0.5, 0.9}},
+			}},
+			wantStatus: http.StatusOK,
+			wantROI:    &config.ROI{Polygon: [][2]float64{{0.2, 0.2}, {0.8, 0.2}, {0.5, 0.9}}},
+		},
+		{
+			name:        "指定なし",
+			requestBody: map[string]interface{}{"image": imageData},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "画像の外にはみ出す矩形",
+			requestBody: map[string]interface{}{"image": imageData, "roi": map[string]float64{"x": 0.8, "y": 0.2, "width": 0.5, "height": 0.6}},
+			wantStatus:  http.StatusBadRequest,
+		},
+		{
+			name:        "空の領域",
+			requestBody: map[string]interface{}{"image": imageData, "roi": map[string]float64{}},
+			wantStatus:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionUnknown, PrimaryIndex: -1}, nil
+				},
+			}
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, createTestRequest(t, http.MethodPost, "/analyze", tt.requestBody))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantROI, mockAnalyzer.getLastOptions().ROI)
+			}
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_Crowd(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		wantStatus int
+	}{
+		{
+			name:       "群衆モード",
+			enabled:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "群衆モードが無効",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return &analyzer.AnalysisResult{
+						Faces: []analyzer.Face{
+							{X: 10, Y: 10, Width: 20, Height: 20, Emotion: analyzer.EmotionHappy},
+							{X: 50, Y: 10, Width: 20, Height: 20, Emotion: analyzer.EmotionHappy},
+							{X: 90, Y: 10, Width: 20, Height: 20, Emotion: analyzer.EmotionSad},
+						},
+						PrimaryEmotion: analyzer.EmotionHappy,
+						ImageWidth:     200,
+						ImageHeight:    100,
+						Crowd: &analyzer.CrowdSummary{
+							FaceCount:    3,
+							Emotions:     map[analyzer.Emotion]int{analyzer.EmotionHappy: 2, analyzer.EmotionSad: 1},
+							Distribution: map[analyzer.Emotion]float64{analyzer.EmotionHappy: 2.0 / 3, analyzer.EmotionSad: 1.0 / 3},
+							Tiles:        4,
+						},
+					}, nil
+				},
+			}
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+			handler.SetCrowd(tt.enabled)
+
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, createTestRequest(t, http.MethodPost, "/analyze",
+				map[string]interface{}{"image": imageData, "crowd": true}))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			assert.True(t, mockAnalyzer.getLastOptions().Crowd)
+
+			var resp AnalyzeResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.NotNil(t, resp.Crowd)
+			assert.Equal(t, 3, resp.Crowd.FaceCount)
+			assert.Equal(t, 4, resp.Crowd.Tiles)
+			assert.Equal(t, map[string]int{"happy": 2, "sad": 1}, resp.Crowd.Emotions)
+			assert.InDelta(t, 2.0/3, resp.Crowd.Distribution["happy"], 1e-9)
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_Locale(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name           string
+		locale         string
+		acceptLanguage string
+		wantStatus     int
+		wantLocale     string
+	}{
+		{
+			name:       "リクエストで指定",
+			locale:     "en",
+			wantStatus: http.StatusOK,
+			wantLocale: "en",
+		},
+		{
+			name:           "Accept-Language ヘッダーから選択",
+			acceptLanguage: "ja-JP,ja;q=0.9",
+			wantStatus:     http.StatusOK,
+			wantLocale:     "ja",
+		},
+		{
+			name:       "未指定",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "非対応の言語",
+			locale:     "fr",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionUnknown, PrimaryIndex: -1}, nil
+				},
+			}
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+
+			body := map[string]interface{}{"image": imageData}
+			if tt.locale != "" {
+				body["locale"] = tt.locale
+			}
+			req := createTestRequest(t, http.MethodPost, "/analyze", body)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, tt.wantLocale, mockAnalyzer.getLastOptions().Locale)
+			}
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_Accept(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+	mockAnalyzer.analyzeFunc = func(imgData []byte) (*analyzer.AnalysisResult, error) {
+		return &analyzer.AnalysisResult{
+			Faces:              []analyzer.Face{{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.2}},
+			PrimaryEmotion:     analyzer.EmotionHappy,
+			Confidence:         0.9,
+			ProcessedImageData: []byte("jpeg"),
+		}, nil
+	}
+	handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+	body := map[string]interface{}{"image": testImageDataURL(t)}
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		check           func(t *testing.T, body []byte)
+	}{
+		{
+			name:            "JSON",
+			accept:          "application/json",
+			wantContentType: codec.MediaTypeJSON,
+			check: func(t *testing.T, body []byte) {
+				var resp AnalyzeResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, EmotionToString(analyzer.EmotionHappy), resp.Emotion)
+				assert.Equal(t, "data:image/jpeg;base64,anBlZw==", resp.ProcessedImage)
+			},
+		},
+		{
+			name:            "MessagePack",
+			accept:          "application/msgpack",
+			wantContentType: codec.MediaTypeMsgPack,
+			check: func(t *testing.T, body []byte) {
+				var resp map[string]interface{}
+				require.NoError(t, msgpack.Unmarshal(body, &resp))
+				assert.Equal(t, EmotionToString(analyzer.EmotionHappy), resp["emotion"])
+				assert.Equal(t, []byte("jpeg"), resp["processedImage"])
+				assert.Len(t, resp["faces"], 1)
+			},
+		},
+		{
+			name:            "Protobuf",
+			accept:          "application/x-protobuf",
+			wantContentType: "application/x-protobuf; messageType=faceemotion.v1.AnalyzeResponse",
+			check: func(t *testing.T, body []byte) {
+				var resp faceemotionv1.AnalyzeResponse
+				require.NoError(t, proto.Unmarshal(body, &resp))
+				assert.Equal(t, faceemotionv1.Emotion_EMOTION_HAPPY, resp.GetEmotion())
+				assert.Equal(t, []byte("jpeg"), resp.GetProcessedImage())
+				assert.Len(t, resp.GetFaces(), 1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createTestRequest(t, http.MethodPost, "/analyze", body)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+			tt.check(t, rec.Body.Bytes())
+		})
+	}
+
+	t.Run("エラーもMessagePackで返す", func(t *testing.T) {
+		req := createTestRequest(t, http.MethodPost, "/analyze", map[string]interface{}{"image": "invalid"})
+		req.Header.Set("Accept", "application/msgpack")
+		rec := httptest.NewRecorder()
+		handler.HandleAnalyze(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, codec.MediaTypeMsgPack, rec.Header().Get("Content-Type"))
+		var problem map[string]interface{}
+		require.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &problem))
+		assert.NotEmpty(t, problem["code"])
+	})
+}
+
+func TestProcessedImage(t *testing.T) {
+	data := []byte("jpeg")
+
+	tests := []struct {
+		name      string
+		mediaType string
+		data      []byte
+		wantURL   string
+		wantData  []byte
+	}{
+		{
+			name:      "JSONはデータURL",
+			mediaType: codec.MediaTypeJSON,
+			data:      data,
+			wantURL:   "data:image/jpeg;base64,anBlZw==",
+		},
+		{
+			name:      "MessagePackはエンコードしない",
+			mediaType: codec.MediaTypeMsgPack,
+			data:      data,
+			wantData:  data,
+		},
+		{
+			name:      "Protobufはエンコードしない",
+			mediaType: codec.MediaTypeProtobuf,
+			data:      data,
+			wantData:  data,
+		},
+		{
+			name:      "形式の指定なし",
+			mediaType: "",
+			data:      data,
+		},
+		{
+			name:      "画像なし",
+			mediaType: codec.MediaTypeJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, raw := processedImage(tt.mediaType, tt.data)
+			assert.Equal(t, tt.wantURL, url)
+			assert.Equal(t, tt.wantData, raw)
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_NoFaces(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+	mockAnalyzer.analyzeFunc = func(imgData []byte) (*analyzer.AnalysisResult, error) {
+		return &analyzer.AnalysisResult{
+			PrimaryIndex:       -1,
+			Preprocessing:      []string{"clahe"},
+			ProcessedImageData: []byte("jpeg"),
+		}, nil
+	}
+	handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+
+	req := createTestRequest(t, http.MethodPost, "/analyze", map[string]interface{}{"image": testImageDataURL(t)})
+	rec := httptest.NewRecorder()
+	handler.HandleAnalyze(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp AnalyzeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "不明", resp.Emotion)
+	assert.Equal(t, -1, resp.PrimaryIndex)
+	assert.NotNil(t, resp.Faces)
+	assert.Empty(t, resp.Faces)
+	assert.Equal(t, []string{"clahe"}, resp.Preprocessing)
+	// 顔がなくても処理済み画像を返す
+	assert.Equal(t, "data:image/jpeg;base64,anBlZw==", resp.ProcessedImage)
+}
+
+func TestFaceHandler_FaceRegions(t *testing.T) {
+	handler := &FaceHandler{}
+	results := &analyzer.AnalysisResult{
+		Faces: []analyzer.Face{{
+			X: 10, Y: 20, Width: 30, Height: 40,
+			Detector:  "haar",
+			Quality:   0.8,
+			Occlusion: &analyzer.OcclusionAnalysis{Occluded: true, Masked: true},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		width, height int
+		want          [4]float64
+	}{
+		{name: "画像のサイズで正規化", width: 100, height: 200, want: [4]float64{0.1, 0.1, 0.3, 0.2}},
+		{name: "画像のサイズが不明", want: [4]float64{10, 20, 30, 40}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			faces := handler.faceRegions(results, tt.width, tt.height)
+			require.Len(t, faces, 1)
+			f := faces[0]
+			assert.InDeltaSlice(t, tt.want[:], []float64{f.X, f.Y, f.Width, f.Height}, 1e-9)
+			assert.Equal(t, "haar", f.Detector)
+			assert.Equal(t, 0.8, f.Quality)
+			assert.True(t, f.Occluded)
+			assert.True(t, f.Masked)
+		})
+	}
+
+	assert.Equal(t, []FaceRegion{}, handler.faceRegions(&analyzer.AnalysisResult{}, 100, 100))
+}
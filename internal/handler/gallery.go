@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/gallery"
+)
+
+// 登録済み人物のギャラリーを管理するハンドラー
+type GalleryHandler struct {
+	analyzer analyzer.FaceAnalyzerInterface
+	gallery  *gallery.Gallery
+}
+
+type EnrollRequest struct {
+	ID    string `json:"id"`
+	Name  string `json:"name,omitempty"`
+	Image string `json:"image"`
+}
+
+type PersonResponse struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name,omitempty"`
+	Samples    int       `json:"samples"`
+	EnrolledAt time.Time `json:"enrolledAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type GalleryListResponse struct {
+	People []PersonResponse `json:"people"`
+}
+
+func NewGalleryHandler(analyzer analyzer.FaceAnalyzerInterface, g *gallery.Gallery) *GalleryHandler {
+	return &GalleryHandler{
+		analyzer: analyzer,
+		gallery:  g,
+	}
+}
+
+// 画像に写った1人の顔を人物として登録
+func (h *GalleryHandler) HandleEnroll(w http.ResponseWriter, r *http.Request) {
+	var req EnrollRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		errors.WriteProblem(w, r, invalidRequest("invalid request body"))
+		return
+	}
+
+	imgBytes, err := decodeImageDataURL(req.Image, maxRequestSize)
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	results, err := h.analyzer.AnalyzeWithOptions(imgBytes, analyzer.AnalyzeOptions{})
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	// 他人の顔を誤って登録しないよう、顔が1つの画像のみ受け付ける
+	switch len(results.Faces) {
+	case 0:
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeFaceNotDetected, "no face detected", nil))
+		return
+	case 1:
+	default:
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeMultipleFaces, "multiple faces detected", nil))
+		return
+	}
+
+	embedding := results.Faces[0].Embedding
+	if len(embedding) == 0 {
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeUnavailable, "face embedding is not configured", nil))
+		return
+	}
+
+	person, err := h.gallery.Enroll(req.ID, req.Name, embedding)
+	if err != nil {
+		slog.Error("人物の登録に失敗", "id", req.ID, "error", err)
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(toPersonResponse(person)); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
+
+// 登録済みの人物の一覧を返す
+// 埋め込みはレスポンスに含めない
+func (h *GalleryHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	people := h.gallery.List()
+	response := GalleryListResponse{People: make([]PersonResponse, len(people))}
+	for i, p := range people {
+		response.People[i] = toPersonResponse(p)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
+
+// 人物をギャラリーから削除
+func (h *GalleryHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.gallery.Delete(id); err != nil {
+		if stderrors.Is(err, errors.ErrPersonNotFound) {
+			errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeNotFound, "person not found", err))
+			return
+		}
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "failed to delete person", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toPersonResponse(p gallery.Person) PersonResponse {
+	return PersonResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Samples:    len(p.Embeddings),
+		EnrolledAt: p.EnrolledAt,
+		UpdatedAt:  p.UpdatedAt,
+	}
+}
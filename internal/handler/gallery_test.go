@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/gallery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImageDataURL(tb testing.TB) string {
+	tb.Helper()
+	var buf bytes.Buffer
+	require.NoError(tb, jpeg.Encode(&buf, createTestImage(testImageWidth, testImageHeight), &jpeg.Options{Quality: testQuality}))
+	return "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// problem+json のエラーレスポンスを読み取る
+func decodeProblem(tb testing.TB, rec *httptest.ResponseRecorder) errors.Problem {
+	tb.Helper()
+	require.Equal(tb, errors.ProblemContentType, rec.Header().Get("Content-Type"))
+	var p errors.Problem
+	require.NoError(tb, json.NewDecoder(rec.Body).Decode(&p))
+	require.Equal(tb, rec.Code, p.Status)
+	return p
+}
+
+func TestGalleryHandler_HandleEnroll(t *testing.T) {
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name       string
+		body       interface{}
+		faces      []analyzer.Face
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "登録成功",
+			body:       EnrollRequest{ID: "p001", Name: "参加者1", Image: imageData},
+			faces:      []analyzer.Face{{Width: 100, Height: 100, Embedding: []float32{0.6, 0.8}}},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "顔が検出されない",
+			body:       EnrollRequest{ID: "p001", Image: imageData},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "no face detected",
+		},
+		{
+			name: "複数の顔",
+			body: EnrollRequest{ID: "p001", Image: imageData},
+			faces: []analyzer.Face{
+				{Embedding: []float32{1, 0}},
+				{Embedding: []float32{0, 1}},
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "multiple faces detected",
+		},
+		{
+			name:       "埋め込みモデルが未設定",
+			body:       EnrollRequest{ID: "p001", Image: imageData},
+			faces:      []analyzer.Face{{Width: 100, Height: 100}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantError:  "face embedding is not configured",
+		},
+		{
+			name:       "不正な人物ID",
+			body:       EnrollRequest{ID: "../p001", Image: imageData},
+			faces:      []analyzer.Face{{Embedding: []float32{1, 0}}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "不正な画像データ",
+			body:       EnrollRequest{ID: "p001", Image: "invalid"},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid image data format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := gallery.Open("")
+			require.NoError(t, err)
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					return &analyzer.AnalysisResult{Faces: tt.faces}, nil
+				},
+			}
+
+			handler := NewGalleryHandler(mockAnalyzer, g)
+			req := createTestRequest(t, http.MethodPost, "/gallery/enroll", tt.body)
+			rec := httptest.NewRecorder()
+
+			handler.HandleEnroll(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantError != "" {
+				assert.Equal(t, tt.wantError, decodeProblem(t, rec).Detail)
+			}
+			if tt.wantStatus == http.StatusCreated {
+				var resp PersonResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+				assert.Equal(t, "p001", resp.ID)
+				assert.Equal(t, 1, resp.Samples)
+				assert.Len(t, g.List(), 1)
+			}
+		})
+	}
+}
+
+func TestGalleryHandler_ListAndDelete(t *testing.T) {
+	g, err := gallery.Open("")
+	require.NoError(t, err)
+	_, err = g.Enroll("p001", "参加者1", []float32{1, 0})
+	require.NoError(t, err)
+	_, err = g.Enroll("p002", "", []float32{0, 1})
+	require.NoError(t, err)
+
+	handler := NewGalleryHandler(&mockFaceAnalyzer{}, g)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /gallery", handler.HandleList)
+	mux.HandleFunc("DELETE /gallery/{id}", handler.HandleDelete)
+
+	// 一覧の取得
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, createTestRequest(t, http.MethodGet, "/gallery", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list GalleryListResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+	require.Len(t, list.People, 2)
+	assert.Equal(t, "p001", list.People[0].ID)
+	assert.NotContains(t, rec.Body.String(), "embeddings")
+
+	// 削除
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, createTestRequest(t, http.MethodDelete, "/gallery/p001", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, g.List(), 1)
+
+	// 存在しない人物の削除
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, createTestRequest(t, http.MethodDelete, "/gallery/p001", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestFaceHandler_HandleAnalyze_GalleryMatch(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	g, err := gallery.Open("")
+	require.NoError(t, err)
+	_, err = g.Enroll("p001", "", []float32{1, 0})
+	require.NoError(t, err)
+
+	mockAnalyzer := &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			return &analyzer.AnalysisResult{
+				Faces: []analyzer.Face{
+					{X: 10, Y: 10, Width: 20, Height: 20, Embedding: []float32{0.99, 0.1}},
+					{X: 100, Y: 100, Width: 20, Height: 20, Embedding: []float32{0, 1}},
+				},
+				PrimaryEmotion: analyzer.EmotionHappy,
+				Confidence:     0.9,
+			}, nil
+		},
+	}
+
+	handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+	handler.SetGallery(g, 0.8)
+
+	req := createTestRequest(t, http.MethodPost, "/analyze", map[string]string{"image": testImageDataURL(t)})
+	rec := httptest.NewRecorder()
+	handler.HandleAnalyze(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp AnalyzeResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	require.Len(t, resp.Faces, 2)
+	assert.Equal(t, "p001", resp.Faces[0].ParticipantID)
+	assert.Greater(t, resp.Faces[0].Similarity, 0.8)
+	assert.Empty(t, resp.Faces[1].ParticipantID)
+}
@@ -0,0 +1,471 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// BatchAnalyze で1回に受け付ける画像の最大数
+const maxBatchSize = 8
+
+// BatchAnalyze で並行して分析する画像の数
+const batchConcurrency = 4
+
+// gRPCで受信するメッセージの最大サイズ
+// BatchAnalyze で上限の数の画像を送信できる大きさにする
+const GRPCMaxMessageSize = maxBatchSize*maxRequestSize + 1024*1024
+
+// 感情の列挙値の対応
+var grpcEmotions = map[string]faceemotionv1.Emotion{
+	"happy":    faceemotionv1.Emotion_EMOTION_HAPPY,
+	"sad":      faceemotionv1.Emotion_EMOTION_SAD,
+	"angry":    faceemotionv1.Emotion_EMOTION_ANGRY,
+	"surprise": faceemotionv1.Emotion_EMOTION_SURPRISE,
+	"neutral":  faceemotionv1.Emotion_EMOTION_NEUTRAL,
+	"unknown":  faceemotionv1.Emotion_EMOTION_UNKNOWN,
+}
+
+// HTTPのハンドラーと同じ分析器で画像を分析するgRPCサービス
+type GRPCService struct {
+	faceemotionv1.UnimplementedAnalyzerServiceServer
+	face    *FaceHandler
+	streams *StreamHandler
+}
+
+// ストリームのフレームレートの制限と平滑化は streams の設定を使用する
+func NewGRPCService(face *FaceHandler, streams *StreamHandler) *GRPCService {
+	return &GRPCService{face: face, streams: streams}
+}
+
+// 1枚の画像を分析
+func (s *GRPCService) Analyze(ctx context.Context, in *faceemotionv1.AnalyzeRequest) (*faceemotionv1.AnalyzeResponse, error) {
+	response, err := s.analyze(ctx, in)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return response, nil
+}
+
+// 複数の画像を並行して分析
+// 画像ごとに分析結果またはエラーを返す
+func (s *GRPCService) BatchAnalyze(ctx context.Context, in *faceemotionv1.BatchAnalyzeRequest) (*faceemotionv1.BatchAnalyzeResponse, error) {
+	requests := in.GetRequests()
+	if len(requests) == 0 {
+		return nil, grpcError(ctx, invalidRequest("requests is empty"))
+	}
+	if len(requests) > maxBatchSize {
+		return nil, grpcError(ctx, invalidRequest("too many requests"))
+	}
+
+	results := make([]*faceemotionv1.BatchAnalyzeResult, len(requests))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			response, err := s.analyze(ctx, req)
+			if err != nil {
+				results[i] = &faceemotionv1.BatchAnalyzeResult{
+					Outcome: &faceemotionv1.BatchAnalyzeResult_Error{Error: grpcErrorDetail(ctx, err)},
+				}
+				return
+			}
+			results[i] = &faceemotionv1.BatchAnalyzeResult{
+				Outcome: &faceemotionv1.BatchAnalyzeResult_Response{Response: response},
+			}
+		}()
+	}
+	wg.Wait()
+
+	return &faceemotionv1.BatchAnalyzeResponse{Results: results}, nil
+}
+
+// フレームを受信し、フレームごとの分析結果を返す
+// 分析オプションは最初のメッセージのみ使用する
+// WebSocketと同様に、分析が追いつかない場合や上限を超えたフレームは破棄する
+func (s *GRPCService) AnalyzeStream(srv faceemotionv1.AnalyzerService_AnalyzeStreamServer) error {
+	ctx := srv.Context()
+	first, err := srv.Recv()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	req, err := analyzeRequestFromProto(first.GetOptions())
+	if err != nil {
+		return grpcError(ctx, err)
+	}
+	opts, err := s.face.analyzeOptions(req, grpcAcceptLanguage(ctx))
+	if err != nil {
+		return grpcError(ctx, err)
+	}
+	// セッションが指定されていない場合もストリーム内では顔を追跡する
+	if opts.SessionID == "" {
+		opts.SessionID, err = newStreamSessionID()
+		if err != nil {
+			return grpcError(ctx, err)
+		}
+	}
+
+	var dropped atomic.Int64
+	queue := stream.NewLatestQueue()
+	received := make(chan error, 1)
+	go func() {
+		limiter := s.streams.newFrameLimiter()
+		var seq uint64
+		msg := first
+		for {
+			if frame := msg.GetFrame(); len(frame) > 0 {
+				seq++
+				if !limiter.Allow() {
+					dropped.Add(1)
+				} else if queue.Push(stream.Frame{Seq: seq, Data: frame, ReceivedAt: time.Now()}) {
+					dropped.Add(1)
+				}
+			}
+			var err error
+			msg, err = srv.Recv()
+			if err != nil {
+				received <- err
+				return
+			}
+		}
+	}()
+
+	smoother := stream.NewSmoother(s.streams.smoothingWindow)
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case err := <-received:
+			if err != io.EOF {
+				return err
+			}
+			// 送信が終了した場合は処理待ちのフレームを分析してから終了
+			select {
+			case frame := <-queue.Frames():
+				return s.sendFrame(srv, frame, req, opts, smoother, &dropped)
+			default:
+				return nil
+			}
+		case frame := <-queue.Frames():
+			if err := s.sendFrame(srv, frame, req, opts, smoother, &dropped); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// 1フレームを分析して結果を送信
+func (s *GRPCService) sendFrame(
+	srv faceemotionv1.AnalyzerService_AnalyzeStreamServer,
+	frame stream.Frame,
+	req AnalyzeRequest,
+	opts analyzer.AnalyzeOptions,
+	smoother *stream.Smoother,
+	dropped *atomic.Int64,
+) error {
+	response := &faceemotionv1.AnalyzeStreamResponse{Seq: frame.Seq}
+	result, err := s.streams.analyzeFrame(frame.Data, req, opts, smoother)
+	if err != nil {
+		response.Outcome = &faceemotionv1.AnalyzeStreamResponse_Error{Error: grpcErrorDetail(srv.Context(), err)}
+	} else {
+		response.Outcome = &faceemotionv1.AnalyzeStreamResponse_Result{Result: toAnalyzeResponseProto(result)}
+	}
+	response.Dropped = dropped.Swap(0)
+	return srv.Send(response)
+}
+
+// 画像を検証して分析
+func (s *GRPCService) analyze(ctx context.Context, in *faceemotionv1.AnalyzeRequest) (*faceemotionv1.AnalyzeResponse, error) {
+	req, err := analyzeRequestFromProto(in.GetOptions())
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.face.analyzeOptions(req, grpcAcceptLanguage(ctx))
+	if err != nil {
+		return nil, err
+	}
+	imgBytes := in.GetImage()
+	if in.GetImageUrl() != "" {
+		if len(imgBytes) > 0 {
+			return nil, invalidRequest("image and image_url are mutually exclusive")
+		}
+		imgBytes, err = s.face.requestImage(ctx, "", in.GetImageUrl(), maxRequestSize)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(imgBytes) > maxRequestSize {
+		return nil, errImageTooLarge
+	}
+	if err := validateImageBytes(imgBytes); err != nil {
+		return nil, err
+	}
+
+	results, err := s.face.analyzer.AnalyzeWithOptions(imgBytes, opts)
+	if err != nil {
+		return nil, err
+	}
+	response := s.face.responseV1(req, results, codec.MediaTypeProtobuf)
+	s.face.notifyAnalysis(grpcMetadata(ctx, "x-api-key"), response)
+	return toAnalyzeResponseProto(&response), nil
+}
+
+// gRPCの分析オプションをHTTPのリクエストと同じ形式に変換
+// このサーバーが対応していないフィールド（新しい定義のクライアントが送信したもの）は無視せずにエラーとする
+func analyzeRequestFromProto(options *faceemotionv1.AnalyzeOptions) (AnalyzeRequest, error) {
+	if hasUnknownFields(options) || hasUnknownFields(options.GetRoi()) {
+		return AnalyzeRequest{}, invalidRequest("unsupported analyze options")
+	}
+	req := AnalyzeRequest{
+		PrimaryPolicy: options.GetPrimaryPolicy(),
+		TrackID:       int(options.GetTrackId()),
+		SessionID:     options.GetSessionId(),
+		Explain:       options.GetExplain(),
+		ExplainImages: options.GetExplainImages(),
+		Crowd:         options.GetCrowd(),
+		Locale:        options.GetLocale(),
+	}
+	if roi := options.GetRoi(); roi != nil {
+		req.ROI = &ROIRequest{X: roi.GetX(), Y: roi.GetY(), Width: roi.GetWidth(), Height: roi.GetHeight()}
+		for _, p := range roi.GetPolygon() {
+			if hasUnknownFields(p) {
+				return AnalyzeRequest{}, invalidRequest("unsupported analyze options")
+			}
+			req.ROI.Polygon = append(req.ROI.Polygon, [2]float64{p.GetX(), p.GetY()})
+		}
+	}
+	return req, nil
+}
+
+// メッセージに未知のフィールドが含まれるかを返す
+func hasUnknownFields(m proto.Message) bool {
+	if m == nil || !m.ProtoReflect().IsValid() {
+		return false
+	}
+	return len(m.ProtoReflect().GetUnknown()) > 0
+}
+
+// メタデータの accept-language を返す
+func grpcAcceptLanguage(ctx context.Context) string {
+	return grpcMetadata(ctx, "accept-language")
+}
+
+// メタデータの最初の値を返す
+func grpcMetadata(ctx context.Context, key string) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// エラーを problem と同じ内容の Error に変換
+func grpcErrorDetail(ctx context.Context, err error) *faceemotionv1.Error {
+	p := errors.NewProblem(err, annotation.LocaleFromAcceptLanguage(grpcAcceptLanguage(ctx)))
+	if p.Status >= http.StatusInternalServerError {
+		slog.Error("gRPCのリクエストの処理に失敗", "code", p.Code, "error", err)
+	}
+	return &faceemotionv1.Error{Code: p.Code, Title: p.Title, Detail: p.Detail}
+}
+
+// エラーをgRPCのステータスに変換
+// エラーコードなどは詳細に Error として含める
+func grpcError(ctx context.Context, err error) error {
+	detail := grpcErrorDetail(ctx, err)
+	st := status.New(grpcCode(errors.GetStatusCode(detail.Code)), detail.Detail)
+	if withDetails, err := st.WithDetails(detail); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// HTTPのステータスに対応するgRPCのステータスコード
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusUnprocessableEntity:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// 分析結果をgRPCのレスポンスに変換
+func toAnalyzeResponseProto(r *AnalyzeResponseV1) *faceemotionv1.AnalyzeResponse {
+	faces := make([]*faceemotionv1.Face, len(r.Faces))
+	for i, f := range r.Faces {
+		faces[i] = &faceemotionv1.Face{
+			Box: &faceemotionv1.Box{
+				X: int32(f.Box.X), Y: int32(f.Box.Y), Width: int32(f.Box.Width), Height: int32(f.Box.Height),
+			},
+			NormalizedBox: &faceemotionv1.NormalizedBox{
+				X: f.NormalizedBox.X, Y: f.NormalizedBox.Y, Width: f.NormalizedBox.Width, Height: f.NormalizedBox.Height,
+			},
+			Emotion:       toEmotionProto(f.Emotion),
+			Detector:      f.Detector,
+			Score:         f.Score,
+			TrackId:       int32(f.TrackID),
+			Quality:       f.Quality,
+			Engagement:    f.Engagement,
+			ParticipantId: f.ParticipantID,
+			Similarity:    f.Similarity,
+			Occluded:      f.Occluded,
+			Masked:        f.Masked,
+			Eyes:          toEyesProto(f.Eyes),
+			Explain:       toFaceExplainProto(f.Explain),
+		}
+	}
+	timings := make([]*faceemotionv1.StageTiming, len(r.Timings))
+	for i, t := range r.Timings {
+		timings[i] = &faceemotionv1.StageTiming{Stage: t.Stage, DurationMs: t.DurationMs}
+	}
+
+	return &faceemotionv1.AnalyzeResponse{
+		Image:          &faceemotionv1.ImageInfo{Width: int32(r.Image.Width), Height: int32(r.Image.Height)},
+		Emotion:        toEmotionProto(r.Emotion),
+		Confidence:     r.Confidence,
+		PrimaryIndex:   int32(r.PrimaryIndex),
+		PrimaryTrackId: int32(r.PrimaryTrackID),
+		PrimaryPolicy:  r.PrimaryPolicy,
+		Faces:          faces,
+		Preprocessing:  r.Preprocessing,
+		Session:        toSessionProto(r.Session),
+		ProcessedImage: r.ProcessedImageData,
+		Crowd:          toCrowdProto(r.Crowd),
+		Timings:        timings,
+		GrayscaleImage: dataURLBytes(r.GrayscaleImage),
+		Meta: &faceemotionv1.Meta{
+			ApiVersion:    r.Meta.APIVersion,
+			ServerVersion: r.Meta.ServerVersion,
+			Detectors:     r.Meta.Detectors,
+			Pipeline:      r.Meta.Pipeline,
+		},
+	}
+}
+
+func toEmotionProto(emotion string) faceemotionv1.Emotion {
+	if e, ok := grpcEmotions[emotion]; ok {
+		return e
+	}
+	return faceemotionv1.Emotion_EMOTION_UNSPECIFIED
+}
+
+func toEyesProto(eyes *EyesRegion) *faceemotionv1.Eyes {
+	if eyes == nil {
+		return nil
+	}
+	return &faceemotionv1.Eyes{Openness: eyes.Openness, State: eyes.State, Gaze: eyes.Gaze}
+}
+
+func toFaceExplainProto(e *FaceExplainResponse) *faceemotionv1.FaceExplain {
+	if e == nil {
+		return nil
+	}
+	return &faceemotionv1.FaceExplain{
+		Brightness:        e.Brightness,
+		Variation:         e.Variation,
+		SmileScore:        e.SmileScore,
+		LaplacianVariance: e.LaplacianVariance,
+		Sharpness:         e.Sharpness,
+		SizeScore:         e.SizeScore,
+		Quality:           e.Quality,
+		Emotion:           toEmotionProto(e.Emotion),
+		Rule:              e.Rule,
+		Thresholds:        e.Thresholds,
+		Margins:           e.Margins,
+		RoiImage:          dataURLBytes(e.ROIImage),
+	}
+}
+
+func toCrowdProto(c *CrowdResponse) *faceemotionv1.Crowd {
+	if c == nil {
+		return nil
+	}
+	emotions := make(map[string]int32, len(c.Emotions))
+	for emotion, n := range c.Emotions {
+		emotions[emotion] = int32(n)
+	}
+	return &faceemotionv1.Crowd{
+		FaceCount:    int32(c.FaceCount),
+		Emotions:     emotions,
+		Distribution: c.Distribution,
+		Tiles:        int32(c.Tiles),
+	}
+}
+
+// Base64のデータURLの画像をバイト列に戻す
+func dataURLBytes(dataURL string) []byte {
+	_, encoded, ok := strings.Cut(dataURL, ";base64,")
+	if !ok {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func toSessionProto(agg *session.Aggregate) *faceemotionv1.SessionAggregate {
+	if agg == nil {
+		return nil
+	}
+	counts := make(map[string]int32, len(agg.EmotionCounts))
+	for emotion, n := range agg.EmotionCounts {
+		counts[emotion] = int32(n)
+	}
+	return &faceemotionv1.SessionAggregate{
+		SessionId:         agg.SessionID,
+		Frames:            int32(agg.Frames),
+		FramesWithFaces:   int32(agg.FramesWithFaces),
+		FacesObserved:     int32(agg.FacesObserved),
+		MeanEngagement:    agg.MeanEngagement,
+		MinEngagement:     agg.MinEngagement,
+		MaxEngagement:     agg.MaxEngagement,
+		EngagedFrameRatio: agg.EngagedFrameRatio,
+		EmotionCounts:     counts,
+	}
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	faceemotionv1 "github.com/okamyuji/face-emotion-analyzer/internal/gen/faceemotion/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// メモリ上のgRPCサーバーに接続したクライアントを作成
+func dialTestGRPC(t *testing.T, face *FaceHandler) faceemotionv1.AnalyzerServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	faceemotionv1.RegisterAnalyzerServiceServer(server, NewGRPCService(face, NewStreamHandler(face, 0, 1)))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return faceemotionv1.NewAnalyzerServiceClient(conn)
+}
+
+func TestGRPCService_Analyze(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+	client := dialTestGRPC(t, NewFaceHandler(mockRenderer, mockAnalyzer))
+
+	tests := []struct {
+		name     string
+		request  *faceemotionv1.AnalyzeRequest
+		wantCode codes.Code
+		wantErr  string
+	}{
+		{
+			name: "正常な画像",
+			request: &faceemotionv1.AnalyzeRequest{
+				Image:   testJPEGFrame(t),
+				Options: &faceemotionv1.AnalyzeOptions{PrimaryPolicy: "largest"},
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name:     "不正な画像",
+			request:  &faceemotionv1.AnalyzeRequest{Image: []byte("not an image")},
+			wantCode: codes.InvalidArgument,
+			wantErr:  errors.ErrCodeInvalidImage,
+		},
+		{
+			name: "不正な主要な顔の選択ポリシー",
+			request: &faceemotionv1.AnalyzeRequest{
+				Image:   testJPEGFrame(t),
+				Options: &faceemotionv1.AnalyzeOptions{PrimaryPolicy: "unknown"},
+			},
+			wantCode: codes.InvalidArgument,
+			wantErr:  errors.ErrCodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Analyze(context.Background(), tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode != codes.OK {
+				details := status.Convert(err).Details()
+				require.Len(t, details, 1)
+				detail, ok := details[0].(*faceemotionv1.Error)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantErr, detail.GetCode())
+				return
+			}
+
+			assert.Equal(t, faceemotionv1.Emotion_EMOTION_HAPPY, resp.GetEmotion())
+			assert.Len(t, resp.GetFaces(), 1)
+			assert.Equal(t, APIVersionV1, resp.GetMeta().GetApiVersion())
+		})
+	}
+}
+
+func TestGRPCService_BatchAnalyze(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+	client := dialTestGRPC(t, NewFaceHandler(mockRenderer, mockAnalyzer))
+
+	resp, err := client.BatchAnalyze(context.Background(), &faceemotionv1.BatchAnalyzeRequest{
+		Requests: []*faceemotionv1.AnalyzeRequest{
+			{Image: testJPEGFrame(t)},
+			{Image: []byte("not an image")},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.GetResults(), 2)
+	assert.Equal(t, faceemotionv1.Emotion_EMOTION_HAPPY, resp.GetResults()[0].GetResponse().GetEmotion())
+	assert.Equal(t, errors.ErrCodeInvalidImage, resp.GetResults()[1].GetError().GetCode())
+
+	// 画像のないリクエストはエラー
+	_, err = client.BatchAnalyze(context.Background(), &faceemotionv1.BatchAnalyzeRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCService_AnalyzeStream(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+	client := dialTestGRPC(t, NewFaceHandler(mockRenderer, mockAnalyzer))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.AnalyzeStream(ctx)
+	require.NoError(t, err)
+
+	frame := testJPEGFrame(t)
+	require.NoError(t, stream.Send(&faceemotionv1.AnalyzeStreamRequest{
+		Options: &faceemotionv1.AnalyzeOptions{PrimaryPolicy: "largest"},
+		Frame:   frame,
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.GetSeq())
+	assert.Equal(t, faceemotionv1.Emotion_EMOTION_HAPPY, resp.GetResult().GetEmotion())
+
+	// 不正なフレームはエラーを返してストリームを継続する
+	require.NoError(t, stream.Send(&faceemotionv1.AnalyzeStreamRequest{Frame: []byte("not an image")}))
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), resp.GetSeq())
+	assert.Equal(t, errors.ErrCodeInvalidImage, resp.GetError().GetCode())
+
+	require.NoError(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestAnalyzeRequestFromProto(t *testing.T) {
+	unknown := protowire.AppendVarint(protowire.AppendTag(nil, 99, protowire.VarintType), 1)
+	withUnknown := func(m proto.Message) {
+		m.ProtoReflect().SetUnknown(unknown)
+	}
+
+	unknownOptions := &faceemotionv1.AnalyzeOptions{}
+	withUnknown(unknownOptions)
+	unknownROI := &faceemotionv1.ROI{Width: 1, Height: 1}
+	withUnknown(unknownROI)
+	unknownPoint := &faceemotionv1.Point{X: 0.1, Y: 0.1}
+	withUnknown(unknownPoint)
+
+	tests := []struct {
+		name    string
+		options *faceemotionv1.AnalyzeOptions
+		want    AnalyzeRequest
+		wantErr bool
+	}{
+		{
+			name:    "オプションなし",
+			options: nil,
+			want:    AnalyzeRequest{},
+		},
+		{
+			name: "根拠の出力",
+			options: &faceemotionv1.AnalyzeOptions{
+				Explain:       true,
+				ExplainImages: true,
+			},
+			want: AnalyzeRequest{Explain: true, ExplainImages: true},
+		},
+		{
+			name: "多角形の分析領域",
+			options: &faceemotionv1.AnalyzeOptions{
+				Roi: &faceemotionv1.ROI{Polygon: []*faceemotionv1.Point{
+					{X: 0.1, Y: 0.1}, {X: 0.9, Y: 0.1}, {X: 0.5, Y: 0.9},
+				}},
+			},
+			want: AnalyzeRequest{ROI: &ROIRequest{Polygon: [][2]float64{
+				{0.1, 0.1}, {0.9, 0.1}, {0.5, 0.9},
+			}}},
+		},
+		{
+			name:    "未対応のオプション",
+			options: unknownOptions,
+			wantErr: true,
+		},
+		{
+			name:    "未対応の分析領域のフィールド",
+			options: &faceemotionv1.AnalyzeOptions{Roi: unknownROI},
+			wantErr: true,
+		},
+		{
+			name: "未対応の頂点のフィールド",
+			options: &faceemotionv1.AnalyzeOptions{
+				Roi: &faceemotionv1.ROI{Polygon: []*faceemotionv1.Point{unknownPoint}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzeRequestFromProto(tt.options)
+			if tt.wantErr {
+				assert.Equal(t, codes.InvalidArgument, status.Code(grpcError(context.Background(), err)))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestToAnalyzeResponseProto_Explain(t *testing.T) {
+	roiImage := []byte("png")
+	resp := toAnalyzeResponseProto(&AnalyzeResponseV1{
+		Faces: []FaceV1{{
+			Emotion: "happy",
+			Eyes:    &EyesRegion{Openness: 0.8, State: "open", Gaze: "center"},
+			Explain: &FaceExplainResponse{
+				SmileScore: 0.7,
+				Emotion:    "happy",
+				Rule:       "smile",
+				Thresholds: map[string]float64{"smile": 0.5},
+				ROIImage:   pngDataURL(roiImage),
+			},
+		}},
+		Crowd:   &CrowdResponse{FaceCount: 1, Emotions: map[string]int{"happy": 1}, Tiles: 1},
+		Timings: []StageTimingResponse{{Stage: "detect", DurationMs: 1.5}},
+	})
+
+	require.Len(t, resp.GetFaces(), 1)
+	face := resp.GetFaces()[0]
+	assert.Equal(t, "open", face.GetEyes().GetState())
+	assert.Equal(t, faceemotionv1.Emotion_EMOTION_HAPPY, face.GetExplain().GetEmotion())
+	assert.Equal(t, "smile", face.GetExplain().GetRule())
+	assert.Equal(t, 0.5, face.GetExplain().GetThresholds()["smile"])
+	assert.Equal(t, roiImage, face.GetExplain().GetRoiImage())
+	assert.Equal(t, int32(1), resp.GetCrowd().GetEmotions()["happy"])
+	require.Len(t, resp.GetTimings(), 1)
+	assert.Equal(t, "detect", resp.GetTimings()[0].GetStage())
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/codec"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/job"
+)
+
+// ジョブ数が上限に達した場合に再試行を促すまでの秒数
+const jobRetryAfter = "5"
+
+// ジョブのリクエストボディと画像データのデフォルトのサイズ上限（20MB）
+const defaultJobMaxUploadSize = 20 * 1024 * 1024
+
+// 分析を非同期に実行するジョブのハンドラー
+type JobHandler struct {
+	face *FaceHandler
+	jobs *job.Manager
+	// 同期の分析より大きな画像を受け付けるためのサイズ上限
+	maxUploadSize int64
+}
+
+// ジョブのハンドラーを作成
+// maxUploadSize が0以下の場合はデフォルト値を使用する
+func NewJobHandler(face *FaceHandler, jobs *job.Manager, maxUploadSize int64) *JobHandler {
+	if maxUploadSize <= 0 {
+		maxUploadSize = defaultJobMaxUploadSize
+	}
+	return &JobHandler{face: face, jobs: jobs, maxUploadSize: maxUploadSize}
+}
+
+// ジョブの状態のレスポンス
+type JobResponse struct {
+	ID        string     `json:"id"`
+	Status    job.Status `json:"status"`
+	Progress  float64    `json:"progress"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	// 完了した場合の分析結果
+	Result *AnalyzeResponseV1 `json:"result,omitempty"`
+	// 失敗した場合のエラー
+	Error *errors.Problem `json:"error,omitempty"`
+}
+
+// 分析のジョブを作成
+// リクエストの形式は /api/v1/analyze と同じで、202 と状態の取得先を返す
+func (h *JobHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	req, imgBytes, opts, ok := h.face.readAnalyzeRequest(w, r, h.maxUploadSize)
+	if !ok {
+		return
+	}
+	if req.CallbackURL != "" {
+		if h.face.webhooks == nil {
+			errors.WriteProblem(w, r, invalidRequest("webhooks are disabled"))
+			return
+		}
+		if err := h.face.webhooks.CheckURL(req.CallbackURL); err != nil {
+			errors.WriteProblem(w, r, err)
+			return
+		}
+	}
+
+	locale := annotation.LocaleFromAcceptLanguage(r.Header.Get("Accept-Language"))
+	notify := h.notifyJob(r.Header.Get(APIKeyHeader), req.CallbackURL, locale)
+	created, err := h.jobs.SubmitNotify(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		opts.Progress = progress
+		results, err := h.face.analyzer.AnalyzeWithOptions(imgBytes, opts)
+		if err != nil {
+			return nil, err
+		}
+		response := h.face.responseV1(req, results, codec.MediaTypeJSON)
+		return &response, nil
+	}, notify)
+	if errors.Is(err, errors.ErrJobQueueFull) {
+		w.Header().Set("Retry-After", jobRetryAfter)
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeUnavailable, "too many pending jobs", err))
+		return
+	}
+	if err != nil {
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeInternalError, "job creation failed", err))
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/jobs/"+created.ID)
+	h.writeJob(w, r, http.StatusAccepted, created)
+}
+
+// ジョブの状態を返す
+func (h *JobHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	found, ok := h.jobs.Get(r.PathValue("id"))
+	if !ok {
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeNotFound, "job not found", nil))
+		return
+	}
+	h.writeJob(w, r, http.StatusOK, found)
+}
+
+// ジョブをキャンセル
+// 終了済みのジョブは 409 を返す
+func (h *JobHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	cancelled, err := h.jobs.Cancel(r.PathValue("id"))
+	switch {
+	case errors.Is(err, errors.ErrJobNotFound):
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeNotFound, "job not found", err))
+		return
+	case errors.Is(err, errors.ErrJobFinished):
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeConflict, "job already finished", err))
+		return
+	case err != nil:
+		errors.WriteProblem(w, r, err)
+		return
+	}
+	h.writeJob(w, r, http.StatusOK, cancelled)
+}
+
+// ジョブの終了を callbackUrl と APIキーに登録された配信先に通知する関数を返す
+// Webhookが無効な場合は nil を返す
+func (h *JobHandler) notifyJob(apiKey, callbackURL, locale string) job.NotifyFunc {
+	webhooks := h.face.webhooks
+	if webhooks == nil {
+		return nil
+	}
+	return func(j job.Job) {
+		eventType := jobEventType(j.Status)
+		response := jobResponse(j, locale)
+		response.Result = webhookResult(response.Result)
+		if callbackURL != "" {
+			webhooks.Send(callbackURL, eventType, response)
+		}
+		webhooks.Notify(apiKey, eventType, response)
+	}
+}
+
+func (h *JobHandler) writeJob(w http.ResponseWriter, r *http.Request, status int, j job.Job) {
+	response := jobResponse(j, annotation.LocaleFromAcceptLanguage(r.Header.Get("Accept-Language")))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
+
+func jobResponse(j job.Job, locale string) JobResponse {
+	response := JobResponse{
+		ID:        j.ID,
+		Status:    j.Status,
+		Progress:  j.Progress,
+		CreatedAt: j.CreatedAt,
+		UpdatedAt: j.UpdatedAt,
+	}
+	if result, ok := j.Result.(*AnalyzeResponseV1); ok {
+		response.Result = result
+	}
+	if j.Err != nil {
+		p := errors.NewProblem(j.Err, locale)
+		response.Error = &p
+	}
+	return response
+}
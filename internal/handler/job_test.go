@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/job"
+	"github.com/okamyuji/face-emotion-analyzer/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJobHandler(t *testing.T, face *FaceHandler) *JobHandler {
+	t.Helper()
+	pool := worker.NewPool(1, 1)
+	t.Cleanup(func() {
+		_ = pool.Shutdown(context.Background())
+	})
+	return NewJobHandler(face, job.NewManager(pool, time.Minute, 0), 0)
+}
+
+// ジョブが終了するまで状態を取得する
+func waitJobFinished(t *testing.T, router http.Handler, location string) JobResponse {
+	t.Helper()
+	var resp JobResponse
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, createTestRequest(t, http.MethodGet, location, nil))
+		if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&resp) != nil {
+			return false
+		}
+		return resp.Status.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return resp
+}
+
+func TestJobHandler(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name       string
+		analyzeErr error
+		wantStatus job.Status
+		wantCode   string
+	}{
+		{
+			name:       "完了",
+			wantStatus: job.StatusDone,
+		},
+		{
+			name:       "失敗",
+			analyzeErr: errors.CodeError(errors.ErrCodeInvalidImage, "無効な画像データです", nil),
+			wantStatus: job.StatusFailed,
+			wantCode:   errors.ErrCodeInvalidImage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			face := NewFaceHandler(mockRenderer, &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					if tt.analyzeErr != nil {
+						return nil, tt.analyzeErr
+					}
+					return &analyzer.AnalysisResult{
+						PrimaryEmotion: analyzer.EmotionHappy,
+						ImageWidth:     320,
+						ImageHeight:    240,
+					}, nil
+				},
+			})
+			router := newTestAPIv1Router(t, face)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, createTestRequest(t, http.MethodPost, "/api/v1/jobs", map[string]interface{}{"image": imageData}))
+			require.Equal(t, http.StatusAccepted, rec.Code)
+
+			var created JobResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+			assert.Equal(t, "/api/v1/jobs/"+created.ID, rec.Header().Get("Location"))
+
+			resp := waitJobFinished(t, router, rec.Header().Get("Location"))
+			assert.Equal(t, tt.wantStatus, resp.Status)
+			if tt.wantCode != "" {
+				require.NotNil(t, resp.Error)
+				assert.Equal(t, tt.wantCode, resp.Error.Code)
+				assert.Nil(t, resp.Result)
+				return
+			}
+			require.NotNil(t, resp.Result)
+			assert.Equal(t, "happy", resp.Result.Emotion)
+			assert.Equal(t, 1.0, resp.Progress)
+
+			// 終了したジョブはキャンセルできない
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, createTestRequest(t, http.MethodDelete, "/api/v1/jobs/"+created.ID, nil))
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Equal(t, errors.ErrCodeConflict, decodeProblem(t, rec).Code)
+		})
+	}
+}
+
+func TestJobHandler_Cancel(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	release := make(chan struct{})
+	defer close(release)
+	face := NewFaceHandler(mockRenderer, &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			<-release
+			return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionHappy}, nil
+		},
+	})
+	router := newTestAPIv1Router(t, face)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, createTestRequest(t, http.MethodPost, "/api/v1/jobs", map[string]interface{}{"image": testImageDataURL(t)}))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	location := rec.Header().Get("Location")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, createTestRequest(t, http.MethodDelete, location, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp JobResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, job.StatusCancelled, resp.Status)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		t.Run(fmt.Sprintf("存在しないジョブ（%s）", method), func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, createTestRequest(t, method, "/api/v1/jobs/missing", nil))
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			decodeProblem(t, rec)
+		})
+	}
+}
+
+func TestJobHandler_QueueFull(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	release := make(chan struct{})
+	defer close(release)
+	face := NewFaceHandler(mockRenderer, &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			<-release
+			return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionHappy}, nil
+		},
+	})
+	pool := worker.NewPool(1, 1)
+	t.Cleanup(func() {
+		_ = pool.Shutdown(context.Background())
+	})
+	jobs := NewJobHandler(face, job.NewManager(pool, time.Minute, 1), 0)
+	body := map[string]interface{}{"image": testImageDataURL(t)}
+
+	rec := httptest.NewRecorder()
+	jobs.HandleCreate(rec, createTestRequest(t, http.MethodPost, "/api/v1/jobs", body))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	// 待機中と実行中のジョブ数が上限に達している
+	rec = httptest.NewRecorder()
+	jobs.HandleCreate(rec, createTestRequest(t, http.MethodPost, "/api/v1/jobs", body))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, jobRetryAfter, rec.Header().Get("Retry-After"))
+	assert.Equal(t, errors.ErrCodeUnavailable, decodeProblem(t, rec).Code)
+}
+
+func TestJobHandler_MaxUploadSize(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	face := NewFaceHandler(mockRenderer, &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionHappy}, nil
+		},
+	})
+	const maxUploadSize = 2 * maxRequestSize
+
+	tests := []struct {
+		name       string
+		size       int
+		wantStatus int
+	}{
+		{
+			name:       "同期の分析の上限を超える画像",
+			size:       maxRequestSize + 1,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "ジョブの上限を超える画像",
+			size:       maxUploadSize + 1,
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := worker.NewPool(1, 1)
+			t.Cleanup(func() {
+				_ = pool.Shutdown(context.Background())
+			})
+			jobs := NewJobHandler(face, job.NewManager(pool, time.Minute, 0), maxUploadSize)
+			image := "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(make([]byte, tt.size))
+
+			rec := httptest.NewRecorder()
+			jobs.HandleCreate(rec, createTestRequest(t, http.MethodPost, "/api/v1/jobs", map[string]interface{}{"image": image}))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusAccepted {
+				assert.Equal(t, errors.ErrCodeRequestTooLarge, decodeProblem(t, rec).Code)
+			}
+		})
+	}
+}
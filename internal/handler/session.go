@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+)
+
+// クライアントが指定したセッションIDとして受け付ける形式
+var validSessionID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// セッションの集計結果を返すハンドラー
+type SessionHandler struct {
+	store *session.Store
+}
+
+func NewSessionHandler(store *session.Store) *SessionHandler {
+	return &SessionHandler{store: store}
+}
+
+// セッションの集計結果を返す
+func (h *SessionHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validSessionID.MatchString(id) {
+		errors.WriteProblem(w, r, invalidRequest("invalid session id"))
+		return
+	}
+	agg, ok := h.store.Get(id)
+	if !ok {
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeNotFound, "session not found", nil))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(agg); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEngagementRecorder struct {
+	mu     sync.Mutex
+	scores []float64
+}
+
+func (m *mockEngagementRecorder) RecordEngagement(score float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.scores = append(m.scores, score)
+}
+
+func TestFaceHandler_HandleAnalyze_Engagement(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	mockAnalyzer := &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			return &analyzer.AnalysisResult{
+				Faces: []analyzer.Face{
+					{X: 10, Y: 10, Width: 20, Height: 20, Engagement: 0.8},
+					{X: 100, Y: 100, Width: 20, Height: 20, Engagement: 0.4},
+				},
+				PrimaryEmotion: analyzer.EmotionHappy,
+				Confidence:     0.9,
+			}, nil
+		},
+	}
+	recorder := &mockEngagementRecorder{}
+	store := session.NewStore(time.Minute, 0.5, 0)
+
+	handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+	handler.SetSessionStore(store)
+	handler.SetMetrics(recorder)
+
+	body := map[string]string{"image": testImageDataURL(t), "sessionId": "lab-1"}
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler.HandleAnalyze(rec, createTestRequest(t, http.MethodPost, "/analyze", body))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp AnalyzeResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		assert.InDelta(t, 0.8, resp.Faces[0].Engagement, 1e-9)
+		require.NotNil(t, resp.Session)
+		assert.Equal(t, i+1, resp.Session.Frames)
+		assert.InDelta(t, 0.6, resp.Session.MeanEngagement, 1e-9)
+	}
+	assert.Equal(t, []float64{0.8, 0.4, 0.8, 0.4}, recorder.scores)
+
+	// セッションの集計結果の取得
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sessions/{id}", NewSessionHandler(store).HandleGet)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, createTestRequest(t, http.MethodGet, "/sessions/lab-1", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var agg session.Aggregate
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&agg))
+	assert.Equal(t, 2, agg.Frames)
+	assert.Equal(t, map[string]int{"happy": 2}, agg.EmotionCounts)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, createTestRequest(t, http.MethodGet, "/sessions/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// 不正な形式のセッションID
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, createTestRequest(t, http.MethodGet, "/sessions/"+strings.Repeat("a", 65), nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"golang.org/x/time/rate"
+)
+
+// フレームの結果の送信のタイムアウト
+const streamWriteTimeout = 10 * time.Second
+
+// WebSocketで受信したフレームを連続して分析するハンドラー
+type StreamHandler struct {
+	face     *FaceHandler
+	upgrader websocket.Upgrader
+	// 1接続あたりの分析するフレーム数の上限（0の場合は制限しない）
+	maxFPS float64
+	// 平滑化に使用するフレーム数
+	smoothingWindow int
+}
+
+// オリジンの検証はセキュリティミドルウェアのCORSの検証で行う
+func NewStreamHandler(face *FaceHandler, maxFPS float64, smoothingWindow int) *StreamHandler {
+	return &StreamHandler{
+		face: face,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+		maxFPS:          maxFPS,
+		smoothingWindow: smoothingWindow,
+	}
+}
+
+// フレームごとにクライアントに送信するメッセージ
+type StreamFrameResponse struct {
+	// 分析したフレームの通し番号
+	Seq uint64 `json:"seq"`
+	// 前回の送信以降に破棄したフレーム数
+	Dropped int64 `json:"dropped"`
+	// 分析結果（処理済み画像は含めない）
+	Result *AnalyzeResponseV1 `json:"result,omitempty"`
+	// フレームの分析に失敗した場合のエラー
+	Error *errors.Problem `json:"error,omitempty"`
+}
+
+// WebSocketで画像のフレームを受信し、フレームごとの分析結果を返す
+// 分析オプションはクエリパラメータで指定し、フレームはバイナリメッセージで送信する
+// 分析が追いつかない場合や上限を超えたフレームは破棄する
+func (h *StreamHandler) Handle(w http.ResponseWriter, r *http.Request) {
+	req, err := analyzeRequestFromValues(r.URL.Query())
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+	opts, err := h.face.analyzeOptions(req, r.Header.Get("Accept-Language"))
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+	// セッションが指定されていない場合も接続内では顔を追跡する
+	if opts.SessionID == "" {
+		opts.SessionID, err = newStreamSessionID()
+		if err != nil {
+			errors.WriteProblem(w, r, err)
+			return
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade がエラーレスポンスを送信する
+		slog.Error("WebSocketへの切り替えに失敗", "error", err)
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxRequestSize)
+
+	var dropped atomic.Int64
+	queue := stream.NewLatestQueue()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.process(conn, r, req, opts, queue, &dropped, stop)
+	}()
+
+	h.receive(conn, queue, &dropped)
+	close(stop)
+	<-done
+}
+
+// クライアントからフレームを受信してキューに追加
+// 接続が閉じられるまで戻らない
+func (h *StreamHandler) receive(conn *websocket.Conn, queue *stream.LatestQueue, dropped *atomic.Int64) {
+	limiter := h.newFrameLimiter()
+	var seq uint64
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.Debug("WebSocketの受信を終了", "error", err)
+			}
+			return
+		}
+		seq++
+		if !limiter.Allow() {
+			dropped.Add(1)
+			continue
+		}
+		if queue.Push(stream.Frame{Seq: seq, Data: data, ReceivedAt: time.Now()}) {
+			dropped.Add(1)
+		}
+	}
+}
+
+// 1接続あたりのフレームレートの制限を作成
+func (h *StreamHandler) newFrameLimiter() *rate.Limiter {
+	limit := rate.Inf
+	if h.maxFPS > 0 {
+		limit = rate.Limit(h.maxFPS)
+	}
+	return rate.NewLimiter(limit, 1)
+}
+
+// キューのフレームを順に分析して結果を送信
+func (h *StreamHandler) process(
+	conn *websocket.Conn,
+	r *http.Request,
+	req AnalyzeRequest,
+	opts analyzer.AnalyzeOptions,
+	queue *stream.LatestQueue,
+	dropped *atomic.Int64,
+	stop <-chan struct{},
+) {
+	smoother := stream.NewSmoother(h.smoothingWindow)
+	locale := annotation.LocaleFromAcceptLanguage(r.Header.Get("Accept-Language"))
+
+	for {
+		var frame stream.Frame
+		select {
+		case <-stop:
+			return
+		case frame = <-queue.Frames():
+		}
+
+		response := StreamFrameResponse{Seq: frame.Seq}
+		result, err := h.analyzeFrame(frame.Data, req, opts, smoother)
+		if err != nil {
+			p := errors.NewProblem(err, locale)
+			response.Error = &p
+		} else {
+			response.Result = result
+		}
+		response.Dropped = dropped.Swap(0)
+
+		if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err == nil {
+			err = conn.WriteJSON(response)
+		}
+		if err != nil {
+			slog.Debug("WebSocketの送信に失敗", "error", err)
+			// 受信側も終了させる
+			conn.Close()
+			return
+		}
+	}
+}
+
+// 1フレームを分析し、平滑化した結果を返す
+func (h *StreamHandler) analyzeFrame(
+	data []byte,
+	req AnalyzeRequest,
+	opts analyzer.AnalyzeOptions,
+	smoother *stream.Smoother,
+) (*AnalyzeResponseV1, error) {
+	if len(data) > maxRequestSize {
+		return nil, errImageTooLarge
+	}
+	if err := validateImageBytes(data); err != nil {
+		return nil, err
+	}
+	results, err := h.face.analyzer.AnalyzeWithOptions(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// ストリームでは処理済み画像を返さない
+	response := h.face.responseV1(req, results, "")
+	smoothFaces(smoother, &response)
+	return &response, nil
+}
+
+// 追跡中の顔の感情とエンゲージメントスコアを平滑化した値に置き換える
+func smoothFaces(smoother *stream.Smoother, response *AnalyzeResponseV1) {
+	observations := make([]stream.Observation, len(response.Faces))
+	for i, face := range response.Faces {
+		observations[i] = stream.Observation{
+			TrackID:    face.TrackID,
+			Emotion:    face.Emotion,
+			Engagement: face.Engagement,
+		}
+	}
+	for i, obs := range smoother.Update(observations) {
+		response.Faces[i].Emotion = obs.Emotion
+		response.Faces[i].Engagement = obs.Engagement
+	}
+	if response.PrimaryIndex >= 0 && response.PrimaryIndex < len(response.Faces) {
+		response.Emotion = response.Faces[response.PrimaryIndex].Emotion
+	}
+}
+
+func newStreamSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.CodeError(errors.ErrCodeInternalError, "failed to generate session id", err)
+	}
+	return "stream-" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"bytes"
+	"image/jpeg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ストリームのハンドラーのサーバーに接続
+func dialTestStream(t *testing.T, h *StreamHandler, query string) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(h.Handle))
+	t.Cleanup(server.Close)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/?" + query
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func testJPEGFrame(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, createTestImage(testImageWidth, testImageHeight), &jpeg.Options{Quality: testQuality}))
+	return buf.Bytes()
+}
+
+func readStreamFrame(t *testing.T, conn *websocket.Conn) StreamFrameResponse {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var resp StreamFrameResponse
+	require.NoError(t, conn.ReadJSON(&resp))
+	return resp
+}
+
+func TestStreamHandler(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	// 同じ顔の感情がフレームごとに変化する
+	emotions := []analyzer.Emotion{analyzer.EmotionHappy, analyzer.EmotionSad, analyzer.EmotionHappy}
+	var mu sync.Mutex
+	calls := 0
+	mockAnalyzer := &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			emotion := emotions[calls%len(emotions)]
+			calls++
+			return &analyzer.AnalysisResult{
+				Faces:              []analyzer.Face{{Width: 10, Height: 10, TrackID: 1, Emotion: emotion, Engagement: float64(calls) / 10}},
+				PrimaryEmotion:     emotion,
+				ProcessedImageData: []byte("jpeg"),
+				ImageWidth:         320,
+				ImageHeight:        240,
+			}, nil
+		},
+	}
+	face := NewFaceHandler(mockRenderer, mockAnalyzer)
+	conn := dialTestStream(t, NewStreamHandler(face, 0, 3), "primaryPolicy=largest")
+
+	frame := testJPEGFrame(t)
+	wantEmotions := []string{"happy", "sad", "happy"}
+	wantEngagement := []float64{0.1, 0.15, 0.2}
+	for i := range wantEmotions {
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, frame))
+		resp := readStreamFrame(t, conn)
+		assert.Equal(t, uint64(i+1), resp.Seq)
+		require.NotNil(t, resp.Result)
+		assert.Empty(t, resp.Result.ProcessedImage)
+		require.Len(t, resp.Result.Faces, 1)
+		assert.InDelta(t, wantEngagement[i], resp.Result.Faces[0].Engagement, 1e-9)
+		// 2フレーム目は最頻値が同数のため新しい感情になる
+		assert.Equal(t, wantEmotions[i], resp.Result.Faces[0].Emotion)
+		assert.Equal(t, wantEmotions[i], resp.Result.Emotion)
+	}
+
+	// トラッキングのためのセッションIDを接続ごとに割り当てる
+	assert.True(t, strings.HasPrefix(mockAnalyzer.getLastOptions().SessionID, "stream-"))
+	assert.Equal(t, analyzer.PrimaryLargest, mockAnalyzer.getLastOptions().PrimaryPolicy)
+
+	// 画像でないフレームはエラーを返して接続を維持する
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("not an image")))
+	resp := readStreamFrame(t, conn)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, errors.ErrCodeInvalidImage, resp.Error.Code)
+	assert.Nil(t, resp.Result)
+}
+
+func TestStreamHandler_DropsStaleFrames(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	face := NewFaceHandler(mockRenderer, &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			started <- struct{}{}
+			<-release
+			return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionUnknown, PrimaryIndex: -1}, nil
+		},
+	})
+	conn := dialTestStream(t, NewStreamHandler(face, 0, 1), "")
+
+	frame := testJPEGFrame(t)
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, frame))
+	<-started
+
+	// 分析中に受信したフレームは最新のものだけを残す
+	for i := 0; i < 3; i++ {
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, frame))
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	resp := readStreamFrame(t, conn)
+	assert.Equal(t, uint64(1), resp.Seq)
+	assert.Equal(t, int64(2), resp.Dropped)
+	resp = readStreamFrame(t, conn)
+	assert.Equal(t, uint64(4), resp.Seq)
+	assert.Zero(t, resp.Dropped)
+}
+
+func TestStreamHandler_InvalidOptions(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	h := NewStreamHandler(NewFaceHandler(mockRenderer, &mockFaceAnalyzer{}), 0, 1)
+	rec := httptest.NewRecorder()
+	h.Handle(rec, createTestRequest(t, http.MethodGet, "/api/v1/stream?primaryPolicy=invalid", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, errors.ErrCodeInvalidRequest, decodeProblem(t, rec).Code)
+}
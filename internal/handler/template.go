@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/annotation"
 )
 
 // テンプレートに渡すデータ構造体
@@ -55,24 +56,12 @@ func (tr *TemplateRenderer) ExecuteTemplate(w http.ResponseWriter, name string,
 // FaceAnalyzer用のインターフェース
 type FaceAnalyzerInterface interface {
 	Analyze(data []byte) (*analyzer.AnalysisResult, error)
+	AnalyzeWithOptions(data []byte, opts analyzer.AnalyzeOptions) (*analyzer.AnalysisResult, error)
 }
 
 // 型変換のためのヘルパー関数を追加
 func EmotionToString(emotion analyzer.Emotion) string {
-	switch emotion {
-	case analyzer.EmotionHappy:
-		return "喜び"
-	case analyzer.EmotionSad:
-		return "悲しみ"
-	case analyzer.EmotionAngry:
-		return "怒り"
-	case analyzer.EmotionNeutral:
-		return "普通"
-	case analyzer.EmotionSurprise:
-		return "驚き"
-	default:
-		return "不明"
-	}
+	return annotation.EmotionLabel(string(emotion), annotation.LocaleJa)
 }
 
 // ファイルシステムからテンプレートを読み込む新しいレンダラーを作成
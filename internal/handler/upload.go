@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+)
+
+// multipart/form-data の画像以外のフィールドの最大サイズ
+const maxFormValueSize = 64 * 1024
+
+// バイナリで受け付ける画像の形式
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/bmp":  true,
+}
+
+var errImageTooLarge = errors.CodeError(errors.ErrCodeRequestTooLarge, "image size exceeds limit", nil)
+
+// リクエストの形式が不正な場合のエラーを作成
+func invalidRequest(message string) *errors.Error {
+	return errors.CodeError(errors.ErrCodeInvalidRequest, message, nil)
+}
+
+// 画像データが不正な場合のエラーを作成
+func invalidImage(message string) *errors.Error {
+	return errors.CodeError(errors.ErrCodeInvalidImage, message, nil)
+}
+
+// JSONのリクエストを読み取る
+// 画像はBase64のデータURIのまま返し、デコードは呼び出し元で行う
+func readJSONAnalyzeRequest(w http.ResponseWriter, r *http.Request, limit int64) (AnalyzeRequest, error) {
+	// リクエストボディの読み取り前にデバッグログ
+	slog.Debug("リクエスト受信",
+		"content_length", r.ContentLength,
+		"content_type", r.Header.Get("Content-Type"),
+		"csrf_token", r.Header.Get("X-CSRF-Token"),
+		"transfer_encoding", r.TransferEncoding)
+
+	// リクエストボディの読み取り
+	if r.Body == nil {
+		slog.Error("リクエストボディが空です")
+		return AnalyzeRequest{}, invalidRequest("empty request body")
+	}
+
+	defer r.Body.Close()
+	if r.ContentLength > limit {
+		return AnalyzeRequest{}, errImageTooLarge
+	}
+
+	// ボディ全体を別に保持せず、読み取りながらデコードする
+	var req AnalyzeRequest
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit)).Decode(&req)
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		slog.Error("リクエストボディの読み取りに失敗",
+			"error", err,
+			"content_length", r.ContentLength,
+			"transfer_encoding", r.TransferEncoding)
+		return AnalyzeRequest{}, errImageTooLarge
+	case errors.Is(err, io.EOF):
+		slog.Error("リクエストボディが空です",
+			"content_length", r.ContentLength,
+			"transfer_encoding", r.TransferEncoding)
+		return AnalyzeRequest{}, invalidRequest("empty request body")
+	case err != nil:
+		slog.Error("JSONのデコードに失敗", "error", err, "content_length", r.ContentLength)
+		return AnalyzeRequest{}, invalidRequest("invalid request body")
+	}
+	return req, nil
+}
+
+// multipart/form-data のリクエストを読み取る
+// image フィールドの画像をパートごとに上限まで読み取り、その他のフィールドは分析オプションとして扱う
+func readMultipartAnalyzeRequest(w http.ResponseWriter, r *http.Request, limit int64) (AnalyzeRequest, []byte, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, limit+maxFormValueSize)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return AnalyzeRequest{}, nil, invalidRequest("invalid multipart body")
+	}
+
+	values := url.Values{}
+	var imgBytes []byte
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return AnalyzeRequest{}, nil, multipartReadError(err)
+		}
+
+		name := part.FormName()
+		if name == "image" {
+			imgBytes, err = readLimited(part, limit, 0)
+		} else {
+			var value []byte
+			value, err = readLimited(part, maxFormValueSize, 0)
+			values.Add(name, string(value))
+		}
+		part.Close()
+		if err != nil {
+			return AnalyzeRequest{}, nil, multipartReadError(err)
+		}
+	}
+
+	if imgBytes == nil {
+		return AnalyzeRequest{}, nil, invalidRequest("missing image field")
+	}
+	if err := validateImageBytes(imgBytes); err != nil {
+		return AnalyzeRequest{}, nil, err
+	}
+	req, err := analyzeRequestFromValues(values)
+	if err != nil {
+		return AnalyzeRequest{}, nil, err
+	}
+	return req, imgBytes, nil
+}
+
+// image/* のリクエストを読み取る
+// ボディをそのまま画像とし、分析オプションはクエリパラメータで受け取る
+func readRawAnalyzeRequest(w http.ResponseWriter, r *http.Request, limit int64) (AnalyzeRequest, []byte, error) {
+	if r.Body == nil {
+		return AnalyzeRequest{}, nil, invalidRequest("empty request body")
+	}
+	defer r.Body.Close()
+
+	imgBytes, err := readLimited(r.Body, limit, r.ContentLength)
+	if err != nil {
+		slog.Error("リクエストボディの読み取りに失敗", "error", err, "content_length", r.ContentLength)
+		return AnalyzeRequest{}, nil, errImageTooLarge
+	}
+	if err := validateImageBytes(imgBytes); err != nil {
+		return AnalyzeRequest{}, nil, err
+	}
+	req, err := analyzeRequestFromValues(r.URL.Query())
+	if err != nil {
+		return AnalyzeRequest{}, nil, err
+	}
+	return req, imgBytes, nil
+}
+
+// 上限を超えない範囲で読み取る
+// 上限を超える場合はエラーを返す
+// sizeHint（不明な場合は0以下）が分かる場合は読み取る前に上限を確認し、バッファを一度で確保する
+func readLimited(r io.Reader, limit, sizeHint int64) ([]byte, error) {
+	if sizeHint > limit {
+		return nil, errImageTooLarge
+	}
+	var buf bytes.Buffer
+	if sizeHint > 0 {
+		buf.Grow(int(sizeHint) + bytes.MinRead)
+	}
+	if _, err := buf.ReadFrom(io.LimitReader(r, limit+1)); err != nil {
+		return nil, err
+	}
+	if int64(buf.Len()) > limit {
+		return nil, errImageTooLarge
+	}
+	return buf.Bytes(), nil
+}
+
+func multipartReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.Is(err, errImageTooLarge) || errors.As(err, &maxBytesErr) {
+		return errImageTooLarge
+	}
+	return invalidRequest("invalid multipart body")
+}
+
+// 画像データの形式を先頭のバイト列から判定して検証
+func validateImageBytes(data []byte) error {
+	if len(data) == 0 {
+		return invalidImage("empty image data")
+	}
+	if !supportedImageTypes[http.DetectContentType(data)] {
+		return invalidImage("invalid image data format")
+	}
+	return nil
+}
+
+// フォームのフィールドやクエリパラメータから分析オプションを作成
+// フィールド名はJSONのリクエストと同じで、roi はJSONで指定する
+func analyzeRequestFromValues(values url.Values) (AnalyzeRequest, error) {
+	req := AnalyzeRequest{
+		PrimaryPolicy: values.Get("primaryPolicy"),
+		SessionID:     values.Get("sessionId"),
+		Locale:        values.Get("locale"),
+		CallbackURL:   values.Get("callbackUrl"),
+	}
+	if v := values.Get("trackId"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return AnalyzeRequest{}, invalidRequest("invalid trackId")
+		}
+		req.TrackID = id
+	}
+
+	flags := []struct {
+		name string
+		dst  *bool
+	}{
+		{"explain", &req.Explain},
+		{"explainImages", &req.ExplainImages},
+		{"crowd", &req.Crowd},
+	}
+	for _, f := range flags {
+		v := values.Get(f.name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return AnalyzeRequest{}, invalidRequest("invalid " + f.name)
+		}
+		*f.dst = b
+	}
+
+	if v := values.Get("roi"); v != "" {
+		if err := json.Unmarshal([]byte(v), &req.ROI); err != nil {
+			return AnalyzeRequest{}, invalidRequest("invalid roi")
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,381 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/fetch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testImageBytes(tb testing.TB) []byte {
+	tb.Helper()
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(testImageDataURL(tb), "data:image/jpeg;base64,"))
+	require.NoError(tb, err)
+	return data
+}
+
+func TestAnalyzeRequestFromValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		want    AnalyzeRequest
+		wantErr string
+	}{
+		{
+			name:   "未指定",
+			values: url.Values{},
+			want:   AnalyzeRequest{},
+		},
+		{
+			name: "全てのオプション",
+			values: url.Values{
+				"primaryPolicy": {"tracked"},
+				"trackId":       {"3"},
+				"sessionId":     {"s1"},
+				"explain":       {"true"},
+				"crowd":         {"1"},
+				"locale":        {"en"},
+				"roi":           {`{"x":0.1,"y":0.2,"width":0.5,"height":0.6}`},
+			},
+			want: AnalyzeRequest{
+				PrimaryPolicy: "tracked",
+				TrackID:       3,
+				SessionID:     "s1",
+				Explain:       true,
+				Crowd:         true,
+				Locale:        "en",
+				ROI:           &ROIRequest{X: 0.1, Y: 0.2, Width: 0.5, Height: 0.6},
+			},
+		},
+		{
+			name:    "数値でないトラッキングID",
+			values:  url.Values{"trackId": {"abc"}},
+			wantErr: "invalid trackId",
+		},
+		{
+			name:    "真偽値でないフラグ",
+			values:  url.Values{"explainImages": {"yes"}},
+			wantErr: "invalid explainImages",
+		},
+		{
+			name:    "JSONでない分析対象領域",
+			values:  url.Values{"roi": {"0.1,0.2"}},
+			wantErr: "invalid roi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := analyzeRequestFromValues(tt.values)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req)
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_Upload(t *testing.T) {
+	mockRenderer, _, cleanup := setupTest(t)
+	defer cleanup()
+
+	imgBytes := testImageBytes(t)
+
+	multipartRequest := func(fields map[string]string, image []byte) *http.Request {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		for name, value := range fields {
+			require.NoError(t, mw.WriteField(name, value))
+		}
+		if image != nil {
+			part, err := mw.CreateFormFile("image", "face.jpg")
+			require.NoError(t, err)
+			_, err = part.Write(image)
+			require.NoError(t, err)
+		}
+		require.NoError(t, mw.Close())
+		req := httptest.NewRequest(http.MethodPost, "/analyze", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		return req
+	}
+	rawRequest := func(contentType, query string, body []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/analyze"+query, bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		return req
+	}
+
+	tests := []struct {
+		name        string
+		request     *http.Request
+		wantStatus  int
+		wantError   string
+		wantOptions analyzer.AnalyzeOptions
+	}{
+		{
+			name:        "multipart/form-data",
+			request:     multipartRequest(map[string]string{"primaryPolicy": "center", "sessionId": "s1"}, imgBytes),
+			wantStatus:  http.StatusOK,
+			wantOptions: analyzer.AnalyzeOptions{PrimaryPolicy: "center", SessionID: "s1"},
+		},
+		{
+			name:       "multipart/form-data の画像がない",
+			request:    multipartRequest(map[string]string{"primaryPolicy": "center"}, nil),
+			wantStatus: http.StatusBadRequest,
+			wantError:  "missing image field",
+		},
+		{
+			name:       "multipart/form-data の画像が上限を超える",
+			request:    multipartRequest(nil, append(imgBytes, make([]byte, maxRequestSize)...)),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  "image size exceeds limit",
+		},
+		{
+			name:        "バイナリの画像",
+			request:     rawRequest("image/jpeg", "?primaryPolicy=largest&locale=en", imgBytes),
+			wantStatus:  http.StatusOK,
+			wantOptions: analyzer.AnalyzeOptions{PrimaryPolicy: "largest", Locale: "en"},
+		},
+		{
+			name:       "画像でないバイナリ",
+			request:    rawRequest("image/png", "", []byte("not an image")),
+			wantStatus: http.StatusBadRequest,
+			wantError:  "invalid image data format",
+		},
+		{
+			name:       "上限を超えるバイナリの画像",
+			request:    rawRequest("image/jpeg", "", append(imgBytes, make([]byte, maxRequestSize)...)),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  "image size exceeds limit",
+		},
+		{
+			name: "Content-Lengthが上限を超えるバイナリの画像",
+			request: func() *http.Request {
+				req := rawRequest("image/jpeg", "", imgBytes)
+				req.ContentLength = maxRequestSize + 1
+				return req
+			}(),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  "image size exceeds limit",
+		},
+		{
+			name:       "非対応のContent-Type",
+			request:    rawRequest("text/plain", "", []byte("hello")),
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantError:  "invalid content type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received []byte
+			mockAnalyzer := &mockFaceAnalyzer{
+				analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+					received = imgData
+					return &analyzer.AnalysisResult{PrimaryEmotion: analyzer.EmotionUnknown, PrimaryIndex: -1}, nil
+				},
+			}
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyze(rec, tt.request)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.Contains(t, decodeProblem(t, rec).Detail, tt.wantError)
+				assert.Equal(t, 0, mockAnalyzer.getCallCount())
+				return
+			}
+			assert.Equal(t, imgBytes, received)
+			assert.Equal(t, tt.wantOptions, mockAnalyzer.getLastOptions())
+		})
+	}
+}
+
+func TestFaceHandler_HandleAnalyze_ImageURL(t *testing.T) {
+	mockRenderer, mockAnalyzer, cleanup := setupTest(t)
+	defer cleanup()
+
+	imgBytes := testImageBytes(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		_, _ = w.Write(imgBytes)
+	}))
+	defer server.Close()
+
+	newFetcher := func(allowedNetworks ...string) *fetch.Fetcher {
+		f, err := fetch.New(config.ImageURLConfig{
+			Enabled:         true,
+			AllowedSchemes:  []string{"http"},
+			AllowedHosts:    []string{"127.0.0.1"},
+			AllowedNetworks: allowedNetworks,
+			AllowedTypes:    []string{"image/jpeg"},
+			MaxSize:         maxRequestSize,
+			Timeout:         time.Second,
+		})
+		require.NoError(t, err)
+		return f
+	}
+
+	tests := []struct {
+		name       string
+		fetcher    *fetch.Fetcher
+		body       map[string]interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "URLの画像を分析",
+			fetcher:    newFetcher("127.0.0.0/8"),
+			body:       map[string]interface{}{"imageUrl": server.URL + "/face.jpg"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "無効な場合",
+			body:       map[string]interface{}{"imageUrl": server.URL + "/face.jpg"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.ErrCodeInvalidRequest,
+		},
+		{
+			name:       "画像と同時に指定",
+			fetcher:    newFetcher("127.0.0.0/8"),
+			body:       map[string]interface{}{"image": testImageDataURL(t), "imageUrl": server.URL + "/face.jpg"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.ErrCodeInvalidRequest,
+		},
+		{
+			name:       "ループバックアドレスへの接続",
+			fetcher:    newFetcher(),
+			body:       map[string]interface{}{"imageUrl": server.URL + "/face.jpg"},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.ErrCodeURLAddressBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewFaceHandler(mockRenderer, mockAnalyzer)
+			handler.SetImageFetcher(tt.fetcher)
+
+			rec := httptest.NewRecorder()
+			handler.HandleAnalyzeV1(rec, createTestRequest(t, http.MethodPost, "/api/v1/analyze", tt.body))
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, decodeProblem(t, rec).Code)
+			}
+		})
+	}
+}
+
+// 読み取りを記録するリーダー
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestReadLimited(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 1000)
+
+	tests := []struct {
+		name     string
+		limit    int64
+		sizeHint int64
+		wantErr  error
+		wantRead bool
+	}{
+		{name: "サイズ不明", limit: 1000, sizeHint: 0, wantRead: true},
+		{name: "サイズ指定", limit: 1000, sizeHint: 1000, wantRead: true},
+		{name: "読み取り後に上限を超える", limit: 999, sizeHint: 0, wantErr: errImageTooLarge, wantRead: true},
+		{name: "読み取る前に上限を超える", limit: 999, sizeHint: 1000, wantErr: errImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &countingReader{r: bytes.NewReader(data)}
+			got, err := readLimited(r, tt.limit, tt.sizeHint)
+			assert.Equal(t, tt.wantRead, r.read > 0)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+		})
+	}
+}
+
+func TestDecodeImageDataURL(t *testing.T) {
+	imgBytes := testImageBytes(t)
+
+	tests := []struct {
+		name      string
+		dataURL   string
+		want      []byte
+		wantError string
+	}{
+		{
+			name:    "正常な画像",
+			dataURL: "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(imgBytes),
+			want:    imgBytes,
+		},
+		{
+			name:      "JPEG以外のデータURL",
+			dataURL:   "data:image/png;base64,AAAA",
+			wantError: "invalid image data format",
+		},
+		{
+			name:      "不正なBase64",
+			dataURL:   "data:image/jpeg;base64,QUJD!",
+			wantError: "invalid image data",
+		},
+		{
+			name:      "途中で終わるBase64",
+			dataURL:   "data:image/jpeg;base64,QQ",
+			wantError: "invalid image data",
+		},
+		{
+			name:      "空の画像",
+			dataURL:   "data:image/jpeg;base64,",
+			wantError: "empty image data",
+		},
+		{
+			name:      "上限を超える画像",
+			dataURL:   "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(make([]byte, maxRequestSize+1)),
+			wantError: "image size exceeds limit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeImageDataURL(tt.dataURL, maxRequestSize)
+			if tt.wantError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/job"
+	"github.com/okamyuji/face-emotion-analyzer/internal/webhook"
+)
+
+// Webhookの登録に使用するAPIキーのヘッダー
+const APIKeyHeader = "X-API-Key"
+
+// Webhookの登録と配信に失敗したイベントを管理するハンドラー
+type WebhookHandler struct {
+	webhooks *webhook.Dispatcher
+	// 配信に失敗したイベントの参照に必要なトークン（空の場合は参照できない）
+	adminToken string
+}
+
+type WebhookRequest struct {
+	URL string `json:"url"`
+	// 通知するイベントの種類（省略時はすべてのイベント）
+	Events []string `json:"events,omitempty"`
+}
+
+// 登録した配信先
+// persisted が false の場合、登録はメモリ上にのみあり再起動すると消える
+type WebhookResponse struct {
+	webhook.Registration
+	Persisted bool `json:"persisted"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []webhook.Registration `json:"webhooks"`
+}
+
+type DeadLetterListResponse struct {
+	DeadLetters []webhook.DeadLetter `json:"deadLetters"`
+}
+
+func NewWebhookHandler(webhooks *webhook.Dispatcher, adminToken string) *WebhookHandler {
+	return &WebhookHandler{
+		webhooks:   webhooks,
+		adminToken: adminToken,
+	}
+}
+
+// APIキーに配信先を登録
+func (h *WebhookHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		errors.WriteProblem(w, r, invalidRequest("invalid request body"))
+		return
+	}
+
+	registration, err := h.webhooks.Register(r.Header.Get(APIKeyHeader), req.URL, req.Events)
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/webhooks/"+registration.ID)
+	writeJSON(w, http.StatusCreated, WebhookResponse{
+		Registration: registration,
+		Persisted:    h.webhooks.Persistent(),
+	})
+}
+
+// APIキーに登録された配信先の一覧を返す
+func (h *WebhookHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	registrations, err := h.webhooks.Registrations(r.Header.Get(APIKeyHeader))
+	if err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, WebhookListResponse{Webhooks: registrations})
+}
+
+// APIキーに登録された配信先を削除
+func (h *WebhookHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.webhooks.Unregister(r.Header.Get(APIKeyHeader), r.PathValue("id")); err != nil {
+		errors.WriteProblem(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// 最大回数まで再試行しても配信できなかったイベントを新しい順に返す
+// 管理用のトークンを Authorization: Bearer で指定する必要がある
+func (h *WebhookHandler) HandleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeUnauthorized, "invalid admin token", nil))
+		return
+	}
+	writeJSON(w, http.StatusOK, DeadLetterListResponse{DeadLetters: h.webhooks.DeadLetters()})
+}
+
+func (h *WebhookHandler) authorizeAdmin(r *http.Request) bool {
+	if h.adminToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("レスポンスの送信に失敗", "error", err)
+	}
+}
+
+// 分析の完了を APIキーに登録された配信先に通知する
+func (h *FaceHandler) notifyAnalysis(apiKey string, response AnalyzeResponseV1) {
+	if h.webhooks == nil {
+		return
+	}
+	h.webhooks.Notify(apiKey, webhook.EventAnalysisCompleted, webhookResult(&response))
+}
+
+// 通知に含める分析結果を返す
+// 通知のボディを小さく保つため、画像は含めない
+func webhookResult(r *AnalyzeResponseV1) *AnalyzeResponseV1 {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.ProcessedImage = ""
+	result.ProcessedImageData = nil
+	result.GrayscaleImage = ""
+	return &result
+}
+
+// ジョブの状態に対応するイベントの種類を返す
+func jobEventType(status job.Status) string {
+	switch status {
+	case job.StatusFailed:
+		return webhook.EventJobFailed
+	case job.StatusCancelled:
+		return webhook.EventJobCancelled
+	default:
+		return webhook.EventJobCompleted
+	}
+}
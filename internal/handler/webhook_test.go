@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/analyzer"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/gallery"
+	"github.com/okamyuji/face-emotion-analyzer/internal/session"
+	"github.com/okamyuji/face-emotion-analyzer/internal/stream"
+	"github.com/okamyuji/face-emotion-analyzer/internal/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIKey     = "test-api-key"
+	testAdminToken = "test-admin-token"
+)
+
+// テスト用のサーバーに配信できるようループバックを許可したディスパッチャー
+func newTestDispatcher(t *testing.T) *webhook.Dispatcher {
+	t.Helper()
+	d, err := webhook.New(config.WebhookConfig{
+		Enabled:          true,
+		Secret:           "test-secret",
+		APIKeys:          []string{testAPIKey},
+		AllowedSchemes:   []string{"http"},
+		AllowedNetworks:  []string{"127.0.0.0/8"},
+		Timeout:          time.Second,
+		MaxAttempts:      1,
+		InitialBackoff:   10 * time.Millisecond,
+		MaxBackoff:       10 * time.Millisecond,
+		Workers:          1,
+		QueueSize:        10,
+		DeadLetterSize:   10,
+		MaxRegistrations: 2,
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(d.Close)
+	return d
+}
+
+func newTestWebhookRouter(t *testing.T, face *FaceHandler, d *webhook.Dispatcher) http.Handler {
+	t.Helper()
+	face.SetWebhooks(d)
+	g, err := gallery.Open("")
+	require.NoError(t, err)
+	passThrough := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	return NewAPIv1Router(face, NewGalleryHandler(&mockFaceAnalyzer{}, g),
+		NewSessionHandler(session.NewStore(time.Minute, 0.5, 0)), newTestJobHandler(t, face),
+		NewStreamHandler(face, 0, 1), NewSessionEventsHandler(stream.NewHub(0, 1), 0),
+		NewWebhookHandler(d, testAdminToken), passThrough)
+}
+
+// 受信したイベントを記録するサーバー
+func newTestReceiver(t *testing.T) (*httptest.Server, <-chan webhook.Event) {
+	t.Helper()
+	received := make(chan webhook.Event, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event webhook.Event
+		if json.Unmarshal(body, &event) == nil && r.Header.Get(webhook.HeaderSignature) != "" {
+			received <- event
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func receiveEvent(t *testing.T, received <-chan webhook.Event) webhook.Event {
+	t.Helper()
+	select {
+	case event := <-received:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("イベントを受信できませんでした")
+		return webhook.Event{}
+	}
+}
+
+func newHappyFaceHandler(t *testing.T) *FaceHandler {
+	t.Helper()
+	mockRenderer, _, cleanup := setupTest(t)
+	t.Cleanup(cleanup)
+	return NewFaceHandler(mockRenderer, &mockFaceAnalyzer{
+		analyzeFunc: func(imgData []byte) (*analyzer.AnalysisResult, error) {
+			return &analyzer.AnalysisResult{
+				PrimaryEmotion: analyzer.EmotionHappy,
+				ImageWidth:     320,
+				ImageHeight:    240,
+			}, nil
+		},
+	})
+}
+
+func TestWebhookHandler(t *testing.T) {
+	router := newTestWebhookRouter(t, newHappyFaceHandler(t), newTestDispatcher(t))
+
+	request := func(method, path string, body interface{}, apiKey string) *httptest.ResponseRecorder {
+		req := createTestRequest(t, method, path, body)
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("登録と一覧と削除", func(t *testing.T) {
+		rec := request(http.MethodPost, "/api/v1/webhooks",
+			WebhookRequest{URL: "http://127.0.0.1:9/hook", Events: []string{webhook.EventJobCompleted}}, testAPIKey)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		var created WebhookResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+		assert.Equal(t, "/api/v1/webhooks/"+created.ID, rec.Header().Get("Location"))
+		// 保存先を設定していないため、登録はメモリ上にのみある
+		assert.False(t, created.Persisted)
+
+		rec = request(http.MethodGet, "/api/v1/webhooks", nil, testAPIKey)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var list WebhookListResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&list))
+		require.Len(t, list.Webhooks, 1)
+		assert.Equal(t, created.ID, list.Webhooks[0].ID)
+
+		rec = request(http.MethodDelete, "/api/v1/webhooks/"+created.ID, nil, testAPIKey)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = request(http.MethodDelete, "/api/v1/webhooks/"+created.ID, nil, testAPIKey)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	tests := []struct {
+		name       string
+		body       interface{}
+		apiKey     string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "APIキーなし",
+			body:       WebhookRequest{URL: "http://127.0.0.1:9/hook"},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   errors.ErrCodeUnauthorized,
+		},
+		{
+			name:       "未知のイベント",
+			body:       WebhookRequest{URL: "http://127.0.0.1:9/hook", Events: []string{"unknown"}},
+			apiKey:     testAPIKey,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.ErrCodeInvalidRequest,
+		},
+		{
+			name:       "許可されていないスキーム",
+			body:       WebhookRequest{URL: "ftp://127.0.0.1/hook"},
+			apiKey:     testAPIKey,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.ErrCodeURLSchemeNotAllowed,
+		},
+		{
+			name:       "不正なボディ",
+			body:       "invalid",
+			apiKey:     testAPIKey,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   errors.ErrCodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(http.MethodPost, "/api/v1/webhooks", tt.body, tt.apiKey)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantCode != "" {
+				assert.Equal(t, tt.wantCode, decodeProblem(t, rec).Code)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_DeadLetters(t *testing.T) {
+	d := newTestDispatcher(t)
+	router := newTestWebhookRouter(t, newHappyFaceHandler(t), d)
+	// プライベートアドレスへの配信は接続時に拒否され、再試行せずに記録される
+	d.Send("http://10.0.0.1/hook", webhook.EventJobCompleted, map[string]string{"id": "1"})
+	require.Eventually(t, func() bool { return len(d.DeadLetters()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "トークンなし", wantStatus: http.StatusUnauthorized},
+		{name: "不正なトークン", authorization: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+		{name: "正しいトークン", authorization: "Bearer " + testAdminToken, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := createTestRequest(t, http.MethodGet, "/api/v1/admin/webhooks/dead-letters", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantStatus != http.StatusOK {
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+				return
+			}
+
+			var resp DeadLetterListResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.Len(t, resp.DeadLetters, 1)
+			assert.Equal(t, "http://10.0.0.1/hook", resp.DeadLetters[0].URL)
+			assert.Equal(t, webhook.EventJobCompleted, resp.DeadLetters[0].EventType)
+		})
+	}
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	imageData := testImageDataURL(t)
+
+	t.Run("分析の完了を登録先に通知", func(t *testing.T) {
+		server, received := newTestReceiver(t)
+		d := newTestDispatcher(t)
+		router := newTestWebhookRouter(t, newHappyFaceHandler(t), d)
+		_, err := d.Register(testAPIKey, server.URL, []string{webhook.EventAnalysisCompleted})
+		require.NoError(t, err)
+
+		req := createTestRequest(t, http.MethodPost, "/api/v1/analyze", map[string]interface{}{"image": imageData})
+		req.Header.Set(APIKeyHeader, testAPIKey)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		event := receiveEvent(t, received)
+		assert.Equal(t, webhook.EventAnalysisCompleted, event.Type)
+		data, ok := event.Data.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "happy", data["emotion"])
+		assert.NotContains(t, data, "processedImage")
+	})
+
+	t.Run("ジョブの完了を callbackUrl に通知", func(t *testing.T) {
+		server, received := newTestReceiver(t)
+		router := newTestWebhookRouter(t, newHappyFaceHandler(t), newTestDispatcher(t))
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, createTestRequest(t, http.MethodPost, "/api/v1/jobs",
+			map[string]interface{}{"image": imageData, "callbackUrl": server.URL}))
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		var created JobResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&created))
+
+		event := receiveEvent(t, received)
+		assert.Equal(t, webhook.EventJobCompleted, event.Type)
+		data, ok := event.Data.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, created.ID, data["id"])
+		assert.Equal(t, "done", data["status"])
+	})
+}
+
+func TestWebhook_CallbackURL(t *testing.T) {
+	imageData := testImageDataURL(t)
+
+	tests := []struct {
+		name       string
+		webhooks   bool
+		path       string
+		callback   string
+		wantStatus int
+	}{
+		{
+			name:       "同期の分析",
+			webhooks:   true,
+			path:       "/api/v1/analyze",
+			callback:   "http://127.0.0.1:9/hook",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Webhookが無効",
+			path:       "/api/v1/jobs",
+			callback:   "http://127.0.0.1:9/hook",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "許可されていないスキーム",
+			webhooks:   true,
+			path:       "/api/v1/jobs",
+			callback:   "ftp://127.0.0.1/hook",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			face := newHappyFaceHandler(t)
+			router := newTestAPIv1Router(t, face)
+			if tt.webhooks {
+				router = newTestWebhookRouter(t, face, newTestDispatcher(t))
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, createTestRequest(t, http.MethodPost, tt.path,
+				map[string]interface{}{"image": imageData, "callbackUrl": tt.callback}))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
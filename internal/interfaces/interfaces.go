@@ -25,6 +25,7 @@ type TemplateRenderer interface {
 // 顔分析のインターフェース
 type FaceAnalyzer interface {
 	Analyze(imgData []byte) (*analyzer.AnalysisResult, error)
+	AnalyzeWithOptions(imgData []byte, opts analyzer.AnalyzeOptions) (*analyzer.AnalysisResult, error)
 	Close() error
 }
 
@@ -0,0 +1,255 @@
+package job
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/worker"
+)
+
+// 終了したジョブの結果を保持するデフォルトの期間
+const defaultTTL = time.Hour
+
+// 待機中と実行中のジョブ数のデフォルトの上限
+const defaultMaxPending = 100
+
+// ジョブの状態
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusDone      Status = "done"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// ジョブが終了しているかを返す
+func (s Status) Finished() bool {
+	return s == StatusDone || s == StatusFailed || s == StatusCancelled
+}
+
+// ジョブとして実行する処理
+// progress で進捗（0〜1）を報告する
+type RunFunc func(ctx context.Context, progress func(float64)) (interface{}, error)
+
+// ジョブの状態と結果
+type Job struct {
+	ID       string
+	Status   Status
+	Progress float64
+	// 完了した場合の処理の結果
+	Result interface{}
+	// 失敗した場合のエラー
+	Err       error
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// ジョブが終了した場合に呼び出す関数
+type NotifyFunc func(Job)
+
+type entry struct {
+	job    Job
+	cancel context.CancelFunc
+	notify NotifyFunc
+}
+
+// ワーカープールでジョブを実行し、状態を管理する
+// 終了したジョブは保持期間を過ぎると破棄する
+type Manager struct {
+	mu   sync.Mutex
+	pool *worker.Pool
+	jobs map[string]*entry
+	ttl  time.Duration
+	now  func() time.Time
+	// 待機中と実行中のジョブ数とその上限
+	pending    int
+	maxPending int
+}
+
+// ジョブの管理を作成
+// maxPending は待機中と実行中のジョブ数の上限で、0以下の場合はデフォルト値を使用する
+func NewManager(pool *worker.Pool, ttl time.Duration, maxPending int) *Manager {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if maxPending <= 0 {
+		maxPending = defaultMaxPending
+	}
+	return &Manager{
+		pool:       pool,
+		jobs:       make(map[string]*entry),
+		ttl:        ttl,
+		now:        time.Now,
+		maxPending: maxPending,
+	}
+}
+
+// ジョブをキューに追加
+func (m *Manager) Submit(run RunFunc) (Job, error) {
+	return m.SubmitNotify(run, nil)
+}
+
+// ジョブをキューに追加し、完了、失敗、キャンセルのいずれかで終了した場合に notify を呼び出す
+// 待機中と実行中のジョブ数が上限に達している場合は ErrJobQueueFull を返す
+func (m *Manager) SubmitNotify(run RunFunc, notify NotifyFunc) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	m.mu.Lock()
+	if m.pending >= m.maxPending {
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("%w: %d", errors.ErrJobQueueFull, m.maxPending)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	now := m.now()
+	m.expire(now)
+	e := &entry{
+		job: Job{
+			ID:        id,
+			Status:    StatusQueued,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		cancel: cancel,
+		notify: notify,
+	}
+	m.jobs[id] = e
+	m.pending++
+	job := e.job
+	m.mu.Unlock()
+
+	go func() {
+		// ctx はキャンセルした場合のみ終了し、キャンセルされたジョブはキューの空きや結果を待たずに終了する
+		result, err := m.pool.Submit(ctx, worker.Task{
+			Execute: func(ctx context.Context) (interface{}, error) {
+				return m.execute(ctx, id, run)
+			},
+		})
+		m.finish(ctx, id, result, err)
+	}()
+	return job, nil
+}
+
+// ジョブの状態を返す
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(m.now())
+	e, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return e.job, true
+}
+
+// ジョブをキャンセル
+// 実行中の処理は中断できないため、結果を破棄する
+func (m *Manager) Cancel(id string) (Job, error) {
+	m.mu.Lock()
+	now := m.now()
+	m.expire(now)
+	e, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return Job{}, fmt.Errorf("%w: %s", errors.ErrJobNotFound, id)
+	}
+	if e.job.Status.Finished() {
+		job := e.job
+		m.mu.Unlock()
+		return job, fmt.Errorf("%w: %s", errors.ErrJobFinished, id)
+	}
+	e.cancel()
+	e.job.Status = StatusCancelled
+	e.job.UpdatedAt = now
+	m.pending--
+	job := e.job
+	m.mu.Unlock()
+
+	if e.notify != nil {
+		e.notify(job)
+	}
+	return job, nil
+}
+
+// ワーカーでジョブを実行
+func (m *Manager) execute(ctx context.Context, id string, run RunFunc) (interface{}, error) {
+	m.update(id, func(j *Job) {
+		j.Status = StatusRunning
+	})
+	return run(ctx, func(progress float64) {
+		m.update(id, func(j *Job) {
+			j.Progress = progress
+		})
+	})
+}
+
+// 実行中または待機中のジョブの状態を更新
+func (m *Manager) update(id string, fn func(j *Job)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.jobs[id]
+	if !ok || e.job.Status.Finished() {
+		return
+	}
+	fn(&e.job)
+	e.job.UpdatedAt = m.now()
+}
+
+// ジョブの結果を記録
+// キャンセルされたジョブの結果は破棄する
+// ワーカープールのシャットダウンの期限までに終わらなかったジョブはキャンセルとして記録する
+func (m *Manager) finish(ctx context.Context, id string, result interface{}, err error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	if !ok || e.job.Status.Finished() {
+		m.mu.Unlock()
+		return
+	}
+	switch {
+	case ctx.Err() != nil, errors.Is(err, worker.ErrPoolShutdown):
+		e.job.Status = StatusCancelled
+	case err != nil:
+		e.job.Status = StatusFailed
+		e.job.Err = err
+	default:
+		e.job.Status = StatusDone
+		e.job.Progress = 1
+		e.job.Result = result
+	}
+	e.job.UpdatedAt = m.now()
+	m.pending--
+	job := e.job
+	m.mu.Unlock()
+
+	if e.notify != nil {
+		e.notify(job)
+	}
+}
+
+// 保持期間を過ぎた終了済みのジョブを破棄
+func (m *Manager) expire(now time.Time) {
+	for id, e := range m.jobs {
+		if e.job.Status.Finished() && now.Sub(e.job.UpdatedAt) > m.ttl {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("ジョブIDの生成に失敗: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,289 @@
+package job
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/worker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	pool := worker.NewPool(1, 1)
+	t.Cleanup(func() {
+		_ = pool.Shutdown(context.Background())
+	})
+	return NewManager(pool, time.Minute, 0)
+}
+
+// ジョブが終了するまで待機
+func waitFinished(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	var job Job
+	require.Eventually(t, func() bool {
+		var ok bool
+		job, ok = m.Get(id)
+		return ok && job.Status.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestManager_Submit(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        RunFunc
+		wantStatus Status
+		wantResult interface{}
+		wantErr    bool
+	}{
+		{
+			name: "完了",
+			run: func(ctx context.Context, progress func(float64)) (interface{}, error) {
+				progress(0.5)
+				return "result", nil
+			},
+			wantStatus: StatusDone,
+			wantResult: "result",
+		},
+		{
+			name: "失敗",
+			run: func(ctx context.Context, progress func(float64)) (interface{}, error) {
+				return nil, stderrors.New("failed")
+			},
+			wantStatus: StatusFailed,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager(t)
+			job, err := m.Submit(tt.run)
+			require.NoError(t, err)
+			assert.Equal(t, StatusQueued, job.Status)
+			assert.Len(t, job.ID, 32)
+
+			job = waitFinished(t, m, job.ID)
+			assert.Equal(t, tt.wantStatus, job.Status)
+			assert.Equal(t, tt.wantResult, job.Result)
+			if tt.wantErr {
+				assert.Error(t, job.Err)
+				return
+			}
+			assert.NoError(t, job.Err)
+			assert.Equal(t, 1.0, job.Progress)
+		})
+	}
+}
+
+func TestManager_Cancel(t *testing.T) {
+	m := newTestManager(t)
+
+	// ワーカーを占有して後続のジョブを待機させる
+	release := make(chan struct{})
+	started := make(chan struct{})
+	running, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		close(started)
+		<-release
+		return "running", nil
+	})
+	require.NoError(t, err)
+	<-started
+
+	ran := false
+	queued, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	// 待機中のジョブのキャンセル
+	job, err := m.Cancel(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, job.Status)
+
+	// 実行中のジョブのキャンセルは結果を破棄する
+	job, err = m.Cancel(running.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, job.Status)
+	close(release)
+
+	time.Sleep(50 * time.Millisecond)
+	job, ok := m.Get(running.ID)
+	require.True(t, ok)
+	assert.Equal(t, StatusCancelled, job.Status)
+	assert.Nil(t, job.Result)
+	assert.False(t, ran)
+
+	// 終了したジョブはキャンセルできない
+	_, err = m.Cancel(running.ID)
+	assert.ErrorIs(t, err, errors.ErrJobFinished)
+
+	_, err = m.Cancel("missing")
+	assert.ErrorIs(t, err, errors.ErrJobNotFound)
+}
+
+func TestManager_SubmitNotify(t *testing.T) {
+	m := newTestManager(t)
+	notified := make(chan Job, 2)
+	notify := func(j Job) { notified <- j }
+
+	done, err := m.SubmitNotify(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		return "result", nil
+	}, notify)
+	require.NoError(t, err)
+
+	select {
+	case j := <-notified:
+		assert.Equal(t, done.ID, j.ID)
+		assert.Equal(t, StatusDone, j.Status)
+		assert.Equal(t, "result", j.Result)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ジョブの終了が通知されませんでした")
+	}
+
+	// キャンセルした場合も1回だけ通知する
+	release := make(chan struct{})
+	started := make(chan struct{})
+	running, err := m.SubmitNotify(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		close(started)
+		<-release
+		return "running", nil
+	}, notify)
+	require.NoError(t, err)
+	<-started
+
+	_, err = m.Cancel(running.ID)
+	require.NoError(t, err)
+	close(release)
+
+	j := <-notified
+	assert.Equal(t, running.ID, j.ID)
+	assert.Equal(t, StatusCancelled, j.Status)
+	select {
+	case j := <-notified:
+		t.Fatalf("終了が重複して通知されました: %s", j.Status)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestManager_MaxPending(t *testing.T) {
+	pool := worker.NewPool(1, 1)
+	t.Cleanup(func() {
+		_ = pool.Shutdown(context.Background())
+	})
+	m := NewManager(pool, time.Minute, 2)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	running, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		close(started)
+		<-release
+		return "running", nil
+	})
+	require.NoError(t, err)
+	<-started
+
+	wait := func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		return nil, nil
+	}
+	queued, err := m.Submit(wait)
+	require.NoError(t, err)
+
+	// 待機中と実行中のジョブ数が上限に達している
+	_, err = m.Submit(wait)
+	assert.ErrorIs(t, err, errors.ErrJobQueueFull)
+
+	// キャンセルしたジョブは上限に数えない
+	_, err = m.Cancel(queued.ID)
+	require.NoError(t, err)
+	next, err := m.Submit(wait)
+	require.NoError(t, err)
+
+	// 終了したジョブは上限に数えない
+	close(release)
+	waitFinished(t, m, running.ID)
+	waitFinished(t, m, next.ID)
+	_, err = m.Submit(wait)
+	assert.NoError(t, err)
+}
+
+func TestManager_Expire(t *testing.T) {
+	m := newTestManager(t)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	job, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		return "result", nil
+	})
+	require.NoError(t, err)
+	waitFinished(t, m, job.ID)
+
+	// 保持期間内は結果を取得できる
+	now = now.Add(30 * time.Second)
+	_, ok := m.Get(job.ID)
+	assert.True(t, ok)
+
+	// 保持期間を過ぎると破棄する
+	now = now.Add(time.Minute)
+	_, ok = m.Get(job.ID)
+	assert.False(t, ok)
+}
+
+func TestManager_PoolShutdown(t *testing.T) {
+	pool := worker.NewPool(1, 1)
+	m := NewManager(pool, time.Minute, 0)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	running, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		close(started)
+		<-release
+		return "running", nil
+	})
+	require.NoError(t, err)
+	<-started
+	queued, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+		return "queued", nil
+	})
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+
+	// 期限までに終わらなかったジョブはキャンセルとして記録する
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+	close(release)
+
+	assert.Equal(t, StatusCancelled, waitFinished(t, m, running.ID).Status)
+	assert.Equal(t, StatusCancelled, waitFinished(t, m, queued.ID).Status)
+}
+
+func TestManager_PoolShutdownDrain(t *testing.T) {
+	pool := worker.NewPool(1, 1)
+	m := NewManager(pool, time.Minute, 0)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		job, err := m.Submit(func(ctx context.Context, progress func(float64)) (interface{}, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "result", nil
+		})
+		require.NoError(t, err)
+		ids = append(ids, job.ID)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// キューに残ったジョブも期限内に実行する
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, pool.Shutdown(ctx))
+	for _, id := range ids {
+		assert.Equal(t, StatusDone, waitFinished(t, m, id).Status)
+	}
+}
@@ -2,16 +2,21 @@ package metrics
 
 import (
 	"context"
+	"net/http"
 	"runtime"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 // アプリケーションメトリクスを収集
 type MetricsCollector struct {
+	registry *prometheus.Registry
+
 	// アプリケーションメトリクス
 	requestCounter  *prometheus.CounterVec
 	requestDuration *prometheus.HistogramVec
@@ -19,6 +24,7 @@ type MetricsCollector struct {
 	activeRequests  *prometheus.GaugeVec
 	analysisResults *prometheus.CounterVec
 	processingTime  *prometheus.HistogramVec
+	engagementScore prometheus.Histogram
 
 	// リソースメトリクス
 	memoryUsage     prometheus.Gauge
@@ -37,6 +43,25 @@ type MetricsCollector struct {
 	opencvErrors     *prometheus.CounterVec
 	gpuUtilization   prometheus.Gauge
 	gpuMemory        prometheus.Gauge
+	opencvMats       *prometheus.GaugeVec
+
+	// Webhookメトリクス
+	webhookDeliveries       *prometheus.CounterVec
+	webhookDeliveryDuration *prometheus.HistogramVec
+	webhookDeadLetters      prometheus.Gauge
+
+	// Matの使用状況の取得元（未設定の場合は収集しない）
+	matSourceMu sync.RWMutex
+	matSource   func() MatCounts
+}
+
+// OpenCVのMatの使用状況
+type MatCounts struct {
+	// プロセス全体で生存中のMat数
+	Live int
+	// リソースマネージャーが貸し出し中・プール中のMat数
+	InUse  int
+	Pooled int
 }
 
 // 新しいメトリクスコレクターを作成
@@ -46,6 +71,7 @@ func NewMetricsCollector() *MetricsCollector {
 	// カスタムレジストリを作成
 	registry := prometheus.NewRegistry()
 	factory := promauto.With(registry)
+	m.registry = registry
 
 	// リクエストメトリクス
 	m.requestCounter = factory.NewCounterVec(prometheus.CounterOpts{
@@ -81,6 +107,12 @@ func NewMetricsCollector() *MetricsCollector {
 		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5},
 	}, []string{"operation"})
 
+	m.engagementScore = factory.NewHistogram(prometheus.HistogramOpts{
+		Name:    "face_analyzer_engagement_score",
+		Help:    "顔ごとのエンゲージメントスコアの分布",
+		Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+	})
+
 	// リソースメトリクス
 	m.memoryUsage = factory.NewGauge(prometheus.GaugeOpts{
 		Name: "face_analyzer_memory_bytes",
@@ -144,12 +176,39 @@ func NewMetricsCollector() *MetricsCollector {
 		Help: "GPU使用メモリ量",
 	})
 
+	m.opencvMats = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "face_analyzer_opencv_mats",
+		Help: "OpenCVのMat数",
+	}, []string{"state"})
+
+	// Webhookメトリクス
+	m.webhookDeliveries = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "face_analyzer_webhook_deliveries_total",
+		Help: "Webhookの配信の試行回数",
+	}, []string{"event", "result"})
+
+	m.webhookDeliveryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "face_analyzer_webhook_delivery_duration_seconds",
+		Help:    "Webhookの配信時間の分布",
+		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"event"})
+
+	m.webhookDeadLetters = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "face_analyzer_webhook_dead_letters",
+		Help: "保持している配信に失敗したWebhookのイベント数",
+	})
+
 	// メトリクス収集を開始
 	go m.collect()
 
 	return m
 }
 
+// Prometheus形式でメトリクスを出力するハンドラーを返す
+func (m *MetricsCollector) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
 // 定期的にメトリクスを収集
 func (m *MetricsCollector) collect() {
 	ticker := time.NewTicker(15 * time.Second)
@@ -168,6 +227,13 @@ func (m *MetricsCollector) collectResourceMetrics() {
 	m.memoryUsage.Set(float64(stats.Alloc))
 	m.goroutineCount.Set(float64(runtime.NumGoroutine()))
 
+	m.matSourceMu.RLock()
+	source := m.matSource
+	m.matSourceMu.RUnlock()
+	if source != nil {
+		m.UpdateMatCounts(source())
+	}
+
 	// CPU使用率の収集は別途実装が必要
 	// GPUメトリクスの収集も別途実装が必要
 }
@@ -205,6 +271,11 @@ func getConfidenceRange(confidence float64) string {
 	}
 }
 
+// 顔ごとのエンゲージメントスコアを記録
+func (m *MetricsCollector) RecordEngagement(score float64) {
+	m.engagementScore.Observe(score)
+}
+
 // 処理時間を記録
 func (m *MetricsCollector) RecordProcessingTime(operation string, duration time.Duration) {
 	m.processingTime.WithLabelValues(operation).Observe(duration.Seconds())
@@ -241,6 +312,32 @@ func (m *MetricsCollector) UpdateGPUStats(utilization float64, memory int64) {
 	m.gpuMemory.Set(float64(memory))
 }
 
+// Matの使用状況の取得元を設定
+// 設定した関数はリソースメトリクスの収集時に呼び出される
+func (m *MetricsCollector) SetMatSource(source func() MatCounts) {
+	m.matSourceMu.Lock()
+	defer m.matSourceMu.Unlock()
+	m.matSource = source
+}
+
+// Matの使用状況を更新
+func (m *MetricsCollector) UpdateMatCounts(counts MatCounts) {
+	m.opencvMats.WithLabelValues("live").Set(float64(counts.Live))
+	m.opencvMats.WithLabelValues("in_use").Set(float64(counts.InUse))
+	m.opencvMats.WithLabelValues("pooled").Set(float64(counts.Pooled))
+}
+
+// Webhookの配信の試行を記録
+func (m *MetricsCollector) RecordWebhookDelivery(eventType, result string, duration time.Duration) {
+	m.webhookDeliveries.WithLabelValues(eventType, result).Inc()
+	m.webhookDeliveryDuration.WithLabelValues(eventType).Observe(duration.Seconds())
+}
+
+// 保持している配信に失敗したWebhookのイベント数を更新
+func (m *MetricsCollector) SetWebhookDeadLetters(count int) {
+	m.webhookDeadLetters.Set(float64(count))
+}
+
 // コネクション数を更新
 func (m *MetricsCollector) UpdateConnectionCount(count int) {
 	m.openConnections.Set(float64(count))
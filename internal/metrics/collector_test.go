@@ -8,6 +8,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // テスト用のメトリクスコレクター作成関数
@@ -48,6 +49,12 @@ func newTestMetricsCollector() *MetricsCollector {
 		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5},
 	}, []string{"operation"})
 
+	m.engagementScore = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "face_analyzer_engagement_score",
+		Help:    "顔ごとのエンゲージメントスコアの分布",
+		Buckets: prometheus.LinearBuckets(0.1, 0.1, 10),
+	})
+
 	// リソースメトリクス
 	m.memoryUsage = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "face_analyzer_memory_bytes",
@@ -111,6 +118,28 @@ func newTestMetricsCollector() *MetricsCollector {
 		Help: "GPU使用メモリ量",
 	})
 
+	m.opencvMats = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "face_analyzer_opencv_mats",
+		Help: "OpenCVのMat数",
+	}, []string{"state"})
+
+	// Webhookメトリクス
+	m.webhookDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "face_analyzer_webhook_deliveries_total",
+		Help: "Webhookの配信の試行回数",
+	}, []string{"event", "result"})
+
+	m.webhookDeliveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "face_analyzer_webhook_delivery_duration_seconds",
+		Help:    "Webhookの配信時間の分布",
+		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"event"})
+
+	m.webhookDeadLetters = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "face_analyzer_webhook_dead_letters",
+		Help: "保持している配信に失敗したWebhookのイベント数",
+	})
+
 	return m
 }
 
@@ -365,3 +394,86 @@ func BenchmarkMetricsCollector(b *testing.B) {
 		}
 	})
 }
+
+func TestMetricsCollector_RecordEngagement(t *testing.T) {
+	collector := newTestMetricsCollector()
+
+	collector.RecordEngagement(0.25)
+	collector.RecordEngagement(0.85)
+
+	var metric dto.Metric
+	if err := collector.engagementScore.Write(&metric); err != nil {
+		t.Fatalf("ヒストグラムの取得に失敗: %v", err)
+	}
+	if got := metric.GetHistogram().GetSampleCount(); got != 2 {
+		t.Errorf("サンプル数が不正: got %v, want 2", got)
+	}
+	if got := metric.GetHistogram().GetSampleSum(); got < 1.09 || got > 1.11 {
+		t.Errorf("スコアの合計が不正: got %v, want 1.1", got)
+	}
+}
+
+func TestMetricsCollector_MatCounts(t *testing.T) {
+	collector := newTestMetricsCollector()
+
+	// 取得元が未設定の場合は収集しない
+	collector.collectResourceMetrics()
+	if count := testutil.CollectAndCount(collector.opencvMats); count != 0 {
+		t.Errorf("Mat数が記録されています: %d", count)
+	}
+
+	collector.SetMatSource(func() MatCounts {
+		return MatCounts{Live: 12, InUse: 3, Pooled: 2}
+	})
+	collector.collectResourceMetrics()
+
+	tests := []struct {
+		state    string
+		expected float64
+	}{
+		{"live", 12},
+		{"in_use", 3},
+		{"pooled", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			value := testutil.ToFloat64(collector.opencvMats.WithLabelValues(tt.state))
+			if value != tt.expected {
+				t.Errorf("Mat数が一致しません: got %v, want %v", value, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMetricsCollector_Webhook(t *testing.T) {
+	collector := newTestMetricsCollector()
+
+	collector.RecordWebhookDelivery("job.completed", "retry", 100*time.Millisecond)
+	collector.RecordWebhookDelivery("job.completed", "success", 50*time.Millisecond)
+	collector.RecordWebhookDelivery("job.completed", "success", 50*time.Millisecond)
+	collector.SetWebhookDeadLetters(3)
+
+	tests := []struct {
+		result   string
+		expected float64
+	}{
+		{"retry", 1},
+		{"success", 2},
+		{"failed", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.result, func(t *testing.T) {
+			value := testutil.ToFloat64(collector.webhookDeliveries.WithLabelValues("job.completed", tt.result))
+			if value != tt.expected {
+				t.Errorf("配信回数が一致しません: got %v, want %v", value, tt.expected)
+			}
+		})
+	}
+
+	if count := testutil.CollectAndCount(collector.webhookDeliveryDuration); count != 1 {
+		t.Errorf("配信時間のメトリクス数が不正: got %d, want 1", count)
+	}
+	if value := testutil.ToFloat64(collector.webhookDeadLetters); value != 3 {
+		t.Errorf("配信に失敗したイベント数が不正: got %v, want 3", value)
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// gRPCのメタデータのリクエストIDのキー
+var grpcRequestIDKey = strings.ToLower(errors.RequestIDHeader)
+
+// gRPCのリクエストのメトリクスを記録するインターフェース
+type RequestObserver interface {
+	ObserveRequest(ctx context.Context, method, path string, duration time.Duration, status int)
+}
+
+// gRPCの単項呼び出しにHTTPと同じレート制限、リクエストID、ログを適用する
+// CSRFトークンはブラウザからのリクエストのみを対象とするため検証しない
+func (sm *SecurityMiddleware) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := sm.checkGRPC(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// gRPCのストリームにHTTPと同じレート制限、リクエストID、ログを適用する
+// レート制限はストリームの開始時のみ適用する
+func (sm *SecurityMiddleware) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := sm.checkGRPC(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// リクエストIDを設定し、レート制限を検証する
+func (sm *SecurityMiddleware) checkGRPC(ctx context.Context, method string) (context.Context, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(grpcRequestIDKey); len(values) > 0 {
+			header = values[0]
+		}
+	}
+	requestID := requestIDFromHeader(header)
+	ctx = context.WithValue(ctx, RequestIDKey, requestID)
+	if err := grpc.SetHeader(ctx, metadata.Pairs(grpcRequestIDKey, requestID)); err != nil {
+		slog.Debug("リクエストIDのヘッダーの設定に失敗", "error", err)
+	}
+
+	if !sm.limiter.Allow() {
+		return ctx, status.Error(codes.ResourceExhausted, "リクエスト制限を超えました")
+	}
+
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	slog.Info("受信リクエスト",
+		"method", "GRPC",
+		"path", method,
+		"request_id", requestID,
+		"remote_addr", remoteAddr,
+		"timestamp", time.Now().Format(time.RFC3339),
+	)
+	return ctx, nil
+}
+
+// gRPCの単項呼び出しの処理時間と結果を記録する
+func UnaryMetricsInterceptor(observer RequestObserver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observer.ObserveRequest(ctx, "GRPC", info.FullMethod, time.Since(start), httpStatusFromGRPC(err))
+		return resp, err
+	}
+}
+
+// gRPCのストリームの処理時間と結果を記録する
+func StreamMetricsInterceptor(observer RequestObserver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observer.ObserveRequest(ss.Context(), "GRPC", info.FullMethod, time.Since(start), httpStatusFromGRPC(err))
+		return err
+	}
+}
+
+// HTTPのメトリクスと同じラベルで集計できるよう、gRPCのステータスをHTTPのステータスに対応付ける
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusUnprocessableEntity
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// コンテキストを差し替えたストリーム
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type mockRequestObserver struct {
+	path   string
+	status int
+}
+
+func (m *mockRequestObserver) ObserveRequest(ctx context.Context, method, path string, duration time.Duration, status int) {
+	m.path = path
+	m.status = status
+}
+
+func TestSecurityMiddleware_UnaryServerInterceptor(t *testing.T) {
+	sm := NewSecurityMiddleware(&config.SecurityConfig{
+		RateLimit: config.RateLimitConfig{RequestsPerMinute: 1, Burst: 1},
+	})
+	interceptor := sm.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/faceemotion.v1.AnalyzerService/Analyze"}
+
+	var requestID string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		requestID = RequestID(ctx)
+		return "ok", nil
+	}
+
+	// クライアントが指定したリクエストIDを使用する
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "req-1"))
+	resp, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	assert.Equal(t, "req-1", requestID)
+
+	// HTTPと同じレート制限を適用する
+	_, err = interceptor(context.Background(), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestUnaryMetricsInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"成功", nil, http.StatusOK},
+		{"不正な入力", status.Error(codes.InvalidArgument, "invalid"), http.StatusBadRequest},
+		{"レート制限", status.Error(codes.ResourceExhausted, "limited"), http.StatusTooManyRequests},
+		{"ステータスのないエラー", context.Canceled, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			observer := &mockRequestObserver{}
+			interceptor := UnaryMetricsInterceptor(observer)
+			info := &grpc.UnaryServerInfo{FullMethod: "/faceemotion.v1.AnalyzerService/Analyze"}
+			_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, tt.err
+			})
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, info.FullMethod, observer.path)
+			assert.Equal(t, tt.wantStatus, observer.status)
+		})
+	}
+}
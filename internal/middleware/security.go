@@ -6,18 +6,22 @@ import (
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	"golang.org/x/time/rate"
 
 	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
 )
 
 // セキュリティ設定
@@ -25,14 +29,35 @@ const (
 	maxUploadSize     = 10 * 1024 * 1024 // 最大10MB
 	maxImageDimension = 4096             // 最大画像サイズ
 	nonceLength       = 32               // CSPノンスの長さ
+	requestIDLength   = 16               // 生成するリクエストIDのバイト数
 )
 
+// CORSで許可するメソッドとヘッダー
+// Webhookの削除（DELETE）とAPIキー（X-API-Key）を含める
+const (
+	corsAllowMethods = "GET, POST, DELETE, OPTIONS"
+	corsAllowHeaders = "Content-Type, X-CSRF-Token, Authorization, X-API-Key"
+)
+
+// クライアントが指定したリクエストIDとして受け付ける形式
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// バイナリでアップロードできる画像の形式
+var allowedUploadImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+	"image/bmp":  true,
+}
+
 // コンテキストキーのカスタム型
 type contextKey string
 
 const (
 	// CSPノンスのコンテキストキー
 	CSPNonceKey contextKey = "csp-nonce"
+	// リクエストIDのコンテキストキー
+	RequestIDKey contextKey = "request-id"
 )
 
 // セキュリティミドルウェア
@@ -75,16 +100,21 @@ func (sm *SecurityMiddleware) Middleware(next http.HandlerFunc) http.HandlerFunc
 		r = r.WithContext(context.WithValue(r.Context(), CSPNonceKey, nonce))
 		sm.setSecurityHeaders(w, nonce)
 
+		// リクエストIDをレスポンスヘッダーとコンテキストに設定
+		requestID := requestIDFromHeader(r.Header.Get(errors.RequestIDHeader))
+		w.Header().Set(errors.RequestIDHeader, requestID)
+		r = r.WithContext(context.WithValue(r.Context(), RequestIDKey, requestID))
+
 		// 2. レート制限
 		if !sm.limiter.Allow() {
-			http.Error(w, "リクエスト制限を超えました", http.StatusTooManyRequests)
+			errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeRateLimitExceeded, "リクエスト制限を超えました", nil))
 			return
 		}
 
 		// 3. CORS設定
 		if err := sm.handleCORS(w, r); err != nil {
 			if r.Method != http.MethodOptions {
-				http.Error(w, err.Error(), http.StatusForbidden)
+				errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeForbidden, err.Error(), err))
 				return
 			}
 			return
@@ -92,14 +122,14 @@ func (sm *SecurityMiddleware) Middleware(next http.HandlerFunc) http.HandlerFunc
 
 		// 4. CSRFトークン検証
 		if err := sm.validateCSRFToken(r); err != nil {
-			http.Error(w, "無効なCSRFトークン", http.StatusForbidden)
+			errors.WriteProblem(w, r, errors.CodeError(errors.ErrCodeCSRFError, "無効なCSRFトークン", err))
 			return
 		}
 
 		// 5. アップロード制限の検証
 		if r.Method == http.MethodPost && strings.Contains(r.URL.Path, "/analyze") {
 			if err := sm.validateUpload(r); err != nil {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				errors.WriteProblem(w, r, err)
 				return
 			}
 		}
@@ -173,9 +203,8 @@ func (sm *SecurityMiddleware) handleCORS(w http.ResponseWriter, r *http.Request)
 	if origin == fmt.Sprintf("http://%s", r.Host) ||
 		origin == fmt.Sprintf("https://%s", r.Host) {
 		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers",
-			"Content-Type, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+		w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
 		w.Header().Set("Access-Control-Max-Age", "86400")
 		return nil
 	}
@@ -190,9 +219,8 @@ func (sm *SecurityMiddleware) handleCORS(w http.ResponseWriter, r *http.Request)
 	for _, allowed := range allowedOrigins {
 		if origin == allowed {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers",
-				"Content-Type, X-CSRF-Token, Authorization")
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowHeaders)
 			w.Header().Set("Access-Control-Max-Age", "86400")
 			return nil
 		}
@@ -225,6 +253,24 @@ func (sm *SecurityMiddleware) validateCSRFToken(r *http.Request) error {
 	return nil
 }
 
+// クライアントが指定したリクエストIDを検証し、不正な場合は新しく生成する
+func requestIDFromHeader(header string) string {
+	if validRequestID.MatchString(header) {
+		return header
+	}
+	b := make([]byte, requestIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// コンテキストからリクエストIDを取得
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
 // CSRFトークンを生成
 func generateToken() string {
 	b := make([]byte, 32)
@@ -235,19 +281,33 @@ func generateToken() string {
 }
 
 // アップロード制限の検証
+// JSONの場合は画像のデータURIを検証し、multipart/form-data と画像のバイナリはバッファリングせずにハンドラーで読み取る
 func (sm *SecurityMiddleware) validateUpload(r *http.Request) error {
 	// Content-Typeの確認
-	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
-		return fmt.Errorf("不正なContent-Type")
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return errors.CodeError(errors.ErrCodeInvalidRequest, "不正なContent-Type", err)
 	}
 
 	// リクエストボディのサイズ制限
 	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadSize)
 
+	switch {
+	case mediaType == "application/json":
+		return validateJSONUpload(r)
+	case mediaType == "multipart/form-data", allowedUploadImageTypes[mediaType]:
+		return nil
+	default:
+		return errors.CodeError(errors.ErrCodeUnsupportedMediaType, "不正なContent-Type", nil)
+	}
+}
+
+// JSONのアップロードの検証
+func validateJSONUpload(r *http.Request) error {
 	// リクエストボディを読み取り、保持する
 	bodyBytes, err := io.ReadAll(r.Body)
 	if err != nil {
-		return fmt.Errorf("リクエストボディの読み取りに失敗: %v", err)
+		return errors.CodeError(errors.ErrCodeRequestTooLarge, "リクエストボディの読み取りに失敗", err)
 	}
 
 	// 元のボディを復元
@@ -255,16 +315,26 @@ func (sm *SecurityMiddleware) validateUpload(r *http.Request) error {
 
 	// JSONデコード
 	var body struct {
-		Image string `json:"image"`
+		Image    string `json:"image"`
+		ImageURL string `json:"imageUrl"`
 	}
 
 	if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&body); err != nil {
-		return fmt.Errorf("不正なJSONフォーマット: %v", err)
+		return errors.CodeError(errors.ErrCodeInvalidRequest, "不正なJSONフォーマット", err)
+	}
+
+	// image と imageUrl のどちらか一方のみ受け付ける
+	// URLの検証と画像の取得はハンドラーで行う
+	if body.ImageURL != "" {
+		if body.Image != "" {
+			return errors.CodeError(errors.ErrCodeInvalidRequest, "image と imageUrl は同時に指定できません", nil)
+		}
+		return nil
 	}
 
 	// Base64画像の検証
 	if !strings.HasPrefix(body.Image, "data:image/jpeg;base64,") {
-		return fmt.Errorf("不正な画像フォーマット")
+		return errors.CodeError(errors.ErrCodeInvalidImage, "不正な画像フォーマット", nil)
 	}
 
 	return nil
@@ -275,6 +345,7 @@ func (sm *SecurityMiddleware) logRequest(r *http.Request) {
 	slog.Info("受信リクエスト",
 		"method", r.Method,
 		"path", r.URL.Path,
+		"request_id", RequestID(r.Context()),
 		"remote_addr", r.RemoteAddr,
 		"user_agent", r.UserAgent(),
 		"timestamp", time.Now().Format(time.RFC3339),
@@ -2,17 +2,22 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 
 	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSecurityMiddleware_Middleware(t *testing.T) {
@@ -76,7 +81,41 @@ func TestSecurityMiddleware_Middleware(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedHeaders: map[string]string{
 				"Access-Control-Allow-Origin":  "http://localhost:8080",
-				"Access-Control-Allow-Methods": "GET, POST, OPTIONS",
+				"Access-Control-Allow-Methods": "GET, POST, DELETE, OPTIONS",
+			},
+			numRequests: 1,
+		},
+		{
+			name:   "Webhook削除のCORSプリフライトリクエスト",
+			method: http.MethodOptions,
+			path:   "/api/v1/webhooks/1",
+			headers: map[string]string{
+				"Origin":                         "http://localhost:8080",
+				"Access-Control-Request-Method":  "DELETE",
+				"Access-Control-Request-Headers": "X-API-Key",
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "http://localhost:8080",
+				"Access-Control-Allow-Methods": "GET, POST, DELETE, OPTIONS",
+				"Access-Control-Allow-Headers": "Content-Type, X-CSRF-Token, Authorization, X-API-Key",
+			},
+			numRequests: 1,
+		},
+		{
+			name:   "同一オリジンのCORSプリフライトリクエスト",
+			method: http.MethodOptions,
+			path:   "/api/v1/webhooks/1",
+			headers: map[string]string{
+				"Origin":                         "http://example.com",
+				"Access-Control-Request-Method":  "DELETE",
+				"Access-Control-Request-Headers": "X-API-Key",
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "http://example.com",
+				"Access-Control-Allow-Methods": "GET, POST, DELETE, OPTIONS",
+				"Access-Control-Allow-Headers": "Content-Type, X-CSRF-Token, Authorization, X-API-Key",
 			},
 			numRequests: 1,
 		},
@@ -200,6 +239,178 @@ func TestSecurityMiddleware_ValidateCSRFToken(t *testing.T) {
 	}
 }
 
+func TestSecurityMiddleware_ValidateUpload(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantCode    string
+	}{
+		{
+			name:        "JSONの画像",
+			contentType: "application/json",
+			body:        `{"image":"data:image/jpeg;base64,AAAA"}`,
+		},
+		{
+			name:        "JSONの不正な画像フォーマット",
+			contentType: "application/json",
+			body:        `{"image":"data:image/gif;base64,AAAA"}`,
+			wantCode:    errors.ErrCodeInvalidImage,
+		},
+		{
+			name:        "JSONの画像のURL",
+			contentType: "application/json",
+			body:        `{"imageUrl":"https://images.example.com/face.jpg"}`,
+		},
+		{
+			name:        "JSONの画像と画像のURL",
+			contentType: "application/json",
+			body:        `{"image":"data:image/jpeg;base64,AAAA","imageUrl":"https://images.example.com/face.jpg"}`,
+			wantCode:    errors.ErrCodeInvalidRequest,
+		},
+		{
+			name:        "JSONの画像なし",
+			contentType: "application/json",
+			body:        `{}`,
+			wantCode:    errors.ErrCodeInvalidImage,
+		},
+		{
+			name:        "multipart/form-data",
+			contentType: "multipart/form-data; boundary=xyz",
+			body:        "--xyz--",
+		},
+		{
+			name:        "画像のバイナリ",
+			contentType: "image/png",
+			body:        "\x89PNG",
+		},
+		{
+			name:        "非対応のContent-Type",
+			contentType: "text/plain",
+			body:        "hello",
+			wantCode:    errors.ErrCodeUnsupportedMediaType,
+		},
+	}
+
+	sm := NewSecurityMiddleware(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			err := sm.validateUpload(req)
+			if tt.wantCode != "" {
+				var e *errors.Error
+				require.True(t, errors.As(err, &e))
+				assert.Equal(t, tt.wantCode, e.Code)
+				return
+			}
+			assert.NoError(t, err)
+
+			// ハンドラーがボディを読み取れる
+			body, err := io.ReadAll(req.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.body, string(body))
+		})
+	}
+}
+
+// imageUrl のみを指定したリクエストがミドルウェアを通過してハンドラーに届く
+func TestSecurityMiddleware_ImageURL(t *testing.T) {
+	const body = `{"imageUrl":"https://images.example.com/face.jpg"}`
+
+	for _, path := range []string{"/analyze", "/api/v1/analyze"} {
+		t.Run(path, func(t *testing.T) {
+			called := false
+			handler := NewSecurityMiddleware(nil).Middleware(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				b, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, body, string(b))
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-CSRF-Token", "token")
+			req.Header.Set("X-Expected-CSRF-Token", "token")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.True(t, called)
+		})
+	}
+}
+
+func TestSecurityMiddleware_Problem(t *testing.T) {
+	sm := NewSecurityMiddleware(nil)
+	handler := sm.Middleware(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, RequestID(r.Context()))
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+		csrf          bool
+		wantStatus    int
+	}{
+		{
+			name:          "指定されたリクエストIDを使用",
+			requestID:     "client-req_1",
+			wantRequestID: "client-req_1",
+			csrf:          true,
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "不正なリクエストIDは生成し直す",
+			requestID:  "bad id\n",
+			csrf:       true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "CSRFトークンがない",
+			requestID:     "req-2",
+			wantRequestID: "req-2",
+			wantStatus:    http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sessions", nil)
+			req.Header.Set(errors.RequestIDHeader, tt.requestID)
+			if tt.csrf {
+				req.Header.Set("X-CSRF-Token", "token")
+				req.Header.Set("X-Expected-CSRF-Token", "token")
+			}
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			requestID := rec.Header().Get(errors.RequestIDHeader)
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, requestID)
+			} else {
+				assert.Len(t, requestID, 2*requestIDLength)
+			}
+			if tt.wantStatus == http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, errors.ProblemContentType, rec.Header().Get("Content-Type"))
+			var p errors.Problem
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&p))
+			assert.Equal(t, errors.ErrCodeCSRFError, p.Code)
+			assert.Equal(t, tt.wantStatus, p.Status)
+			assert.Equal(t, tt.wantRequestID, p.RequestID)
+		})
+	}
+}
+
 func TestSecurityMiddleware_SecurityHeaders(t *testing.T) {
 	cfg := &config.SecurityConfig{
 		Headers: map[string]string{
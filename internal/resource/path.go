@@ -38,6 +38,10 @@ func init() {
 }
 
 // 与えられたパスをベースディレクトリからの相対パスに解決します
+// 絶対パスはそのまま返します
 func ResolvePath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
 	return filepath.Join(BaseDir, path)
 }
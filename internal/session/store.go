@@ -0,0 +1,178 @@
+package session
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// セッションの保持期間と保持数のデフォルト値
+const (
+	defaultTTL         = 30 * time.Minute
+	defaultMaxSessions = 10000
+)
+
+// 1フレーム分の分析結果
+type Frame struct {
+	// 各顔のエンゲージメントスコア
+	Engagement []float64
+	// 主要な顔の感情（顔がない場合は空）
+	Emotion string
+}
+
+// セッション全体の集計結果
+type Aggregate struct {
+	SessionID         string         `json:"sessionId"`
+	Frames            int            `json:"frames"`
+	FramesWithFaces   int            `json:"framesWithFaces"`
+	FacesObserved     int            `json:"facesObserved"`
+	MeanEngagement    float64        `json:"meanEngagement"`
+	MinEngagement     float64        `json:"minEngagement"`
+	MaxEngagement     float64        `json:"maxEngagement"`
+	EngagedFrameRatio float64        `json:"engagedFrameRatio"`
+	EmotionCounts     map[string]int `json:"emotionCounts"`
+	StartedAt         time.Time      `json:"startedAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+}
+
+// セッションごとに分析結果を集計するストア
+// 一定期間更新のないセッションは破棄する
+type Store struct {
+	mu                  sync.Mutex
+	sessions            map[string]*Aggregate
+	engagementSums      map[string]float64
+	engagedFrames       map[string]int
+	ttl                 time.Duration
+	engagementThreshold float64
+	maxSessions         int
+	now                 func() time.Time
+}
+
+// セッションストアを作成
+// engagementThreshold はフレームの平均スコアがこの値以上のときにエンゲージしているとみなす閾値
+// セッション数が maxSessions（0以下の場合はデフォルト値）に達した場合は最も長く更新のないセッションを破棄する
+func NewStore(ttl time.Duration, engagementThreshold float64, maxSessions int) *Store {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if maxSessions <= 0 {
+		maxSessions = defaultMaxSessions
+	}
+	return &Store{
+		sessions:            make(map[string]*Aggregate),
+		engagementSums:      make(map[string]float64),
+		engagedFrames:       make(map[string]int),
+		ttl:                 ttl,
+		engagementThreshold: engagementThreshold,
+		maxSessions:         maxSessions,
+		now:                 time.Now,
+	}
+}
+
+// フレームの分析結果をセッションに記録し、更新後の集計結果を返す
+func (s *Store) Record(sessionID string, frame Frame) Aggregate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.expire(now)
+
+	agg, ok := s.sessions[sessionID]
+	if !ok {
+		if len(s.sessions) >= s.maxSessions {
+			s.evictOldest()
+		}
+		agg = &Aggregate{
+			SessionID:     sessionID,
+			MinEngagement: math.Inf(1),
+			EmotionCounts: make(map[string]int),
+			StartedAt:     now,
+		}
+		s.sessions[sessionID] = agg
+	}
+
+	agg.Frames++
+	agg.UpdatedAt = now
+	if len(frame.Engagement) > 0 {
+		agg.FramesWithFaces++
+		var frameSum float64
+		for _, score := range frame.Engagement {
+			agg.FacesObserved++
+			frameSum += score
+			s.engagementSums[sessionID] += score
+			agg.MinEngagement = math.Min(agg.MinEngagement, score)
+			agg.MaxEngagement = math.Max(agg.MaxEngagement, score)
+		}
+		if frameSum/float64(len(frame.Engagement)) >= s.engagementThreshold {
+			s.engagedFrames[sessionID]++
+		}
+		agg.MeanEngagement = s.engagementSums[sessionID] / float64(agg.FacesObserved)
+		agg.EngagedFrameRatio = float64(s.engagedFrames[sessionID]) / float64(agg.FramesWithFaces)
+	}
+	if frame.Emotion != "" {
+		agg.EmotionCounts[frame.Emotion]++
+	}
+
+	return snapshot(agg)
+}
+
+// セッションの集計結果を返す
+func (s *Store) Get(sessionID string) (Aggregate, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.expire(s.now())
+	agg, ok := s.sessions[sessionID]
+	if !ok {
+		return Aggregate{}, false
+	}
+	return snapshot(agg), true
+}
+
+// セッションを破棄
+func (s *Store) Delete(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sessionID)
+}
+
+// 最も長く更新のないセッションを破棄
+func (s *Store) evictOldest() {
+	var oldest *Aggregate
+	for _, agg := range s.sessions {
+		if oldest == nil || agg.UpdatedAt.Before(oldest.UpdatedAt) {
+			oldest = agg
+		}
+	}
+	if oldest != nil {
+		s.remove(oldest.SessionID)
+	}
+}
+
+// 保持期間を過ぎたセッションを破棄
+func (s *Store) expire(now time.Time) {
+	for id, agg := range s.sessions {
+		if now.Sub(agg.UpdatedAt) > s.ttl {
+			s.remove(id)
+		}
+	}
+}
+
+func (s *Store) remove(sessionID string) {
+	delete(s.sessions, sessionID)
+	delete(s.engagementSums, sessionID)
+	delete(s.engagedFrames, sessionID)
+}
+
+// 呼び出し元が変更しても影響しないよう集計結果を複製する
+func snapshot(agg *Aggregate) Aggregate {
+	c := *agg
+	if agg.FacesObserved == 0 {
+		c.MinEngagement = 0
+	}
+	c.EmotionCounts = make(map[string]int, len(agg.EmotionCounts))
+	for k, v := range agg.EmotionCounts {
+		c.EmotionCounts[k] = v
+	}
+	return c
+}
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Record(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := NewStore(time.Minute, 0.5, 0)
+	store.now = func() time.Time { return now }
+
+	store.Record("s1", Frame{Engagement: []float64{0.8, 0.6}, Emotion: "happy"})
+	store.Record("s1", Frame{})
+	now = now.Add(10 * time.Second)
+	agg := store.Record("s1", Frame{Engagement: []float64{0.1}, Emotion: "neutral"})
+
+	assert.Equal(t, "s1", agg.SessionID)
+	assert.Equal(t, 3, agg.Frames)
+	assert.Equal(t, 2, agg.FramesWithFaces)
+	assert.Equal(t, 3, agg.FacesObserved)
+	assert.InDelta(t, 0.5, agg.MeanEngagement, 1e-9)
+	assert.InDelta(t, 0.1, agg.MinEngagement, 1e-9)
+	assert.InDelta(t, 0.8, agg.MaxEngagement, 1e-9)
+	assert.InDelta(t, 0.5, agg.EngagedFrameRatio, 1e-9)
+	assert.Equal(t, map[string]int{"happy": 1, "neutral": 1}, agg.EmotionCounts)
+	assert.Equal(t, 10*time.Second, agg.UpdatedAt.Sub(agg.StartedAt))
+}
+
+func TestStore_GetAndExpire(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := NewStore(time.Minute, 0.5, 0)
+	store.now = func() time.Time { return now }
+
+	_, ok := store.Get("s1")
+	assert.False(t, ok)
+
+	store.Record("s1", Frame{})
+	agg, ok := store.Get("s1")
+	require.True(t, ok)
+	assert.Equal(t, 1, agg.Frames)
+	assert.Zero(t, agg.MinEngagement)
+
+	// 返した集計結果を変更してもストアには影響しない
+	agg.EmotionCounts["happy"] = 10
+	agg, _ = store.Get("s1")
+	assert.Empty(t, agg.EmotionCounts)
+
+	now = now.Add(2 * time.Minute)
+	_, ok = store.Get("s1")
+	assert.False(t, ok)
+
+	// 期限切れ後は新しいセッションとして集計する
+	agg = store.Record("s1", Frame{Engagement: []float64{0.9}})
+	assert.Equal(t, 1, agg.Frames)
+	assert.InDelta(t, 0.9, agg.MeanEngagement, 1e-9)
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := NewStore(0, 0.5, 0)
+	store.Record("s1", Frame{Engagement: []float64{0.4}})
+	store.Delete("s1")
+
+	_, ok := store.Get("s1")
+	assert.False(t, ok)
+}
+
+func TestStore_MaxSessions(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := NewStore(time.Hour, 0.5, 2)
+	store.now = func() time.Time { return now }
+
+	store.Record("s1", Frame{})
+	now = now.Add(time.Second)
+	store.Record("s2", Frame{})
+	now = now.Add(time.Second)
+	store.Record("s1", Frame{})
+	now = now.Add(time.Second)
+
+	// 上限に達した場合は最も長く更新のないセッションを破棄する
+	store.Record("s3", Frame{})
+	_, ok := store.Get("s2")
+	assert.False(t, ok)
+	agg, ok := store.Get("s1")
+	require.True(t, ok)
+	assert.Equal(t, 2, agg.Frames)
+	_, ok = store.Get("s3")
+	assert.True(t, ok)
+}
@@ -0,0 +1,169 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// 購読者ごとのイベントのバッファのデフォルトのサイズ
+const defaultSubscriberBuffer = 16
+
+// セッションのイベントの種類
+const (
+	// 分析結果
+	EventResult = "result"
+	// 平滑化した主要な感情の変化
+	EventEmotion = "emotion"
+)
+
+// 購読者に配信するイベント
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// 平滑化した主要な感情が変化したときのイベントのデータ
+type EmotionChange struct {
+	SessionID string    `json:"sessionId"`
+	Emotion   string    `json:"emotion"`
+	Previous  string    `json:"previous,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// セッションごとの分析結果を購読者に配信する
+// 購読者のバッファが一杯の場合はイベントを破棄し、配信元を待たせない
+type Hub struct {
+	mu         sync.Mutex
+	topics     map[string]*topic
+	bufferSize int
+	window     int
+	now        func() time.Time
+}
+
+type topic struct {
+	subscribers map[*Subscription]struct{}
+	// 主要な顔を1つの追跡対象として平滑化する
+	smoother *Smoother
+	emotion  string
+}
+
+// セッションのイベントの購読
+type Subscription struct {
+	hub       *Hub
+	sessionID string
+	events    chan Event
+	dropped   int64
+}
+
+// 配信の仲介を作成
+// bufferSize は購読者ごとのバッファのサイズ、window は感情の平滑化に使用するフレーム数
+func NewHub(bufferSize, window int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = defaultSubscriberBuffer
+	}
+	return &Hub{
+		topics:     make(map[string]*topic),
+		bufferSize: bufferSize,
+		window:     window,
+		now:        time.Now,
+	}
+}
+
+// セッションのイベントを購読
+// 使用後は Close を呼び出す
+func (h *Hub) Subscribe(sessionID string) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[sessionID]
+	if !ok {
+		t = &topic{
+			subscribers: make(map[*Subscription]struct{}),
+			smoother:    NewSmoother(h.window),
+		}
+		h.topics[sessionID] = t
+	}
+	sub := &Subscription{
+		hub:       h,
+		sessionID: sessionID,
+		events:    make(chan Event, h.bufferSize),
+	}
+	t.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// セッションに購読者がいるかを返す
+func (h *Hub) HasSubscribers(sessionID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, ok := h.topics[sessionID]
+	return ok
+}
+
+// 分析結果を配信し、平滑化した主要な感情が変化した場合はその変化も配信する
+// 購読者がいないセッションの結果は破棄する
+func (h *Hub) Publish(sessionID, emotion string, result interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[sessionID]
+	if !ok {
+		return
+	}
+	t.deliver(Event{Type: EventResult, Data: result})
+
+	smoothed := t.smoother.Update([]Observation{{TrackID: 1, Emotion: emotion}})[0].Emotion
+	if smoothed == t.emotion {
+		return
+	}
+	t.deliver(Event{Type: EventEmotion, Data: EmotionChange{
+		SessionID: sessionID,
+		Emotion:   smoothed,
+		Previous:  t.emotion,
+		ChangedAt: h.now(),
+	}})
+	t.emotion = smoothed
+}
+
+func (t *topic) deliver(event Event) {
+	for sub := range t.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// 配信されたイベントを受け取るチャネル
+// Close を呼び出すと閉じられる
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// バッファが一杯で破棄したイベントの数
+func (s *Subscription) Dropped() int64 {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// 購読を終了
+// 最後の購読者が終了したセッションの平滑化の状態は破棄する
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	t, ok := s.hub.topics[s.sessionID]
+	if !ok {
+		return
+	}
+	if _, ok := t.subscribers[s]; !ok {
+		return
+	}
+	delete(t.subscribers, s)
+	close(s.events)
+	if len(t.subscribers) == 0 {
+		delete(s.hub.topics, s.sessionID)
+	}
+}
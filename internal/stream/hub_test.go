@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// チャネルに届いているイベントを全て受け取る
+func drain(sub *Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case e, ok := <-sub.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestHub_Publish(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hub := NewHub(16, 3)
+	hub.now = func() time.Time { return now }
+
+	sub := hub.Subscribe("s1")
+	defer sub.Close()
+	other := hub.Subscribe("s2")
+	defer other.Close()
+
+	hub.Publish("s1", "happy", 1)
+	hub.Publish("s1", "sad", 2)
+	hub.Publish("s1", "happy", 3)
+	// 購読者のいないセッションは配信しない
+	hub.Publish("s3", "happy", 4)
+
+	events := drain(sub)
+	require.Len(t, events, 6)
+	assert.Equal(t, Event{Type: EventResult, Data: 1}, events[0])
+	assert.Equal(t, Event{Type: EventEmotion, Data: EmotionChange{
+		SessionID: "s1", Emotion: "happy", ChangedAt: now,
+	}}, events[1])
+	// 同数の場合は新しい感情になる
+	assert.Equal(t, Event{Type: EventResult, Data: 2}, events[2])
+	assert.Equal(t, Event{Type: EventEmotion, Data: EmotionChange{
+		SessionID: "s1", Emotion: "sad", Previous: "happy", ChangedAt: now,
+	}}, events[3])
+	assert.Equal(t, Event{Type: EventResult, Data: 3}, events[4])
+	assert.Equal(t, EventEmotion, events[5].Type)
+
+	assert.Empty(t, drain(other))
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := NewHub(2, 1)
+	sub := hub.Subscribe("s1")
+	defer sub.Close()
+
+	// バッファが一杯の場合は配信元を待たせずに破棄する
+	for i := 0; i < 3; i++ {
+		hub.Publish("s1", "happy", i)
+	}
+	assert.Len(t, drain(sub), 2)
+	assert.Equal(t, int64(2), sub.Dropped())
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(16, 3)
+	first := hub.Subscribe("s1")
+	second := hub.Subscribe("s1")
+	assert.True(t, hub.HasSubscribers("s1"))
+
+	first.Close()
+	first.Close()
+	_, ok := <-first.Events()
+	assert.False(t, ok)
+	assert.True(t, hub.HasSubscribers("s1"))
+
+	second.Close()
+	assert.False(t, hub.HasSubscribers("s1"))
+}
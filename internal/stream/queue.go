@@ -0,0 +1,47 @@
+package stream
+
+import "time"
+
+// クライアントから受信した1フレーム
+type Frame struct {
+	// 接続内でのフレームの通し番号（1から）
+	Seq        uint64
+	Data       []byte
+	ReceivedAt time.Time
+}
+
+// 最新のフレームだけを保持するキュー
+// 分析が追いつかない場合は古いフレームを破棄する
+// 追加は1つのゴルーチンからのみ行う
+type LatestQueue struct {
+	frames chan Frame
+}
+
+func NewLatestQueue() *LatestQueue {
+	return &LatestQueue{frames: make(chan Frame, 1)}
+}
+
+// フレームを追加
+// 処理待ちのフレームがあれば破棄し、破棄した場合は true を返す
+func (q *LatestQueue) Push(frame Frame) bool {
+	select {
+	case q.frames <- frame:
+		return false
+	default:
+	}
+
+	dropped := false
+	select {
+	case <-q.frames:
+		dropped = true
+	default:
+	}
+	// 追加するゴルーチンは1つなので、取り出した後は必ず空きがある
+	q.frames <- frame
+	return dropped
+}
+
+// 処理するフレームを受け取るチャネル
+func (q *LatestQueue) Frames() <-chan Frame {
+	return q.frames
+}
@@ -0,0 +1,23 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatestQueue(t *testing.T) {
+	q := NewLatestQueue()
+
+	assert.False(t, q.Push(Frame{Seq: 1}))
+	// 処理待ちのフレームは新しいフレームで置き換える
+	assert.True(t, q.Push(Frame{Seq: 2}))
+	assert.True(t, q.Push(Frame{Seq: 3}))
+
+	frame := <-q.Frames()
+	assert.Equal(t, uint64(3), frame.Seq)
+
+	assert.False(t, q.Push(Frame{Seq: 4}))
+	frame = <-q.Frames()
+	assert.Equal(t, uint64(4), frame.Seq)
+}
@@ -0,0 +1,83 @@
+package stream
+
+// トラッキングIDごとの1フレーム分の観測値
+type Observation struct {
+	TrackID    int
+	Emotion    string
+	Engagement float64
+}
+
+// フレーム間で感情とエンゲージメントスコアを平滑化する
+// 追跡中の顔ごとに直近のフレームを保持し、感情は最頻値、スコアは平均を返す
+// 1つの接続の中でのみ使用し、並行して呼び出さない
+type Smoother struct {
+	window int
+	tracks map[int][]Observation
+}
+
+// 平滑化を作成
+// window は平滑化に使用するフレーム数で、1以下の場合は平滑化しない
+func NewSmoother(window int) *Smoother {
+	return &Smoother{
+		window: window,
+		tracks: make(map[int][]Observation),
+	}
+}
+
+// フレームの観測値を記録し、平滑化した値を返す
+// トラッキングIDのない顔はそのまま返し、フレームに含まれない追跡対象の履歴は破棄する
+func (s *Smoother) Update(observations []Observation) []Observation {
+	if s.window <= 1 {
+		return observations
+	}
+
+	smoothed := make([]Observation, len(observations))
+	seen := make(map[int]bool, len(observations))
+	for i, obs := range observations {
+		if obs.TrackID == 0 {
+			smoothed[i] = obs
+			continue
+		}
+		seen[obs.TrackID] = true
+
+		history := append(s.tracks[obs.TrackID], obs)
+		if len(history) > s.window {
+			history = history[len(history)-s.window:]
+		}
+		s.tracks[obs.TrackID] = history
+		smoothed[i] = Observation{
+			TrackID:    obs.TrackID,
+			Emotion:    majorityEmotion(history),
+			Engagement: meanEngagement(history),
+		}
+	}
+
+	for id := range s.tracks {
+		if !seen[id] {
+			delete(s.tracks, id)
+		}
+	}
+	return smoothed
+}
+
+// 最も多く出現した感情を返す
+// 同数の場合はより新しいフレームの感情を優先する
+func majorityEmotion(history []Observation) string {
+	counts := make(map[string]int, len(history))
+	best := ""
+	for _, obs := range history {
+		counts[obs.Emotion]++
+		if counts[obs.Emotion] >= counts[best] {
+			best = obs.Emotion
+		}
+	}
+	return best
+}
+
+func meanEngagement(history []Observation) float64 {
+	var sum float64
+	for _, obs := range history {
+		sum += obs.Engagement
+	}
+	return sum / float64(len(history))
+}
@@ -0,0 +1,90 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSmoother_Update(t *testing.T) {
+	tests := []struct {
+		name   string
+		window int
+		frames [][]Observation
+		want   []Observation
+	}{
+		{
+			name:   "感情の最頻値とスコアの平均",
+			window: 3,
+			frames: [][]Observation{
+				{{TrackID: 1, Emotion: "happy", Engagement: 0.2}},
+				{{TrackID: 1, Emotion: "sad", Engagement: 0.4}},
+				{{TrackID: 1, Emotion: "happy", Engagement: 0.6}},
+			},
+			want: []Observation{{TrackID: 1, Emotion: "happy", Engagement: 0.4}},
+		},
+		{
+			name:   "同数の場合は新しい感情を優先",
+			window: 2,
+			frames: [][]Observation{
+				{{TrackID: 1, Emotion: "happy", Engagement: 0.5}},
+				{{TrackID: 1, Emotion: "sad", Engagement: 0.5}},
+			},
+			want: []Observation{{TrackID: 1, Emotion: "sad", Engagement: 0.5}},
+		},
+		{
+			name:   "窓より古いフレームは使用しない",
+			window: 2,
+			frames: [][]Observation{
+				{{TrackID: 1, Emotion: "happy", Engagement: 1}},
+				{{TrackID: 1, Emotion: "sad", Engagement: 0.2}},
+				{{TrackID: 1, Emotion: "sad", Engagement: 0.4}},
+			},
+			want: []Observation{{TrackID: 1, Emotion: "sad", Engagement: 0.3}},
+		},
+		{
+			name:   "見失った追跡対象の履歴は破棄",
+			window: 3,
+			frames: [][]Observation{
+				{{TrackID: 1, Emotion: "happy", Engagement: 1}},
+				{{TrackID: 2, Emotion: "sad", Engagement: 0.2}},
+				{{TrackID: 1, Emotion: "angry", Engagement: 0.4}},
+			},
+			want: []Observation{{TrackID: 1, Emotion: "angry", Engagement: 0.4}},
+		},
+		{
+			name:   "トラッキングIDのない顔はそのまま",
+			window: 3,
+			frames: [][]Observation{
+				{{Emotion: "happy", Engagement: 1}},
+				{{Emotion: "sad", Engagement: 0.2}},
+			},
+			want: []Observation{{Emotion: "sad", Engagement: 0.2}},
+		},
+		{
+			name:   "窓が1以下の場合は平滑化しない",
+			window: 1,
+			frames: [][]Observation{
+				{{TrackID: 1, Emotion: "happy", Engagement: 1}},
+				{{TrackID: 1, Emotion: "sad", Engagement: 0.2}},
+			},
+			want: []Observation{{TrackID: 1, Emotion: "sad", Engagement: 0.2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSmoother(tt.window)
+			var got []Observation
+			for _, frame := range tt.frames {
+				got = s.Update(frame)
+			}
+			assert.Len(t, got, len(tt.want))
+			for i := range tt.want {
+				assert.Equal(t, tt.want[i].TrackID, got[i].TrackID)
+				assert.Equal(t, tt.want[i].Emotion, got[i].Emotion)
+				assert.InDelta(t, tt.want[i].Engagement, got[i].Engagement, 1e-9)
+			}
+		})
+	}
+}
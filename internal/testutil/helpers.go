@@ -164,6 +164,18 @@ func CompareResponses(t *testing.T, got, want *http.Response) {
 	}
 }
 
+// テスト終了時に生存中のOpenCVのMat数が開始時より増えていればテストを失敗させる
+// count には analyzer.MatProfileCount や ResourceManager の貸し出し中のMat数を返す関数を渡す
+func VerifyNoMatLeaks(tb testing.TB, count func() int) {
+	tb.Helper()
+	before := count()
+	tb.Cleanup(func() {
+		if after := count(); after > before {
+			tb.Errorf("OpenCVのMatがリークしています: %d 個のMatが解放されていません", after-before)
+		}
+	})
+}
+
 // ヘルパー関数
 
 func min(a, b int) int {
@@ -0,0 +1,468 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/okamyuji/face-emotion-analyzer/internal/fetch"
+)
+
+const userAgent = "face-emotion-analyzer-webhook"
+
+// 接続を再利用するために読み捨てるレスポンスボディの上限
+const maxDiscardSize = 64 * 1024
+
+// 配信の結果（メトリクスのラベル）
+const (
+	ResultSuccess = "success"
+	ResultRetry   = "retry"
+	ResultFailed  = "failed"
+	ResultDropped = "dropped"
+)
+
+var errUnauthorized = errors.CodeError(errors.ErrCodeUnauthorized, "invalid api key", nil)
+
+// 配信の結果を記録する
+type DeliveryObserver interface {
+	RecordWebhookDelivery(eventType, result string, duration time.Duration)
+	SetWebhookDeadLetters(count int)
+}
+
+// 配信先が成功以外のステータスを返した場合のエラー
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status: %d", e.status)
+}
+
+type delivery struct {
+	url     string
+	event   Event
+	body    []byte
+	attempt int
+}
+
+// Webhookの登録を管理し、イベントを署名して配信する
+// 配信はワーカーで非同期に行い、失敗した場合は間隔を延ばしながら再試行する
+type Dispatcher struct {
+	guard            *fetch.Guard
+	client           *http.Client
+	secret           []byte
+	apiKeys          []string
+	maxAttempts      int
+	initialBackoff   time.Duration
+	maxBackoff       time.Duration
+	maxRegistrations int
+	deadLetterSize   int
+	observer         DeliveryObserver
+	store            Store
+
+	queue  chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// APIキーの識別子（keyID）ごとの配信先
+	registrations map[string][]Registration
+	deadLetters   []DeadLetter
+	now           func() time.Time
+}
+
+// 設定からディスパッチャーを作成し、配信するワーカーを起動する
+// observer が nil の場合はメトリクスを記録しない
+func New(cfg config.WebhookConfig, observer DeliveryObserver) (*Dispatcher, error) {
+	if !cfg.Enabled {
+		return nil, fmt.Errorf("Webhookが無効です")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	guard, err := fetch.NewGuard(cfg.AllowedSchemes, cfg.AllowedHosts, cfg.AllowedNetworks)
+	if err != nil {
+		return nil, err
+	}
+	store := NewStore(cfg.StorePath)
+	registrations, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		guard: guard,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: guard.Transport(cfg.Timeout),
+			// リダイレクト先は検証せずに追従しない
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		secret:           []byte(cfg.Secret),
+		apiKeys:          cfg.APIKeys,
+		maxAttempts:      cfg.MaxAttempts,
+		initialBackoff:   cfg.InitialBackoff,
+		maxBackoff:       cfg.MaxBackoff,
+		maxRegistrations: cfg.MaxRegistrations,
+		deadLetterSize:   cfg.DeadLetterSize,
+		observer:         observer,
+		store:            store,
+		queue:            make(chan delivery, cfg.QueueSize),
+		ctx:              ctx,
+		cancel:           cancel,
+		registrations:    registrations,
+		now:              time.Now,
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.work()
+	}
+	return d, nil
+}
+
+// ワーカーを停止する
+// 配信中のリクエストは中断し、待機中の配信と再試行は破棄する
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// 設定されたAPIキーかを返す
+func (d *Dispatcher) ValidAPIKey(apiKey string) bool {
+	if apiKey == "" {
+		return false
+	}
+	valid := false
+	for _, key := range d.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// 再起動後も登録が残るかを返す
+// 残る場合も登録はサーバーごとに保持するため、複数のサーバーでは保存先を共有する必要がある
+func (d *Dispatcher) Persistent() bool {
+	return d.store.Persistent()
+}
+
+// 配信先のURLを検証
+func (d *Dispatcher) CheckURL(rawURL string) error {
+	_, err := d.guard.ParseURL(rawURL)
+	return err
+}
+
+// APIキーに配信先を登録
+func (d *Dispatcher) Register(apiKey, rawURL string, events []string) (Registration, error) {
+	if !d.ValidAPIKey(apiKey) {
+		return Registration{}, errUnauthorized
+	}
+	u, err := d.guard.ParseURL(rawURL)
+	if err != nil {
+		return Registration{}, err
+	}
+	for _, e := range events {
+		if !eventTypes[e] {
+			return Registration{}, errors.CodeError(errors.ErrCodeInvalidRequest, "unknown event type: "+e, nil)
+		}
+	}
+	id, err := newID()
+	if err != nil {
+		return Registration{}, err
+	}
+
+	key := keyID(apiKey)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.registrations[key]) >= d.maxRegistrations {
+		return Registration{}, errors.CodeError(errors.ErrCodeInvalidRequest, "too many webhooks registered", nil)
+	}
+	registration := Registration{
+		ID:        id,
+		URL:       u.String(),
+		Events:    append([]string{}, events...),
+		CreatedAt: d.now(),
+	}
+	previous := d.registrations[key]
+	if err := d.replace(key, append(previous[:len(previous):len(previous)], registration), previous); err != nil {
+		return Registration{}, err
+	}
+	return registration, nil
+}
+
+// APIキーに登録された配信先を返す
+func (d *Dispatcher) Registrations(apiKey string) ([]Registration, error) {
+	if !d.ValidAPIKey(apiKey) {
+		return nil, errUnauthorized
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Registration{}, d.registrations[keyID(apiKey)]...), nil
+}
+
+// APIキーに登録された配信先を削除
+func (d *Dispatcher) Unregister(apiKey, id string) error {
+	if !d.ValidAPIKey(apiKey) {
+		return errUnauthorized
+	}
+	key := keyID(apiKey)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	registrations := d.registrations[key]
+	for i, r := range registrations {
+		if r.ID == id {
+			return d.replace(key, append(registrations[:i:i], registrations[i+1:]...), registrations)
+		}
+	}
+	return errors.CodeError(errors.ErrCodeNotFound, "webhook not found", nil)
+}
+
+// APIキーの配信先を置き換えて保存する
+// 保存に失敗した場合は previous に戻す。d.mu を保持して呼び出す
+func (d *Dispatcher) replace(key string, registrations, previous []Registration) error {
+	d.setRegistrations(key, registrations)
+	if err := d.store.Save(d.registrations); err != nil {
+		d.setRegistrations(key, previous)
+		return err
+	}
+	return nil
+}
+
+func (d *Dispatcher) setRegistrations(key string, registrations []Registration) {
+	if len(registrations) == 0 {
+		delete(d.registrations, key)
+		return
+	}
+	d.registrations[key] = registrations
+}
+
+// APIキーに登録された配信先のうち、イベントの種類が対象のものに通知する
+// APIキーが空または設定されていない場合は何もしない
+func (d *Dispatcher) Notify(apiKey, eventType string, data interface{}) {
+	if !d.ValidAPIKey(apiKey) {
+		return
+	}
+	d.mu.Lock()
+	var urls []string
+	for _, r := range d.registrations[keyID(apiKey)] {
+		if r.Accepts(eventType) {
+			urls = append(urls, r.URL)
+		}
+	}
+	d.mu.Unlock()
+	if len(urls) == 0 {
+		return
+	}
+
+	event, body, err := d.newEvent(eventType, data)
+	if err != nil {
+		slog.Error("Webhookのイベントの作成に失敗", "event", eventType, "error", err)
+		return
+	}
+	for _, url := range urls {
+		d.enqueue(delivery{url: url, event: event, body: body})
+	}
+}
+
+// 指定されたURLに通知する
+// ジョブごとの callbackUrl のように登録を介さない配信に使用する
+func (d *Dispatcher) Send(rawURL, eventType string, data interface{}) {
+	event, body, err := d.newEvent(eventType, data)
+	if err != nil {
+		slog.Error("Webhookのイベントの作成に失敗", "event", eventType, "error", err)
+		return
+	}
+	x := delivery{url: rawURL, event: event, body: body}
+	if err := d.CheckURL(rawURL); err != nil {
+		d.fail(x, 0, err)
+		return
+	}
+	d.enqueue(x)
+}
+
+// 配信に失敗したイベントを新しい順に返す
+func (d *Dispatcher) DeadLetters() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letters := make([]DeadLetter, len(d.deadLetters))
+	for i, letter := range d.deadLetters {
+		letters[len(letters)-1-i] = letter
+	}
+	return letters
+}
+
+func (d *Dispatcher) newEvent(eventType string, data interface{}) (Event, []byte, error) {
+	id, err := newID()
+	if err != nil {
+		return Event{}, nil, err
+	}
+	event := Event{ID: id, Type: eventType, CreatedAt: d.now(), Data: data}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return Event{}, nil, fmt.Errorf("イベントのエンコードに失敗: %w", err)
+	}
+	return event, body, nil
+}
+
+// 配信をキューに追加
+// キューが一杯の場合は再試行せずに配信の失敗として記録する
+func (d *Dispatcher) enqueue(x delivery) {
+	if d.ctx.Err() != nil {
+		return
+	}
+	select {
+	case d.queue <- x:
+	default:
+		d.deadLetter(x, 0, "delivery queue is full")
+		d.record(x.event.Type, ResultDropped, 0)
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.wg.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case x := <-d.queue:
+			d.deliver(x)
+		}
+	}
+}
+
+// 1回配信し、失敗した場合は再試行するか配信の失敗として記録する
+func (d *Dispatcher) deliver(x delivery) {
+	x.attempt++
+	start := time.Now()
+	status, err := d.post(x)
+	duration := time.Since(start)
+	if d.ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		d.record(x.event.Type, ResultSuccess, duration)
+	case retryable(status, err) && x.attempt < d.maxAttempts:
+		d.record(x.event.Type, ResultRetry, duration)
+		backoff := d.backoff(x.attempt)
+		slog.Warn("Webhookの配信に失敗したため再試行します",
+			"event_id", x.event.ID, "attempt", x.attempt, "backoff", backoff, "error", err)
+		time.AfterFunc(backoff, func() { d.enqueue(x) })
+	default:
+		d.fail(x, status, err)
+		d.record(x.event.Type, ResultFailed, duration)
+	}
+}
+
+// 署名したイベントを送信
+// 成功以外のステータスの場合はステータスとエラーを返す
+func (d *Dispatcher) post(x delivery) (int, error) {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, x.url, bytes.NewReader(x.body))
+	if err != nil {
+		return 0, err
+	}
+	now := d.now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderID, x.event.ID)
+	req.Header.Set(HeaderEvent, x.event.Type)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(d.secret, now, x.body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, &statusError{status: resp.StatusCode}
+	}
+	return resp.StatusCode, nil
+}
+
+// 再試行で成功する可能性がある失敗かを返す
+// 接続先のアドレスの拒否や 4xx（408、429 を除く）は再試行しない
+func retryable(status int, err error) bool {
+	if status != 0 {
+		return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+	}
+	var codeErr *errors.Error
+	return !errors.As(err, &codeErr)
+}
+
+// 再試行までの間隔を返す
+// 失敗するごとに2倍にし、上限を超えない
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	backoff := d.initialBackoff
+	for i := 1; i < attempt && backoff < d.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.maxBackoff {
+		backoff = d.maxBackoff
+	}
+	return backoff
+}
+
+// 配信の失敗をログに出力して記録
+func (d *Dispatcher) fail(x delivery, status int, err error) {
+	slog.Error("Webhookの配信に失敗",
+		"event_id", x.event.ID, "event", x.event.Type, "attempts", x.attempt, "status", status, "error", err)
+	d.deadLetter(x, status, err.Error())
+}
+
+// 配信に失敗したイベントを保持数まで記録
+func (d *Dispatcher) deadLetter(x delivery, status int, message string) {
+	d.mu.Lock()
+	d.deadLetters = append(d.deadLetters, DeadLetter{
+		EventID:    x.event.ID,
+		EventType:  x.event.Type,
+		URL:        x.url,
+		Attempts:   x.attempt,
+		LastStatus: status,
+		LastError:  message,
+		FailedAt:   d.now(),
+	})
+	if len(d.deadLetters) > d.deadLetterSize {
+		d.deadLetters = d.deadLetters[len(d.deadLetters)-d.deadLetterSize:]
+	}
+	count := len(d.deadLetters)
+	d.mu.Unlock()
+
+	if d.observer != nil {
+		d.observer.SetWebhookDeadLetters(count)
+	}
+}
+
+func (d *Dispatcher) record(eventType, result string, duration time.Duration) {
+	if d.observer != nil {
+		d.observer.RecordWebhookDelivery(eventType, result, duration)
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("IDの生成に失敗: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
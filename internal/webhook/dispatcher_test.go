@@ -0,0 +1,418 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/okamyuji/face-emotion-analyzer/config"
+	"github.com/okamyuji/face-emotion-analyzer/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIKey = "test-api-key"
+
+// テスト用のサーバーに配信できるようループバックを許可した設定
+func testConfig() config.WebhookConfig {
+	return config.WebhookConfig{
+		Enabled:          true,
+		Secret:           "test-secret",
+		APIKeys:          []string{testAPIKey},
+		AllowedSchemes:   []string{"http"},
+		AllowedNetworks:  []string{"127.0.0.0/8"},
+		Timeout:          time.Second,
+		MaxAttempts:      3,
+		InitialBackoff:   10 * time.Millisecond,
+		MaxBackoff:       20 * time.Millisecond,
+		Workers:          2,
+		QueueSize:        10,
+		DeadLetterSize:   2,
+		MaxRegistrations: 2,
+	}
+}
+
+func newTestDispatcher(t *testing.T, modify func(c *config.WebhookConfig), observer DeliveryObserver) *Dispatcher {
+	t.Helper()
+	cfg := testConfig()
+	if modify != nil {
+		modify(&cfg)
+	}
+	d, err := New(cfg, observer)
+	require.NoError(t, err)
+	t.Cleanup(d.Close)
+	return d
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// 受信したリクエストを記録し、statuses の順にステータスを返すサーバー
+// statuses を使い切った後は 200 を返す
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, <-chan receivedRequest) {
+	t.Helper()
+	received := make(chan receivedRequest, 10)
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{header: r.Header.Clone(), body: body}
+		if i := int(count.Add(1)) - 1; i < len(statuses) {
+			w.WriteHeader(statuses[i])
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func receive(t *testing.T, received <-chan receivedRequest) receivedRequest {
+	t.Helper()
+	select {
+	case r := <-received:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("Webhookが配信されませんでした")
+		return receivedRequest{}
+	}
+}
+
+type deliveryRecord struct {
+	eventType string
+	result    string
+}
+
+type fakeObserver struct {
+	mu          sync.Mutex
+	deliveries  []deliveryRecord
+	deadLetters int
+}
+
+func (o *fakeObserver) RecordWebhookDelivery(eventType, result string, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.deliveries = append(o.deliveries, deliveryRecord{eventType: eventType, result: result})
+}
+
+func (o *fakeObserver) SetWebhookDeadLetters(count int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.deadLetters = count
+}
+
+func (o *fakeObserver) deadLetterCount() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.deadLetters
+}
+
+func (o *fakeObserver) results() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var results []string
+	for _, d := range o.deliveries {
+		results = append(results, d.result)
+	}
+	return results
+}
+
+func TestSign(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	body := []byte(`{"id":"1"}`)
+
+	signature := Sign([]byte("secret"), timestamp, body)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.Equal(t, signature, Sign([]byte("secret"), timestamp, body))
+	assert.NotEqual(t, signature, Sign([]byte("other"), timestamp, body))
+	assert.NotEqual(t, signature, Sign([]byte("secret"), timestamp.Add(time.Second), body))
+	assert.NotEqual(t, signature, Sign([]byte("secret"), timestamp, []byte(`{"id":"2"}`)))
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	server, received := newReceiver(t)
+	observer := &fakeObserver{}
+	d := newTestDispatcher(t, nil, observer)
+
+	_, err := d.Register(testAPIKey, server.URL+"/hook", []string{EventAnalysisCompleted})
+	require.NoError(t, err)
+
+	// 対象外のイベントや他のAPIキーには配信しない
+	d.Notify(testAPIKey, EventJobCompleted, map[string]string{"id": "job"})
+	d.Notify("other-key", EventAnalysisCompleted, map[string]string{"emotion": "sad"})
+	d.Notify(testAPIKey, EventAnalysisCompleted, map[string]string{"emotion": "happy"})
+
+	r := receive(t, received)
+	assert.Equal(t, "application/json", r.header.Get("Content-Type"))
+	assert.Equal(t, EventAnalysisCompleted, r.header.Get(HeaderEvent))
+
+	timestamp, err := strconv.ParseInt(r.header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Sign([]byte("test-secret"), time.Unix(timestamp, 0), r.body), r.header.Get(HeaderSignature))
+
+	var event struct {
+		ID   string            `json:"id"`
+		Type string            `json:"type"`
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(r.body, &event))
+	assert.Equal(t, r.header.Get(HeaderID), event.ID)
+	assert.Equal(t, EventAnalysisCompleted, event.Type)
+	assert.Equal(t, "happy", event.Data["emotion"])
+
+	select {
+	case extra := <-received:
+		t.Fatalf("対象外のイベントが配信されました: %s", extra.header.Get(HeaderEvent))
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, []string{ResultSuccess}, observer.results())
+}
+
+func TestDispatcher_Retry(t *testing.T) {
+	tests := []struct {
+		name           string
+		statuses       []int
+		wantRequests   int
+		wantResults    []string
+		wantDeadLetter bool
+		wantLastStatus int
+	}{
+		{
+			name:         "再試行して成功",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			wantRequests: 3,
+			wantResults:  []string{ResultRetry, ResultRetry, ResultSuccess},
+		},
+		{
+			name:           "最大回数まで失敗",
+			statuses:       []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			wantRequests:   3,
+			wantResults:    []string{ResultRetry, ResultRetry, ResultFailed},
+			wantDeadLetter: true,
+			wantLastStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "再試行しないステータス",
+			statuses:       []int{http.StatusBadRequest},
+			wantRequests:   1,
+			wantResults:    []string{ResultFailed},
+			wantDeadLetter: true,
+			wantLastStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := newReceiver(t, tt.statuses...)
+			observer := &fakeObserver{}
+			d := newTestDispatcher(t, nil, observer)
+
+			d.Send(server.URL+"/callback", EventJobCompleted, map[string]string{"id": "job"})
+
+			var ids []string
+			for i := 0; i < tt.wantRequests; i++ {
+				ids = append(ids, receive(t, received).header.Get(HeaderID))
+			}
+			// 再試行しても同じイベントIDで配信する
+			for _, id := range ids {
+				assert.Equal(t, ids[0], id)
+			}
+
+			require.Eventually(t, func() bool {
+				return len(observer.results()) == len(tt.wantResults)
+			}, 2*time.Second, 10*time.Millisecond)
+			assert.Equal(t, tt.wantResults, observer.results())
+
+			letters := d.DeadLetters()
+			if !tt.wantDeadLetter {
+				assert.Empty(t, letters)
+				return
+			}
+			require.Len(t, letters, 1)
+			assert.Equal(t, ids[0], letters[0].EventID)
+			assert.Equal(t, EventJobCompleted, letters[0].EventType)
+			assert.Equal(t, tt.wantRequests, letters[0].Attempts)
+			assert.Equal(t, tt.wantLastStatus, letters[0].LastStatus)
+			assert.Equal(t, 1, observer.deadLetterCount())
+		})
+	}
+}
+
+func TestDispatcher_BlockedAddress(t *testing.T) {
+	server, _ := newReceiver(t)
+	observer := &fakeObserver{}
+	d := newTestDispatcher(t, func(c *config.WebhookConfig) { c.AllowedNetworks = nil }, observer)
+
+	// 接続先のアドレスの拒否は再試行しない
+	d.Send(server.URL+"/callback", EventJobCompleted, nil)
+	require.Eventually(t, func() bool {
+		return len(d.DeadLetters()) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, d.DeadLetters()[0].Attempts)
+	assert.Equal(t, []string{ResultFailed}, observer.results())
+}
+
+func TestDispatcher_DeadLetters(t *testing.T) {
+	d := newTestDispatcher(t, nil, nil)
+
+	// 許可されていないURLは配信せずに記録し、保持数を超えた古いものから破棄する
+	for _, url := range []string{"ftp://a.example.com", "ftp://b.example.com", "ftp://c.example.com"} {
+		d.Send(url, EventJobFailed, nil)
+	}
+	letters := d.DeadLetters()
+	require.Len(t, letters, 2)
+	assert.Equal(t, "ftp://c.example.com", letters[0].URL)
+	assert.Equal(t, "ftp://b.example.com", letters[1].URL)
+	assert.Equal(t, 0, letters[0].Attempts)
+}
+
+func TestDispatcher_Register(t *testing.T) {
+	d := newTestDispatcher(t, nil, nil)
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		url      string
+		events   []string
+		wantCode string
+	}{
+		{
+			name:   "登録",
+			apiKey: testAPIKey,
+			url:    "http://127.0.0.1:8081/hook",
+			events: []string{EventJobCompleted, EventJobFailed},
+		},
+		{
+			name:     "不正なAPIキー",
+			apiKey:   "invalid",
+			url:      "http://127.0.0.1:8081/hook",
+			wantCode: errors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "APIキーが未指定",
+			url:      "http://127.0.0.1:8081/hook",
+			wantCode: errors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "許可されていないスキーム",
+			apiKey:   testAPIKey,
+			url:      "file:///etc/passwd",
+			wantCode: errors.ErrCodeURLSchemeNotAllowed,
+		},
+		{
+			name:     "不明なイベント",
+			apiKey:   testAPIKey,
+			url:      "http://127.0.0.1:8081/hook",
+			events:   []string{"job.started"},
+			wantCode: errors.ErrCodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registration, err := d.Register(tt.apiKey, tt.url, tt.events)
+			if tt.wantCode != "" {
+				var e *errors.Error
+				require.True(t, errors.As(err, &e), "err = %v", err)
+				assert.Equal(t, tt.wantCode, e.Code)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, registration.ID)
+			assert.Equal(t, tt.url, registration.URL)
+			assert.Equal(t, tt.events, registration.Events)
+		})
+	}
+
+	t.Run("登録数の上限", func(t *testing.T) {
+		_, err := d.Register(testAPIKey, "http://127.0.0.1:8081/second", nil)
+		require.NoError(t, err)
+		_, err = d.Register(testAPIKey, "http://127.0.0.1:8081/third", nil)
+		var e *errors.Error
+		require.True(t, errors.As(err, &e))
+		assert.Equal(t, errors.ErrCodeInvalidRequest, e.Code)
+	})
+
+	t.Run("一覧と削除", func(t *testing.T) {
+		registrations, err := d.Registrations(testAPIKey)
+		require.NoError(t, err)
+		require.Len(t, registrations, 2)
+
+		require.NoError(t, d.Unregister(testAPIKey, registrations[0].ID))
+		registrations, err = d.Registrations(testAPIKey)
+		require.NoError(t, err)
+		assert.Len(t, registrations, 1)
+
+		var e *errors.Error
+		require.True(t, errors.As(d.Unregister(testAPIKey, "unknown"), &e))
+		assert.Equal(t, errors.ErrCodeNotFound, e.Code)
+	})
+}
+
+func TestDispatcher_Store(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks", "webhooks.json")
+	withStore := func(c *config.WebhookConfig) { c.StorePath = path }
+
+	d := newTestDispatcher(t, withStore, nil)
+	assert.True(t, d.Persistent())
+	first, err := d.Register(testAPIKey, "http://127.0.0.1:8081/first", nil)
+	require.NoError(t, err)
+	second, err := d.Register(testAPIKey, "http://127.0.0.1:8081/second", []string{EventJobCompleted})
+	require.NoError(t, err)
+	require.NoError(t, d.Unregister(testAPIKey, first.ID))
+
+	// APIキーそのものは保存しない
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), testAPIKey)
+
+	t.Run("再起動後も登録が残る", func(t *testing.T) {
+		restarted := newTestDispatcher(t, withStore, nil)
+		registrations, err := restarted.Registrations(testAPIKey)
+		require.NoError(t, err)
+		require.Len(t, registrations, 1)
+		assert.Equal(t, second.ID, registrations[0].ID)
+		assert.Equal(t, second.Events, registrations[0].Events)
+	})
+
+	t.Run("保存先が未設定", func(t *testing.T) {
+		assert.False(t, newTestDispatcher(t, nil, nil).Persistent())
+	})
+
+	t.Run("不正な登録ファイル", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "webhooks.json")
+		require.NoError(t, os.WriteFile(invalid, []byte("{"), 0o600))
+		cfg := testConfig()
+		cfg.StorePath = invalid
+		_, err := New(cfg, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	d := &Dispatcher{initialBackoff: time.Second, maxBackoff: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 100, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.attempt), func(t *testing.T) {
+			assert.Equal(t, tt.want, d.backoff(tt.attempt))
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 登録された配信先を保持する
+// 登録はAPIキーのハッシュ（keyID）ごとにまとめ、APIキーそのものは保存しない
+type Store interface {
+	// 保存された配信先をすべて読み込む
+	Load() (map[string][]Registration, error)
+	// 配信先をすべて保存する
+	Save(registrations map[string][]Registration) error
+	// 再起動後も登録が残るかを返す
+	Persistent() bool
+}
+
+// 保存先のパスからストアを作成
+// パスが空の場合はメモリ上でのみ保持し、再起動すると登録は消える
+func NewStore(path string) Store {
+	if path == "" {
+		return memoryStore{}
+	}
+	return &fileStore{path: path}
+}
+
+// 登録をまとめるAPIキーの識別子
+func keyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// 何も保存しないストア
+type memoryStore struct{}
+
+func (memoryStore) Load() (map[string][]Registration, error) {
+	return map[string][]Registration{}, nil
+}
+
+func (memoryStore) Save(map[string][]Registration) error {
+	return nil
+}
+
+func (memoryStore) Persistent() bool {
+	return false
+}
+
+// JSONファイルに保存するストア
+// ファイルはサーバーごとに保持するため、複数のサーバーで登録を共有する場合は共有のストレージに置く
+type fileStore struct {
+	path string
+}
+
+// ファイルが存在しない場合は空の登録を返す
+func (s *fileStore) Load() (map[string][]Registration, error) {
+	registrations := map[string][]Registration{}
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return registrations, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Webhookの登録ファイルの読み込みに失敗: %w", err)
+	}
+	if err := json.Unmarshal(data, &registrations); err != nil {
+		return nil, fmt.Errorf("Webhookの登録ファイルの解析に失敗: %w", err)
+	}
+	return registrations, nil
+}
+
+// 書き込み途中のファイルを読み込まないよう一時ファイルに書いてから置き換える
+func (s *fileStore) Save(registrations map[string][]Registration) error {
+	data, err := json.Marshal(registrations)
+	if err != nil {
+		return fmt.Errorf("Webhookの登録のエンコードに失敗: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("Webhookの登録ディレクトリの作成に失敗: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".webhooks-*.tmp")
+	if err != nil {
+		return fmt.Errorf("一時ファイルの作成に失敗: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Webhookの登録の書き込みに失敗: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Webhookの登録の書き込みに失敗: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("Webhookの登録ファイルの置き換えに失敗: %w", err)
+	}
+	return nil
+}
+
+func (s *fileStore) Persistent() bool {
+	return true
+}
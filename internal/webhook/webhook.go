@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Webhookのリクエストヘッダー
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// 通知するイベントの種類
+const (
+	EventAnalysisCompleted = "analysis.completed"
+	EventJobCompleted      = "job.completed"
+	EventJobFailed         = "job.failed"
+	EventJobCancelled      = "job.cancelled"
+)
+
+// 登録時に指定できるイベントの種類
+var eventTypes = map[string]bool{
+	EventAnalysisCompleted: true,
+	EventJobCompleted:      true,
+	EventJobFailed:         true,
+	EventJobCancelled:      true,
+}
+
+// 配信するイベント
+// 再試行しても同じ ID で配信するため、受信側は ID で重複を除去できる
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// APIキーごとに登録された配信先
+type Registration struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// 通知するイベントの種類（空の場合はすべてのイベント）
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// イベントの種類が通知の対象かを返す
+func (r Registration) Accepts(eventType string) bool {
+	if len(r.Events) == 0 {
+		return true
+	}
+	for _, e := range r.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// 最大回数まで再試行しても配信できなかったイベント
+type DeadLetter struct {
+	EventID   string `json:"eventId"`
+	EventType string `json:"eventType"`
+	URL       string `json:"url"`
+	Attempts  int    `json:"attempts"`
+	// 最後の配信のステータスコード（接続できなかった場合は0）
+	LastStatus int       `json:"lastStatus,omitempty"`
+	LastError  string    `json:"lastError"`
+	FailedAt   time.Time `json:"failedAt"`
+}
+
+// 署名を計算
+// タイムスタンプとボディを "." で連結した値の HMAC-SHA256 を "sha256=" に続けて16進数で返す
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
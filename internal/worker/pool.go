@@ -8,6 +8,9 @@ import (
 	"time"
 )
 
+// シャットダウン後に投入した場合や、シャットダウンの期限までに実行されなかった場合のエラー
+var ErrPoolShutdown = errors.New("worker pool is shutdown")
+
 // ワーカーゴルーチン
 type worker struct {
 	pool     *Pool
@@ -16,8 +19,7 @@ type worker struct {
 
 // ワーカープールを
 type Pool struct {
-	tasks         chan Task
-	results       chan Result
+	tasks         chan queuedTask
 	numWorkers    int32
 	maxWorkers    int32
 	metrics       metrics
@@ -27,7 +29,17 @@ type Pool struct {
 	shutdownOnce  sync.Once
 	activeWorkers atomic.Int32
 	minWorkers    int32
-	shutdownChan  chan struct{}
+	// シャットダウンの開始を投入待ちの Submit に知らせる
+	draining chan struct{}
+	// シャットダウンの期限を過ぎた場合に残りのタスクを破棄させる
+	shutdownChan chan struct{}
+}
+
+// キューに入れたタスクと投入元ごとの結果の送信先
+type queuedTask struct {
+	task   Task
+	ctx    context.Context
+	result chan Result
 }
 
 // タスクの実行結果
@@ -71,35 +83,42 @@ func (w *worker) run() {
 		select {
 		case <-w.pool.shutdownChan:
 			return
-		case task, ok := <-w.pool.tasks:
+		case qt, ok := <-w.pool.tasks:
 			if !ok {
 				return
 			}
-
-			start := time.Now()
-			result, err := task.Execute(context.Background())
-			duration := time.Since(start)
-
-			atomic.AddInt64(&w.pool.metrics.tasksProcessed, 1)
-			atomic.AddInt64(&w.pool.metrics.processingTime, duration.Nanoseconds())
-
-			if err != nil {
-				atomic.AddInt64(&w.pool.metrics.errors, 1)
-			}
-
-			// シャットダウン中でないことを確認してから結果を送信
 			select {
 			case <-w.pool.shutdownChan:
+				qt.result <- Result{Err: ErrPoolShutdown}
 				return
-			case w.pool.results <- Result{Value: result, Err: err}:
 			default:
-				// 結果を送信できない場合（バッファが満杯など）はエラーをログに記録
-				atomic.AddInt64(&w.pool.metrics.errors, 1)
 			}
+			// 投入元ごとのチャネルはバッファがあるため、受信されなくても待機しない
+			qt.result <- w.execute(qt)
 		}
 	}
 }
 
+// タスクを実行
+// 投入元がすでにキャンセルしたタスクは実行しない
+func (w *worker) execute(qt queuedTask) Result {
+	if err := qt.ctx.Err(); err != nil {
+		return Result{Err: err}
+	}
+
+	start := time.Now()
+	result, err := qt.task.Execute(qt.ctx)
+	duration := time.Since(start)
+
+	atomic.AddInt64(&w.pool.metrics.tasksProcessed, 1)
+	atomic.AddInt64(&w.pool.metrics.processingTime, duration.Nanoseconds())
+
+	if err != nil {
+		atomic.AddInt64(&w.pool.metrics.errors, 1)
+	}
+	return Result{Value: result, Err: err}
+}
+
 // ワーカープールの統計情報
 func (p *Pool) GetStats() Stats {
 	tasksProcessed := atomic.LoadInt64(&p.metrics.tasksProcessed)
@@ -146,12 +165,12 @@ func NewPool(minWorkers, maxWorkers int32) *Pool {
 
 	bufferSize := int(maxWorkers * 4) // バッファサイズを増やす
 	p := &Pool{
-		tasks:        make(chan Task, bufferSize),
-		results:      make(chan Result, bufferSize),
+		tasks:        make(chan queuedTask, bufferSize),
 		numWorkers:   minWorkers,
 		maxWorkers:   maxWorkers,
 		minWorkers:   minWorkers,
 		metrics:      metrics{},
+		draining:     make(chan struct{}),
 		shutdownChan: make(chan struct{}),
 	}
 
@@ -216,15 +235,19 @@ func min(a, b int32) int32 {
 }
 
 // ワーカープールを終了
+// 新しいタスクの投入を止め、キューに残ったタスクを ctx の期限まで実行する
+// 期限までに実行されなかったタスクの Submit は ErrPoolShutdown を返す
 func (p *Pool) Shutdown(ctx context.Context) error {
 	var err error
 	p.shutdownOnce.Do(func() {
-		p.mu.Lock()
 		p.isShutdown.Store(true)
-		close(p.shutdownChan)
+		close(p.draining)
+
+		// 送信中の Submit が終わってからキューを閉じ、ワーカーに残りのタスクを実行させる
+		p.mu.Lock()
+		close(p.tasks)
 		p.mu.Unlock()
 
-		// 残りのタスクの完了を待機
 		done := make(chan struct{})
 		go func() {
 			p.wg.Wait()
@@ -234,50 +257,48 @@ func (p *Pool) Shutdown(ctx context.Context) error {
 		// コンテキストのタイムアウトまたはキャンセルを待機
 		select {
 		case <-ctx.Done():
+			close(p.shutdownChan)
 			err = ctx.Err()
 		case <-done:
-			p.mu.Lock()
-			close(p.tasks)
-			close(p.results)
-			p.mu.Unlock()
 		}
 	})
 	return err
 }
 
-// タスクを投入
+// タスクを投入し、実行の結果を待つ
+// ctx はタスクの実行にも渡し、実行前にキャンセルされたタスクは実行しない
 func (p *Pool) Submit(ctx context.Context, task Task) (interface{}, error) {
 	if task.Execute == nil {
 		return nil, errors.New("task execute function is nil")
 	}
 
+	qt := queuedTask{task: task, ctx: ctx, result: make(chan Result, 1)}
+
+	// シャットダウンでキューを閉じる前に送信を終える
 	p.mu.RLock()
 	if p.isShutdown.Load() {
 		p.mu.RUnlock()
-		return nil, errors.New("worker pool is shutdown")
+		return nil, ErrPoolShutdown
 	}
-	p.mu.RUnlock()
-
-	// タスクの送信
 	select {
 	case <-ctx.Done():
+		p.mu.RUnlock()
 		return nil, ctx.Err()
-	case <-p.shutdownChan:
-		return nil, errors.New("worker pool is shutting down")
-	case p.tasks <- task:
+	case <-p.draining:
+		p.mu.RUnlock()
+		return nil, ErrPoolShutdown
+	case p.tasks <- qt:
 		atomic.AddInt64(&p.metrics.tasksQueued, 1)
 	}
+	p.mu.RUnlock()
 
 	// 結果の待機
 	select {
 	case <-ctx.Done():
 		return nil, ctx.Err()
 	case <-p.shutdownChan:
-		return nil, errors.New("worker pool is shutting down")
-	case result, ok := <-p.results:
-		if !ok {
-			return nil, errors.New("worker pool is shutdown")
-		}
+		return nil, ErrPoolShutdown
+	case result := <-qt.result:
 		if result.Err != nil {
 			return nil, result.Err
 		}
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -229,6 +230,148 @@ func TestWorkerPool(t *testing.T) {
 	})
 }
 
+func TestWorkerPool_Submit(t *testing.T) {
+	t.Run("投入元ごとに結果を返す", func(t *testing.T) {
+		pool := NewPool(4, 8)
+		defer func() {
+			if err := pool.Shutdown(context.Background()); err != nil {
+				t.Errorf("pool.Shutdown() error = %v", err)
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := pool.Submit(context.Background(), Task{
+					Execute: func(ctx context.Context) (interface{}, error) {
+						time.Sleep(time.Millisecond)
+						return i, nil
+					},
+				})
+				if err != nil {
+					t.Errorf("タスク%d実行エラー: %v", i, err)
+					return
+				}
+				if result != i {
+					t.Errorf("別のタスクの結果です: got %v, want %d", result, i)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("キャンセルされたタスクは実行しない", func(t *testing.T) {
+		pool := NewPool(1, 1)
+		defer func() {
+			if err := pool.Shutdown(context.Background()); err != nil {
+				t.Errorf("pool.Shutdown() error = %v", err)
+			}
+		}()
+
+		// ワーカーを占有している間に投入したタスクをキャンセルする
+		release := make(chan struct{})
+		go func() {
+			_, _ = pool.Submit(context.Background(), Task{
+				Execute: func(ctx context.Context) (interface{}, error) {
+					<-release
+					return nil, nil
+				},
+			})
+		}()
+		time.Sleep(20 * time.Millisecond)
+
+		var executed atomic.Bool
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+			close(release)
+		}()
+		_, err := pool.Submit(ctx, Task{
+			Execute: func(ctx context.Context) (interface{}, error) {
+				executed.Store(true)
+				return nil, nil
+			},
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("期待するエラーではありません: got %v, want %v", err, context.Canceled)
+		}
+		time.Sleep(50 * time.Millisecond)
+		if executed.Load() {
+			t.Error("キャンセルされたタスクが実行されました")
+		}
+	})
+}
+
+func TestWorkerPool_Shutdown(t *testing.T) {
+	t.Run("キューに残ったタスクを実行してから終了する", func(t *testing.T) {
+		pool := NewPool(1, 1)
+
+		var completed atomic.Int32
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := pool.Submit(context.Background(), Task{
+					Execute: func(ctx context.Context) (interface{}, error) {
+						time.Sleep(10 * time.Millisecond)
+						completed.Add(1)
+						return nil, nil
+					},
+				})
+				if err != nil {
+					t.Errorf("タスク実行エラー: %v", err)
+				}
+			}()
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := pool.Shutdown(ctx); err != nil {
+			t.Errorf("シャットダウンエラー: %v", err)
+		}
+		wg.Wait()
+		if got := completed.Load(); got != 5 {
+			t.Errorf("完了したタスク数: got %d, want 5", got)
+		}
+	})
+
+	t.Run("期限までに実行されなかったタスクはエラー", func(t *testing.T) {
+		pool := NewPool(1, 1)
+
+		release := make(chan struct{})
+		defer close(release)
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := pool.Submit(context.Background(), Task{
+					Execute: func(ctx context.Context) (interface{}, error) {
+						<-release
+						return nil, nil
+					},
+				})
+				errs <- err
+			}()
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("期待するエラーではありません: got %v, want %v", err, context.DeadlineExceeded)
+		}
+		for i := 0; i < 2; i++ {
+			if err := <-errs; !errors.Is(err, ErrPoolShutdown) {
+				t.Errorf("期待するエラーではありません: got %v, want %v", err, ErrPoolShutdown)
+			}
+		}
+	})
+}
+
 func BenchmarkWorkerPool(b *testing.B) {
 	pool := NewPool(int32(runtime.NumCPU()), int32(runtime.NumCPU()*2))
 	defer func() {
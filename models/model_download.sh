@@ -15,6 +15,7 @@ OPENCV_MODELS=(
     "haarcascade_frontalface_default.xml"
     "haarcascade_eye.xml"
     "haarcascade_smile.xml"
+    "haarcascade_profileface.xml"
 )
 
 BASE_URL="https://raw.githubusercontent.com/opencv/opencv/master/data/haarcascades"
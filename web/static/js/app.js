@@ -14,13 +14,93 @@ document.addEventListener('DOMContentLoaded', () => {
     const startButton = document.getElementById('startButton');
     const captureButton = document.getElementById('captureButton');
     const stopButton = document.getElementById('stopButton');
+    const streamButton = document.getElementById('streamButton');
     const result = document.getElementById('result');
     const primaryEmotion = document.getElementById('primaryEmotion');
     const confidence = document.getElementById('confidence');
     
     let stream = null;
+    let socket = null;
+    let frameTimer = null;
     const ctx = overlay.getContext('2d');
 
+    // 連続分析でフレームを送信する間隔（ミリ秒）
+    const streamInterval = 200;
+
+    // 現在のビデオのフレームをキャンバスに描画
+    const captureFrame = () => {
+        const canvas = document.createElement('canvas');
+        canvas.width = video.videoWidth || 640;
+        canvas.height = video.videoHeight || 480;
+        canvas.getContext('2d').drawImage(video, 0, 0, canvas.width, canvas.height);
+        return canvas;
+    };
+
+    // /api/v1 の分析結果を表示
+    const showResultV1 = (data) => {
+        result.classList.remove('hidden');
+        primaryEmotion.textContent = data.emotion;
+        confidence.textContent = `${(data.confidence * 100).toFixed(1)}%`;
+
+        ctx.clearRect(0, 0, overlay.width, overlay.height);
+        ctx.strokeStyle = '#00ff00';
+        ctx.fillStyle = '#00ff00';
+        ctx.lineWidth = 2;
+        ctx.font = '16px Arial';
+        for (const face of data.faces) {
+            const box = face.normalizedBox;
+            const x = box.x * overlay.width;
+            const y = box.y * overlay.height;
+            ctx.strokeRect(x, y, box.width * overlay.width, box.height * overlay.height);
+            ctx.fillText(face.emotion, x, y - 5);
+        }
+    };
+
+    // 連続分析の停止
+    const stopStreaming = () => {
+        clearInterval(frameTimer);
+        frameTimer = null;
+        if (socket) {
+            socket.close();
+            socket = null;
+        }
+        streamButton.textContent = '連続分析';
+    };
+
+    // WebSocketでフレームを送信し続け、フレームごとの結果を表示する
+    const startStreaming = () => {
+        const protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
+        socket = new WebSocket(`${protocol}//${location.host}/api/v1/stream`);
+        socket.binaryType = 'arraybuffer';
+
+        socket.addEventListener('open', () => {
+            frameTimer = setInterval(() => {
+                // 送信待ちのデータがある間は新しいフレームを送らない
+                if (!socket || socket.readyState !== WebSocket.OPEN || socket.bufferedAmount > 0) return;
+                captureFrame().toBlob((blob) => {
+                    if (blob && socket && socket.readyState === WebSocket.OPEN) {
+                        socket.send(blob);
+                    }
+                }, 'image/jpeg', 0.8);
+            }, streamInterval);
+        });
+
+        socket.addEventListener('message', (event) => {
+            const message = JSON.parse(event.data);
+            if (message.error) {
+                console.error('フレームの分析エラー:', message.error);
+                return;
+            }
+            showResultV1(message.result);
+        });
+
+        socket.addEventListener('close', () => {
+            if (socket) stopStreaming();
+        });
+
+        streamButton.textContent = '連続分析を停止';
+    };
+
     // ビデオのメタデータ読み込み完了時の処理
     video.addEventListener('loadedmetadata', () => {
         overlay.width = video.videoWidth;
@@ -41,6 +121,7 @@ document.addEventListener('DOMContentLoaded', () => {
             startButton.disabled = true;
             captureButton.disabled = false;
             stopButton.disabled = false;
+            streamButton.disabled = false;
             
             result.classList.add('hidden');
             ctx.clearRect(0, 0, overlay.width, overlay.height);
@@ -55,15 +136,9 @@ document.addEventListener('DOMContentLoaded', () => {
         if (!stream) return;
 
         try {
-            // キャンバスの準備
-            const canvas = document.createElement('canvas');
-            canvas.width = video.videoWidth || 640;
-            canvas.height = video.videoHeight || 480;
-            const context = canvas.getContext('2d');
-            
             // 現在のフレームを描画
-            context.drawImage(video, 0, 0, canvas.width, canvas.height);
-            
+            const canvas = captureFrame();
+
             // Base64形式で画像を取得
             const imageData = canvas.toDataURL('image/jpeg', 0.9);
             
@@ -98,13 +173,14 @@ document.addEventListener('DOMContentLoaded', () => {
             });
 
             if (!response.ok) {
-                const errorText = await response.text();
+                const problem = await response.json().catch(() => ({}));
                 console.error('サーバーエラー:', {
                     status: response.status,
-                    statusText: response.statusText,
-                    error: errorText
+                    code: problem.code,
+                    requestId: problem.requestId,
+                    detail: problem.detail
                 });
-                throw new Error(`分析に失敗しました: ${errorText}`);
+                throw new Error(`分析に失敗しました: ${problem.title || response.statusText}`);
             }
 
             const data = await response.json();
@@ -139,8 +215,19 @@ document.addEventListener('DOMContentLoaded', () => {
         }
     });
 
+    // 連続分析の開始と停止
+    streamButton.addEventListener('click', () => {
+        if (!stream) return;
+        if (socket) {
+            stopStreaming();
+        } else {
+            startStreaming();
+        }
+    });
+
     // カメラの停止
     stopButton.addEventListener('click', () => {
+        stopStreaming();
         if (stream) {
             stream.getTracks().forEach(track => track.stop());
             video.srcObject = null;
@@ -149,6 +236,7 @@ document.addEventListener('DOMContentLoaded', () => {
             startButton.disabled = false;
             captureButton.disabled = true;
             stopButton.disabled = true;
+            streamButton.disabled = true;
             
             // オーバーレイをクリア
             ctx.clearRect(0, 0, overlay.width, overlay.height);